@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var inferVersions bool
+
+// adoptCmd represents the adopt command
+var adoptCmd = &cobra.Command{
+	Use:   "adopt",
+	Short: "Bring an existing namespace under blackbeard management.",
+	Long: `This command labels an existing namespace manager=blackbeard and generates its inventory from the default inventory.
+
+Nothing is applied to the namespace : use the apply command once the inventory matches the running workloads.
+Using --infer-versions, the versions of the inventory are read from the image tags of the namespace deployments.`,
+
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runAdopt(namespace); err != nil {
+			exit(err)
+		}
+	},
+}
+
+func NewAdoptCommand() *cobra.Command {
+	addCommonNamespaceCommandFlags(adoptCmd)
+	addOutputFlag(adoptCmd)
+	adoptCmd.Flags().BoolVar(&inferVersions, "infer-versions", false, "read the versions of the inventory from the image tags of the namespace deployments")
+	return adoptCmd
+}
+
+func runAdopt(namespace string) error {
+
+	if namespace == "" {
+		return errNamespaceRequired()
+	}
+
+	api, _ := newCommandAPI()
+
+	inv, err := api.Adopt(namespace, inferVersions)
+	if err != nil {
+		return err
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"namespace": namespace,
+	}).Info("namespace has been adopted successfully")
+
+	if !isMachineReadable(output) {
+		return nil
+	}
+
+	return printResult(os.Stdout, output, result{Namespace: namespace, Action: "adopted", Inventory: inv})
+}
@@ -1,12 +1,17 @@
 package cmd
 
 import (
-	"errors"
+	"fmt"
+	"io"
+	"os"
 	"time"
 
 	"github.com/gosuri/uiprogress"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
+
+	"github.com/Meetic/blackbeard/pkg/api"
+	"github.com/Meetic/blackbeard/pkg/errors"
 )
 
 const (
@@ -19,11 +24,25 @@ var applyCmd = &cobra.Command{
 	Short: "Apply a given inventory to the associated namespace",
 	Long: `This command will update the configuration files for the given namespace using the inventory file
 and apply the changes to the Kubernetes namespace.
+
+Use --selector or --all to apply the inventories of many namespaces at once, for instance after a base image update :
+
+  blackbeard apply --selector team=search --concurrency 8 --continue-on-error
 	`,
 	Run: func(cmd *cobra.Command, args []string) {
+		if isBulk(bulkOpts) {
+			if wait {
+				exit(errors.New(errors.Invalid, "the --wait flag cannot be used along with --selector or --all"))
+			}
+			if err := runBulkCommand(api.BulkApply, bulkOpts); err != nil {
+				exit(err)
+			}
+			return
+		}
+
 		err := runApply(namespace)
 		if err != nil {
-			logrus.Fatal(err)
+			exit(err)
 		}
 
 	},
@@ -31,22 +50,76 @@ and apply the changes to the Kubernetes namespace.
 
 func NewApplyCommand() *cobra.Command {
 	addCommonNamespaceCommandFlags(applyCmd)
+	addOutputFlag(applyCmd)
+	addBulkFlags(applyCmd)
 	applyCmd.Flags().BoolVar(&wait, "wait", false, "wait until all pods are running")
 	applyCmd.Flags().DurationVarP(&timeout, "timeout", "t", defaultTimeout, "The max time to wait for pods to be all running.")
 
 	return applyCmd
 }
 
+// waitForNamespaceReady waits for the namespace pods to be ready, displaying a progress bar
+func waitForNamespaceReady(api api.Api, namespace string) error {
+	logrus.WithFields(logrus.Fields{
+		"namespace": namespace,
+	}).Info("Waiting for namespace to be ready...")
+	//init progress bar
+	progress := uiprogress.New()
+	if isMachineReadable(output) {
+		progress.SetOut(os.Stderr)
+	}
+	progress.Start()
+	bar := progress.AddBar(100).AppendCompleted().PrependElapsed()
+
+	if err := api.WaitForNamespaceReady(namespace, timeout, bar); err != nil {
+		progress.Stop()
+		printBlocking(os.Stderr, err)
+		return err
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"namespace": namespace,
+	}).Info("Namespace is ready")
+
+	return nil
+}
+
+// printBlocking prints the workloads and pods which prevented a namespace from being ready
+func printBlocking(out io.Writer, err error) {
+	blocking, ok := errors.DetailsOf(err)["blocking"]
+	if !ok {
+		return
+	}
+
+	var lines []string
+	switch b := blocking.(type) {
+	case []string:
+		lines = b
+	case []interface{}:
+		for _, l := range b {
+			lines = append(lines, fmt.Sprint(l))
+		}
+	}
+
+	if len(lines) == 0 {
+		return
+	}
+
+	fmt.Fprintln(out, "Blocking:")
+	for _, l := range lines {
+		fmt.Fprintln(out, "  "+l)
+	}
+}
+
 func runApply(namespace string) error {
 
 	if namespace == "" {
-		return errors.New("you must specified a namespace using the --namespace flag")
+		return errNamespaceRequired()
 	}
 
-	files := newFileClient(playbookDir)
-	api := newAPI(files, newKubernetesClient())
+	api, configPath := newCommandAPI()
 
-	err := api.Apply(namespace, files.ConfigPath())
+	err := api.Apply(namespace, configPath)
 	if err != nil {
 		return err
 	}
@@ -56,22 +129,19 @@ func runApply(namespace string) error {
 	}).Info("Playbook has been deployed")
 
 	if wait {
-		logrus.WithFields(logrus.Fields{
-			"namespace": namespace,
-		}).Info("Waiting for namespace to be ready...")
-		//init progress bar
-		uiprogress.Start()
-		bar := uiprogress.AddBar(100).AppendCompleted().PrependElapsed()
-
-		if err := api.WaitForNamespaceReady(namespace, timeout, bar); err != nil {
+		if err := waitForNamespaceReady(api, namespace); err != nil {
 			return err
 		}
+	}
 
-		logrus.WithFields(logrus.Fields{
-			"namespace": namespace,
-		}).Info("Namespace is ready")
+	if !isMachineReadable(output) {
+		return nil
+	}
 
+	inv, err := api.Inventories().Get(namespace)
+	if err != nil {
+		return err
 	}
 
-	return nil
+	return printResult(os.Stdout, output, result{Namespace: namespace, Action: "applied", Inventory: inv})
 }
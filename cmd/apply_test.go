@@ -0,0 +1,25 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Meetic/blackbeard/pkg/errors"
+)
+
+func TestPrintBlocking(t *testing.T) {
+	err := errors.WithDetail(errors.New(errors.Upstream, "namespace test can not become ready"), "blocking", []string{
+		"deployments/api: NotReady",
+		"pod api-1 (php): ImagePullBackOff",
+	})
+
+	out := bytes.Buffer{}
+	printBlocking(&out, err)
+	assert.Equal(t, "Blocking:\n  deployments/api: NotReady\n  pod api-1 (php): ImagePullBackOff\n", out.String())
+
+	out.Reset()
+	printBlocking(&out, errors.New(errors.Timeout, "time out"))
+	assert.Empty(t, out.String())
+}
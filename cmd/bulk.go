@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"context"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/Meetic/blackbeard/pkg/api"
+	"github.com/Meetic/blackbeard/pkg/errors"
+)
+
+// bulkPollInterval is the interval between two reads of the progress of a bulk operation
+const bulkPollInterval = time.Second
+
+var bulkOpts api.BulkOptions
+
+// addBulkFlags adds the flags running a command on many namespaces
+func addBulkFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&bulkOpts.Selector, "selector", "l", "", "Run the command on the namespaces matching this label selector (ex: team=search)")
+	cmd.Flags().BoolVar(&bulkOpts.All, "all", false, "Run the command on every namespace having an inventory")
+	cmd.Flags().IntVar(&bulkOpts.Concurrency, "concurrency", api.DefaultBulkConcurrency, "Number of namespaces processed at the same time when using --selector or --all")
+	cmd.Flags().BoolVar(&bulkOpts.ContinueOnError, "continue-on-error", false, "Keep processing the namespaces when one of them failed, when using --selector or --all")
+}
+
+// isBulk returns true if the command must run on many namespaces
+func isBulk(opts api.BulkOptions) bool {
+	return opts.Selector != "" || opts.All
+}
+
+// runBulk runs an operation on the namespaces selected by opts, waits for it and prints its report.
+// An error is returned if the operation failed on some namespaces.
+func runBulk(a api.Api, configPath, operation string, opts api.BulkOptions) (*api.BulkReport, error) {
+	if namespace != "" {
+		return nil, errors.New(errors.Invalid, "the --namespace flag cannot be used along with --selector or --all")
+	}
+
+	report, err := a.StartBulk(operation, opts, configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"id":         report.ID,
+		"operation":  operation,
+		"namespaces": len(report.Results),
+	}).Info("bulk operation started")
+
+	report, err = api.WaitForBulk(context.Background(), a, report.ID, bulkPollInterval)
+	if err != nil {
+		return nil, err
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"id":        report.ID,
+		"succeeded": report.Count(api.BulkSucceeded),
+		"failed":    report.Count(api.BulkFailed),
+		"skipped":   report.Count(api.BulkSkipped),
+	}).Info("bulk operation finished")
+
+	return report, bulkError(report)
+}
+
+// bulkError returns an error if the operation failed on some namespaces
+func bulkError(report *api.BulkReport) error {
+	if report.Status != api.BulkFailed {
+		return nil
+	}
+
+	var failed []string
+	for _, r := range report.Results {
+		if r.Status == api.BulkFailed {
+			failed = append(failed, r.Namespace)
+		}
+	}
+
+	return errors.New(errors.Internal, "%s failed on %d of %d namespaces : %s",
+		report.Operation, len(failed), len(report.Results), strings.Join(failed, ", "))
+}
+
+// printBulkReport prints the status of the operation on each namespace
+func printBulkReport(out io.Writer, format string, report *api.BulkReport) error {
+	tbl := newTable([]string{"Namespace", "Status", "Error"})
+
+	for _, r := range report.Results {
+		tbl.addRow([]string{r.Namespace, r.Status, r.Error})
+	}
+
+	return printObject(out, format, report, tbl)
+}
+
+// runBulkCommand runs an operation on many namespaces and prints its report
+func runBulkCommand(operation string, opts api.BulkOptions) error {
+	a, configPath := newCommandAPI()
+
+	return runBulkWith(a, configPath, operation, opts)
+}
+
+func runBulkWith(a api.Api, configPath, operation string, opts api.BulkOptions) error {
+	report, err := runBulk(a, configPath, operation, opts)
+	if report == nil {
+		return err
+	}
+
+	if perr := printBulkReport(os.Stdout, output, report); perr != nil {
+		return perr
+	}
+
+	return err
+}
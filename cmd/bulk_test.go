@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Meetic/blackbeard/pkg/api"
+	"github.com/Meetic/blackbeard/pkg/errors"
+)
+
+func TestPrintBulkReport(t *testing.T) {
+	report := &api.BulkReport{
+		ID:        "42",
+		Operation: api.BulkApply,
+		Status:    api.BulkFailed,
+		Results: []api.BulkResult{
+			{Namespace: "a", Status: api.BulkSucceeded},
+			{Namespace: "b", Status: api.BulkFailed, Error: "unknown profile"},
+			{Namespace: "c", Status: api.BulkSkipped},
+		},
+	}
+
+	out := bytes.Buffer{}
+	assert.Nil(t, printBulkReport(&out, outputTable, report))
+	lines := strings.Split(out.String(), "\n")
+	assert.Equal(t, []string{"Namespace", "Status", "Error"}, strings.Fields(lines[0]))
+	assert.Equal(t, []string{"a", "succeeded"}, strings.Fields(lines[1]))
+	assert.Equal(t, "b failed unknown profile", strings.Join(strings.Fields(lines[2]), " "))
+	assert.Equal(t, []string{"c", "skipped"}, strings.Fields(lines[3]))
+
+	err := bulkError(report)
+	assert.True(t, errors.Is(err, errors.Internal))
+	assert.Contains(t, err.Error(), "apply failed on 1 of 3 namespaces : b")
+
+	report.Status = api.BulkSucceeded
+	assert.Nil(t, bulkError(report))
+}
+
+func TestIsBulk(t *testing.T) {
+	assert.False(t, isBulk(api.BulkOptions{Concurrency: 4}))
+	assert.True(t, isBulk(api.BulkOptions{All: true}))
+	assert.True(t, isBulk(api.BulkOptions{Selector: "team=search"}))
+}
@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"context"
+	"io"
+	"os"
+	"os/signal"
+	"strconv"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Meetic/blackbeard/pkg/errors"
+	"github.com/Meetic/blackbeard/pkg/resource"
+)
+
+var checkCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Check that the services exposed by a namespace are reachable.",
+	Long: `This command requests the urls of ingresses and HTTPRoutes and dials the NodePort and LoadBalancer services of a namespace.
+
+Smoke checks may be declared on a Service, an Ingress or an HTTPRoute using the blackbeard.io/checks annotation.
+They are run in addition to the default probe of the service :
+
+  blackbeard.io/checks: '[{"path": "/health", "status": 200}]'
+
+The command exits with an error if a check failed, so a CI pipeline can wait for a namespace to be actually usable.
+When using a blackbeard server, checks are run by the server.
+`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runCheck(namespace); err != nil {
+			exit(err)
+		}
+	},
+}
+
+func NewCheckCommand() *cobra.Command {
+	addCommonNamespaceCommandFlags(checkCmd)
+	addOutputFlag(checkCmd)
+
+	return checkCmd
+}
+
+func runCheck(namespace string) error {
+
+	if namespace == "" {
+		return errNamespaceRequired()
+	}
+
+	api, _ := newCommandAPI()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	report, err := api.Check(ctx, namespace)
+	if err != nil {
+		return err
+	}
+
+	if err := printCheckReport(os.Stdout, output, report); err != nil {
+		return err
+	}
+
+	return checkFailure(report)
+}
+
+func printCheckReport(out io.Writer, format string, report *resource.CheckReport) error {
+	tbl := newTable([]string{"Service", "Probe", "Target", "Result", "Status", "Latency", "Error"}, "Source", "Expected")
+
+	for _, r := range report.Results {
+		result := "FAIL"
+		if r.Passed {
+			result = "PASS"
+		}
+
+		var status, expected string
+		if r.Status != 0 {
+			status = strconv.Itoa(r.Status)
+		}
+		if r.Expected != 0 {
+			expected = strconv.Itoa(r.Expected)
+		}
+
+		tbl.addRow(
+			[]string{r.Service, r.Probe, r.Target, result, status, strconv.FormatInt(r.LatencyMs, 10) + "ms", r.Error},
+			r.Source, expected,
+		)
+	}
+
+	return printObject(out, format, report, tbl)
+}
+
+// checkFailure returns an error if some checks of the report failed
+func checkFailure(report *resource.CheckReport) error {
+	if report.Passed {
+		return nil
+	}
+
+	var failed int
+	for _, r := range report.Results {
+		if !r.Passed {
+			failed++
+		}
+	}
+
+	return errors.New(errors.Upstream, "%d of %d checks failed in namespace %s", failed, len(report.Results), report.Namespace)
+}
@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Meetic/blackbeard/pkg/errors"
+	"github.com/Meetic/blackbeard/pkg/resource"
+)
+
+func TestPrintCheckReport(t *testing.T) {
+	report := &resource.CheckReport{
+		Namespace: "test",
+		Results: []resource.CheckResult{
+			{Service: "api", Probe: resource.ProbeHTTP, Target: "http://api.test/health", Expected: 200, Status: 200, Passed: true, LatencyMs: 12},
+			{Service: "db", Probe: resource.ProbeTCP, Target: "kube.test:30306", Error: "connection refused"},
+		},
+	}
+
+	out := bytes.Buffer{}
+	assert.Nil(t, printCheckReport(&out, outputTable, report))
+	lines := strings.Split(out.String(), "\n")
+	assert.Equal(t, []string{"api", "http", "http://api.test/health", "PASS", "200", "12ms"}, strings.Fields(lines[1]))
+	assert.Equal(t, []string{"db", "tcp", "kube.test:30306", "FAIL", "0ms", "connection", "refused"}, strings.Fields(lines[2]))
+
+	err := checkFailure(report)
+	assert.True(t, errors.Is(err, errors.Upstream))
+	assert.EqualError(t, err, "1 of 2 checks failed in namespace test")
+
+	report.Passed = true
+	assert.Nil(t, checkFailure(report))
+}
@@ -2,67 +2,124 @@ package cmd
 
 import (
 	"bytes"
-	"errors"
 	"fmt"
 	"html/template"
-	"path/filepath"
+	"os"
 
-	"github.com/Meetic/blackbeard/pkg/playbook"
-	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
+
+	"github.com/Meetic/blackbeard/pkg/api"
+	"github.com/Meetic/blackbeard/pkg/playbook"
+	"github.com/Meetic/blackbeard/pkg/resource"
+)
+
+var (
+	profile     string
+	labels      map[string]string
+	annotations map[string]string
+	owner       string
+	createRef   string
 )
 
 // createCmd represents the create command
 var createCmd = &cobra.Command{
 	Use:   "create",
 	Short: "Create a namespace and generated a dedicated inventory.",
-	Long: `This command will generate an inventory file called {{namespace}}_inventory.json
+	Long: `This command will generate an inventory file called {{namespace}}_inventory.json, or {{namespace}}_inventory.yaml
+when the default inventory of the playbook is a defaults.yaml file.
 
 This file contains all the parameters needed to build a complete Kubernetes configuration.
 Feel free to edit this file before applying changes.
+
+If the playbook contains a namespace.yaml file, the namespace is limited by the resource quota,
+limit range and network policies of a size profile. Use --profile to choose it, otherwise the
+default profile of the playbook is used.
+
+Labels and annotations may be set on the namespace, for instance for cost attribution or for sidecar injection.
+They are added to the labels and annotations defined in the namespace.yaml file of the playbook :
+
+  blackbeard create -n my-feature --label team=dating --annotation ticket=FEAT-123 --owner john
+
+When the blackbeard server reads the playbook from git, the namespace may be pinned to a branch, a tag
+or a commit of the playbook with --playbook-ref. Its configs are then always generated from this ref :
+
+  blackbeard create -n my-feature --playbook-ref v2.3.0
 `,
 	Run: func(cmd *cobra.Command, args []string) {
-		err := runCreate(namespace)
+		err := runCreate(namespace, createOptions())
 		if err != nil {
-			logrus.Fatal(err.Error())
+			exit(err)
 		}
 	},
 }
 
 func NewCreateCommand() *cobra.Command {
 	addCommonNamespaceCommandFlags(createCmd)
+	addOutputFlag(createCmd)
+	createCmd.Flags().StringVar(&profile, "profile", "", "Size profile of the namespace, defined in the namespace.yaml file of the playbook")
+	createCmd.Flags().StringToStringVarP(&labels, "label", "l", nil, "Label set on the namespace, as key=value. May be repeated")
+	createCmd.Flags().StringToStringVar(&annotations, "annotation", nil, "Annotation set on the namespace, as key=value. May be repeated")
+	createCmd.Flags().StringVar(&owner, "owner", "", "Owner of the namespace, set as the "+resource.AnnotationOwner+" annotation")
+	createCmd.Flags().StringVar(&createRef, "playbook-ref", "", "Git reference of the playbook used by the namespace. Only available when the playbook is read from git")
 	return createCmd
 }
 
-func runCreate(namespace string) error {
+// createOptions returns the options of the create command flags
+func createOptions() api.CreateOptions {
+	opts := api.CreateOptions{Profile: profile, PlaybookRef: createRef, Labels: labels, Annotations: annotations}
 
-	if namespace == "" {
-		return errors.New("you must specified a namespace using the --namespace flag")
+	if owner != "" {
+		if opts.Annotations == nil {
+			opts.Annotations = make(map[string]string)
+		}
+		opts.Annotations[resource.AnnotationOwner] = owner
 	}
 
-	files := newFileClient(playbookDir)
+	return opts
+}
+
+func runCreate(namespace string, opts api.CreateOptions) error {
+
+	if namespace == "" {
+		return errNamespaceRequired()
+	}
 
-	api := newAPI(files, newKubernetesClient())
+	api, _ := newCommandAPI()
 
-	inv, err := api.Create(namespace)
+	inv, err := api.Create(namespace, opts)
 	if err != nil {
 		return err
 	}
 
-	tpl := template.Must(template.New("config").Parse(`Namespace for user {{.Inv.Namespace}} has been created !
+	if isMachineReadable(output) {
+		return printResult(os.Stdout, output, result{Namespace: inv.Namespace, Action: "created", Inventory: inv})
+	}
 
+	tpl := template.Must(template.New("config").Parse(`Namespace for user {{.Inv.Namespace}} has been created !
+{{if .Server}}
+	A inventory has been generated on the blackbeard server : {{.Server}}
+	Feel free to update it using the REST api to match your desired testing env configuration.
+{{else}}
 	A inventory file has been generated : {{.File}}
 	Feel free to edit this file to match your desired testing env configuration.
-	`))
+{{end}}`))
 
-	message := bytes.Buffer{}
-	if err := tpl.Execute(&message, struct {
-		File string
-		Inv  playbook.Inventory
+	data := struct {
+		File   string
+		Server string
+		Inv    playbook.Inventory
 	}{
-		File: filepath.Join(files.InventoryPath(), inv.Namespace+"_inventory.json"),
-		Inv:  inv,
-	}); err != nil {
+		Inv: inv,
+	}
+
+	if isRemote() {
+		data.Server = server
+	} else {
+		data.File = newFileClient(playbookDir).InventoryFile(inv.Namespace)
+	}
+
+	message := bytes.Buffer{}
+	if err := tpl.Execute(&message, data); err != nil {
 		return err
 	}
 
@@ -3,25 +3,58 @@ package cmd
 import (
 	"bytes"
 	"fmt"
+	"io"
+	"os"
+	"strings"
 	"text/template"
 
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
+
+	"github.com/Meetic/blackbeard/pkg/api"
+	"github.com/Meetic/blackbeard/pkg/errors"
+	"github.com/Meetic/blackbeard/pkg/resource"
 )
 
 // deleteCmd represents the delete command
 var deleteCmd = &cobra.Command{
-	Use:   "delete [command]",
+	Use:   "delete [KIND] [NAME]",
 	Short: "Delete an object",
 	Long: `Delete resources by namespace or names.
 
-Deletetion of a namespace will delete the namespace and remove all his attached object including the intentory attached to it. While removing an object will only supress it form the namespace but keep everything else.`,
+Deletetion of a namespace will delete the namespace and remove all his attached object including the intentory attached to it. While removing an object will only supress it form the namespace but keep everything else.
+
+Objects may only be deleted from namespaces created by blackbeard (labelled manager=blackbeard).
+
+Use --selector or --all to delete many namespaces at once, along with their inventories :
+
+  blackbeard delete --selector team=search`,
+	Args: cobra.MaximumNArgs(2),
 	Run: func(cmd *cobra.Command, args []string) {
-		runDelete()
+		switch len(args) {
+		case 0:
+			if !isBulk(bulkOpts) {
+				runDelete()
+				return
+			}
+			if err := runBulkDelete(bulkOpts, os.Stdin); err != nil {
+				exit(err)
+			}
+		case 2:
+			if err := runDeleteResource(args[0], args[1]); err != nil {
+				exit(err)
+			}
+		default:
+			exit(errors.New(errors.Invalid, "a kind and a name are required : delete KIND NAME"))
+		}
 	},
 }
 
 func NewDeleteCommand() *cobra.Command {
+	addCommonNamespaceCommandFlags(deleteCmd)
+	addOutputFlag(deleteCmd)
+	addBulkFlags(deleteCmd)
+
 	deleteCmd.AddCommand(NewDeleteJobCommand())
 	deleteCmd.AddCommand(NewDeleteNamespaceCommand())
 
@@ -36,7 +69,7 @@ Using the get command without any sub-command makes no sens. Please use one of t
 {{end -}}
 `))
 
-	data := []string{"delete namespace", "delete job"}
+	data := []string{"delete namespace", "delete job", "delete KIND NAME, KIND being one of " + strings.Join(resource.Kinds(), ", ")}
 
 	contents := bytes.Buffer{}
 	if err := tpl.Execute(&contents, data); err != nil {
@@ -45,3 +78,42 @@ Using the get command without any sub-command makes no sens. Please use one of t
 
 	fmt.Println(contents.String())
 }
+
+// runBulkDelete deletes the namespaces selected by opts, once the user confirmed it
+func runBulkDelete(opts api.BulkOptions, confirmation io.Reader) error {
+	a, configPath := newCommandAPI()
+
+	invs, err := a.ListInventories(opts.Selector)
+	if err != nil {
+		return err
+	}
+
+	if len(invs) == 0 {
+		logrus.Info("no namespace matches the selector")
+		return nil
+	}
+
+	if !askForConfirmation(fmt.Sprintf("You are about to delete %d namespaces, their inventories and all their associated files. Are you sure?", len(invs)), confirmation) {
+		return nil
+	}
+
+	return runBulkWith(a, configPath, api.BulkDelete, opts)
+}
+
+func runDeleteResource(kind, name string) error {
+	if namespace == "" {
+		return errNamespaceRequired()
+	}
+
+	api, _ := newCommandAPI()
+	if err := api.DeleteResource(namespace, kind, name); err != nil {
+		return err
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"namespace": namespace,
+		kind:        name,
+	}).Info("resource deleted")
+
+	return nil
+}
@@ -1,11 +1,12 @@
 package cmd
 
 import (
-	"errors"
 	"fmt"
 
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
+
+	"github.com/Meetic/blackbeard/pkg/resource"
 )
 
 var deleteJobCmd = &cobra.Command{
@@ -20,7 +21,7 @@ Kubernetes will also remove the pod whatever the status.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		err := runDeleteJob(args[0])
 		if err != nil {
-			logrus.Fatal(err.Error())
+			exit(err)
 		}
 	},
 }
@@ -30,21 +31,21 @@ func NewDeleteJobCommand() *cobra.Command {
 	return deleteJobCmd
 }
 
-func runDeleteJob(resource string) error {
+func runDeleteJob(name string) error {
 	if namespace == "" {
 		// should set the namespace to default namespace value set in the kube/config
-		return errors.New("you must specified a namespace using the --namespace flag")
+		return errNamespaceRequired()
 	}
 
-	api := newAPI(newFileClient(playbookDir), newKubernetesClient())
-	err := api.DeleteResource(namespace, resource)
+	api, _ := newCommandAPI()
+	err := api.DeleteResource(namespace, resource.KindJobs, name)
 	if err != nil {
-		return errors.New(fmt.Sprintf("an error occurend when removing the job : %v", err))
+		return fmt.Errorf("an error occurend when removing the job : %w", err)
 	}
 
 	logrus.WithFields(logrus.Fields{
 		"namespace": namespace,
-		"job":       resource,
+		"job":       name,
 	}).Info("job deleted")
 
 	return nil
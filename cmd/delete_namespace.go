@@ -17,7 +17,7 @@ var deleteNamespaceCmd = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		err := runDeleteNamespace(args[0])
 		if err != nil {
-			logrus.Fatal(err.Error())
+			exit(err)
 		}
 	},
 }
@@ -31,7 +31,7 @@ func runDeleteNamespace(namespace string) error {
 		return nil
 	}
 
-	api := newAPI(newFileClient(playbookDir), newKubernetesClient())
+	api, _ := newCommandAPI()
 
 	err := api.Delete(namespace, false)
 	if err != nil {
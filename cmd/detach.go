@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// detachCmd represents the detach command
+var detachCmd = &cobra.Command{
+	Use:   "detach",
+	Short: "Stop managing a namespace without deleting it.",
+	Long: `This command removes the manager=blackbeard label of a namespace and deletes its inventory and config files.
+
+The namespace and its workloads are left running.`,
+
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runDetach(namespace); err != nil {
+			exit(err)
+		}
+	},
+}
+
+func NewDetachCommand() *cobra.Command {
+	addCommonNamespaceCommandFlags(detachCmd)
+	return detachCmd
+}
+
+func runDetach(namespace string) error {
+
+	if namespace == "" {
+		return errNamespaceRequired()
+	}
+
+	api, _ := newCommandAPI()
+
+	if err := api.Detach(namespace); err != nil {
+		return err
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"namespace": namespace,
+	}).Info("namespace has been detached successfully")
+
+	return nil
+}
@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"io"
+	"os"
+	"strconv"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/Meetic/blackbeard/pkg/api"
+)
+
+var fix bool
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Report the drift between the inventories and the namespaces of the cluster.",
+	Long: `This command compares the inventories and configs of the playbook with the namespaces of the cluster and reports :
+
+  - OrphanInventory : an inventory whose namespace does not exist;
+  - OrphanConfigs : a configs dir without inventory;
+  - UnlabelledNamespace : a namespace having an inventory but no manager=blackbeard label;
+  - MissingInventory : a namespace labelled manager=blackbeard without inventory.
+
+Nothing is changed unless --fix is used : orphan inventories and configs are then deleted, namespaces having an
+inventory are labelled and labelled namespaces get an inventory generated from the default inventory.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runDoctor(); err != nil {
+			exit(err)
+		}
+	},
+}
+
+func NewDoctorCommand() *cobra.Command {
+	addOutputFlag(doctorCmd)
+	doctorCmd.Flags().BoolVar(&fix, "fix", false, "fix the reported problems")
+
+	return doctorCmd
+}
+
+func runDoctor() error {
+	api, _ := newCommandAPI()
+
+	report, err := api.Reconcile(fix)
+	if err != nil {
+		return err
+	}
+
+	if len(report.Problems) == 0 {
+		logrus.Info("inventories and namespaces are in sync")
+	}
+
+	return printReconcileReport(os.Stdout, output, report)
+}
+
+func printReconcileReport(out io.Writer, format string, report *api.ReconcileReport) error {
+	columns := []string{"Kind", "Namespace", "Problem"}
+	if !report.DryRun {
+		columns = append(columns, "Fixed", "Error")
+	}
+
+	tbl := newTable(columns)
+	for _, p := range report.Problems {
+		row := []string{p.Kind, p.Namespace, p.Message}
+		if !report.DryRun {
+			row = append(row, strconv.FormatBool(p.Fixed), p.Error)
+		}
+
+		tbl.addRow(row)
+	}
+
+	return printObject(out, format, report, tbl)
+}
@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Meetic/blackbeard/pkg/api"
+)
+
+func TestPrintReconcileReport(t *testing.T) {
+	report := &api.ReconcileReport{
+		DryRun: true,
+		Problems: []api.Problem{
+			{Kind: api.OrphanInventory, Namespace: "old", Message: "the inventory has no namespace"},
+		},
+	}
+
+	out := bytes.Buffer{}
+	assert.Nil(t, printReconcileReport(&out, outputTable, report))
+	lines := strings.Split(out.String(), "\n")
+	assert.Equal(t, []string{"Kind", "Namespace", "Problem"}, strings.Fields(lines[0]))
+	assert.Equal(t, "OrphanInventory old the inventory has no namespace", strings.Join(strings.Fields(lines[1]), " "))
+
+	report.DryRun = false
+	report.Problems[0].Error = "permission denied"
+
+	out.Reset()
+	assert.Nil(t, printReconcileReport(&out, outputTable, report))
+	lines = strings.Split(out.String(), "\n")
+	assert.Equal(t, []string{"Kind", "Namespace", "Problem", "Fixed", "Error"}, strings.Fields(lines[0]))
+	assert.Equal(t, "OrphanInventory old the inventory has no namespace false permission denied", strings.Join(strings.Fields(lines[1]), " "))
+}
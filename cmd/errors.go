@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"github.com/sirupsen/logrus"
+
+	"github.com/Meetic/blackbeard/pkg/errors"
+)
+
+// exitCodes maps error kinds to the exit code of the blackbeard cli.
+var exitCodes = map[errors.Kind]int{
+	errors.Internal:      1,
+	errors.Invalid:       2,
+	errors.NotFound:      3,
+	errors.AlreadyExists: 4,
+	errors.Conflict:      5,
+	errors.Forbidden:     6,
+	errors.Timeout:       7,
+	errors.Upstream:      8,
+}
+
+// exitCode returns the exit code associated to the kind of err.
+func exitCode(err error) int {
+	if code, ok := exitCodes[errors.KindOf(err)]; ok {
+		return code
+	}
+
+	return 1
+}
+
+// exit logs err and terminates the cli with the exit code associated to its kind.
+func exit(err error) {
+	logrus.WithField("code", errors.KindOf(err)).Error(err.Error())
+	logrus.Exit(exitCode(err))
+}
+
+// errNamespaceRequired is returned by commands called without the --namespace flag.
+func errNamespaceRequired() error {
+	return errors.New(errors.Invalid, "you must specified a namespace using the --namespace flag")
+}
@@ -0,0 +1,17 @@
+package cmd
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Meetic/blackbeard/pkg/errors"
+)
+
+func TestExitCode(t *testing.T) {
+	assert.Equal(t, 1, exitCode(fmt.Errorf("boom")))
+	assert.Equal(t, 2, exitCode(errNamespaceRequired()))
+	assert.Equal(t, 3, exitCode(fmt.Errorf("get: %w", errors.New(errors.NotFound, "not found"))))
+	assert.Equal(t, 8, exitCode(errors.New(errors.Upstream, "kubectl failed")))
+}
@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"os/signal"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+
+	"github.com/Meetic/blackbeard/pkg/errors"
+	"github.com/Meetic/blackbeard/pkg/resource"
+)
+
+var (
+	execStdin bool
+	execTTY   bool
+)
+
+var execCmd = &cobra.Command{
+	Use:   "exec TARGET -- COMMAND [ARGS...]",
+	Short: "Execute a command in a pod of a namespace.",
+	Long: `This command executes a command in a ready pod of a workload, or in a given pod.
+
+The target is a deployment, a statefulset or a job, optionally prefixed by its kind (ex: deploy/api), or a pod given as pod/NAME.
+Only namespaces created by blackbeard, labelled manager=blackbeard, accept commands.
+
+The command exits with the exit code of the remote command.
+
+Ex: blackbeard exec -n my-feature -it deploy/api -- sh
+`,
+	Args: cobra.MinimumNArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		if cmd.ArgsLenAtDash() != 1 {
+			exit(errors.New(errors.Invalid, "the command must be given after --, ex: blackbeard exec deploy/api -- ls"))
+		}
+
+		err := runExec(namespace, args[0], args[1:])
+		if code, ok := resource.ExitCode(err); ok {
+			os.Exit(code)
+		}
+
+		if err != nil {
+			exit(err)
+		}
+	},
+}
+
+func NewExecCommand() *cobra.Command {
+	addCommonNamespaceCommandFlags(execCmd)
+	execCmd.Flags().BoolVarP(&execStdin, "stdin", "i", false, "Pass stdin to the command")
+	execCmd.Flags().BoolVarP(&execTTY, "tty", "t", false, "Allocate a terminal for the command")
+	execCmd.Flags().StringVarP(&container, "container", "c", "", "Container in which the command is executed. Default is the first container of the pod")
+
+	return execCmd
+}
+
+func runExec(namespace, target string, command []string) error {
+
+	if namespace == "" {
+		return errNamespaceRequired()
+	}
+
+	api, _ := newCommandAPI()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	opts := resource.ExecOptions{
+		Target:    target,
+		Container: container,
+		Command:   command,
+		TTY:       execTTY,
+		Stdout:    os.Stdout,
+		Stderr:    os.Stderr,
+	}
+
+	if execStdin {
+		opts.Stdin = os.Stdin
+	}
+
+	fd := int(os.Stdin.Fd())
+
+	// With a terminal, keys such as Ctrl-C are sent to the remote command instead of the cli.
+	if execTTY && execStdin && term.IsTerminal(fd) {
+		state, err := term.MakeRaw(fd)
+		if err != nil {
+			return errors.Wrap(err, errors.Internal, "unable to configure the terminal")
+		}
+		defer term.Restore(fd, state)
+
+		sizes := make(chan resource.TerminalSize, 1)
+		if width, height, err := term.GetSize(fd); err == nil {
+			sizes <- resource.TerminalSize{Width: uint16(width), Height: uint16(height)}
+		}
+		go watchResize(ctx, fd, sizes)
+
+		opts.Resize = sizes
+	}
+
+	return api.Exec(ctx, namespace, opts)
+}
@@ -0,0 +1,39 @@
+//go:build !windows
+
+package cmd
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"golang.org/x/term"
+
+	"github.com/Meetic/blackbeard/pkg/resource"
+)
+
+// watchResize sends the terminal size each time the terminal is resized
+func watchResize(ctx context.Context, fd int, sizes chan<- resource.TerminalSize) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGWINCH)
+	defer signal.Stop(signals)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-signals:
+			width, height, err := term.GetSize(fd)
+			if err != nil {
+				continue
+			}
+
+			select {
+			case sizes <- resource.TerminalSize{Width: uint16(width), Height: uint16(height)}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,10 @@
+package cmd
+
+import (
+	"context"
+
+	"github.com/Meetic/blackbeard/pkg/resource"
+)
+
+// watchResize does nothing since windows terminals do not notify resizes
+func watchResize(ctx context.Context, fd int, sizes chan<- resource.TerminalSize) {}
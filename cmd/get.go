@@ -4,26 +4,53 @@ import (
 	"bytes"
 	"fmt"
 	"html/template"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/duration"
 
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
+
+	"github.com/Meetic/blackbeard/pkg/resource"
 )
 
 var getCmd = &cobra.Command{
-	Use:   "get",
+	Use:   "get [KIND] [NAME]",
 	Short: "Show informations about a given namespace.",
 	Long: `This command display informations from a given namespace such as the list of exposed services
-or the url where you can join services throw ingress.`,
+or the url where you can join services throw ingress.
+
+It also lists the objects of a given kind in the namespace : pods, deployments, statefulsets, jobs, cronjobs,
+configmaps, secrets or pvcs. Secret values are never displayed.`,
+	Args: cobra.MaximumNArgs(2),
 	Run: func(cmd *cobra.Command, args []string) {
-		runGet()
+		if len(args) == 0 {
+			runGet()
+			return
+		}
+
+		var name string
+		if len(args) > 1 {
+			name = args[1]
+		}
+
+		if err := runGetResources(args[0], name); err != nil {
+			exit(err)
+		}
 	},
 }
 
 func NewGetCommand() *cobra.Command {
 	addCommonNamespaceCommandFlags(getCmd)
+	addOutputFlag(getCmd)
 
 	getCmd.AddCommand(NewGetNamespacesCommand())
 	getCmd.AddCommand(NewGetServicesCommand())
+	getCmd.AddCommand(NewGetQuotaCommand())
+	getCmd.AddCommand(NewGetDriftCommand())
 
 	return getCmd
 }
@@ -36,7 +63,7 @@ Using the get command without any sub-command makes no sens. Please use one of t
 {{end}}
 `))
 
-	data := []string{"get services", "get namespaces"}
+	data := []string{"get services", "get namespaces", "get quota", "get drift", "get KIND [NAME], KIND being one of " + strings.Join(resource.Kinds(), ", ")}
 
 	contents := bytes.Buffer{}
 	if err := tpl.Execute(&contents, data); err != nil {
@@ -46,3 +73,48 @@ Using the get command without any sub-command makes no sens. Please use one of t
 	fmt.Println(contents.String())
 
 }
+
+func runGetResources(kind, name string) error {
+
+	if namespace == "" {
+		return errNamespaceRequired()
+	}
+
+	api, _ := newCommandAPI()
+
+	var objects []resource.Object
+
+	if name == "" {
+		list, err := api.ListResources(namespace, kind)
+		if err != nil {
+			return err
+		}
+		objects = list
+	} else {
+		object, err := api.GetResource(namespace, kind, name)
+		if err != nil {
+			return err
+		}
+		objects = append(objects, *object)
+	}
+
+	tbl := newTable([]string{"Name", "Status", "Age"}, "Labels")
+	for _, o := range objects {
+		var labels []string
+		for k, v := range o.Labels {
+			labels = append(labels, k+"="+v)
+		}
+		sort.Strings(labels)
+
+		tbl.addRow(
+			[]string{o.Name, o.Status, duration.HumanDuration(time.Since(o.CreatedAt))},
+			strings.Join(labels, ","),
+		)
+	}
+
+	if name != "" {
+		return printObject(os.Stdout, output, objects[0], tbl)
+	}
+
+	return printObject(os.Stdout, output, objects, tbl)
+}
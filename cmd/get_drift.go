@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Meetic/blackbeard/pkg/resource"
+)
+
+var getDriftCmd = &cobra.Command{
+	Use:   "drift",
+	Short: "Show the changes made to the objects of a namespace since its last apply.",
+	Long: `This command compares the live objects of a namespace with the configs rendered by its last apply,
+for instance after a kubectl edit or kubectl scale.
+
+Only the fields set in the configs are compared, so the fields defaulted by kubernetes are not reported.
+Objects removed from the namespace are reported as missing.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runGetDrift(); err != nil {
+			exit(err)
+		}
+	},
+}
+
+func NewGetDriftCommand() *cobra.Command {
+	addCommonNamespaceCommandFlags(getDriftCmd)
+	addOutputFlag(getDriftCmd)
+	return getDriftCmd
+}
+
+func runGetDrift() error {
+
+	if namespace == "" {
+		return errNamespaceRequired()
+	}
+
+	api, _ := newCommandAPI()
+
+	report, err := api.Drift(namespace)
+	if err != nil {
+		return err
+	}
+
+	return printDriftReport(os.Stdout, output, report)
+}
+
+func printDriftReport(out io.Writer, format string, report *resource.DriftReport) error {
+	tbl := newTable([]string{"Kind", "Name", "Field", "Expected", "Actual"})
+
+	for _, o := range report.Objects {
+		if o.Missing {
+			tbl.addRow([]string{o.Kind, o.Name, "", "", "<missing>"})
+			continue
+		}
+
+		for _, f := range o.Fields {
+			actual := "<none>"
+			if f.Actual != nil {
+				actual = fmt.Sprint(f.Actual)
+			}
+
+			tbl.addRow([]string{o.Kind, o.Name, f.Path, fmt.Sprint(f.Expected), actual})
+		}
+	}
+
+	return printObject(out, format, report, tbl)
+}
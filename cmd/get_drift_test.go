@@ -0,0 +1,32 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Meetic/blackbeard/pkg/resource"
+)
+
+func TestPrintDriftReport(t *testing.T) {
+	report := &resource.DriftReport{
+		Namespace: "test",
+		Drifted:   true,
+		Objects: []resource.ObjectDrift{
+			{Kind: "Deployment", Name: "api", Fields: []resource.FieldDrift{
+				{Path: "spec.replicas", Expected: int64(1), Actual: int64(3)},
+				{Path: "spec.paused", Expected: false},
+			}},
+			{Kind: "ConfigMap", Name: "settings", Missing: true},
+		},
+	}
+
+	out := bytes.Buffer{}
+	assert.Nil(t, printDriftReport(&out, outputTable, report))
+	lines := strings.Split(out.String(), "\n")
+	assert.Equal(t, []string{"Deployment", "api", "spec.replicas", "1", "3"}, strings.Fields(lines[1]))
+	assert.Equal(t, []string{"Deployment", "api", "spec.paused", "false", "<none>"}, strings.Fields(lines[2]))
+	assert.Equal(t, []string{"ConfigMap", "settings", "<missing>"}, strings.Fields(lines[3]))
+}
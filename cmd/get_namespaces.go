@@ -1,12 +1,12 @@
 package cmd
 
 import (
-	"errors"
 	"fmt"
 	"os"
-	"text/tabwriter"
+	"sort"
+	"strconv"
+	"strings"
 
-	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 )
 
@@ -14,38 +14,54 @@ var getNamespacesCmd = &cobra.Command{
 	Use:   "namespaces",
 	Short: "Show informations about kubernetes namespaces.",
 	Long: `Show informations about kubernetes namespaces such as names, status (percentage of pods in a running status),
-managed or not with the current playbook, etc.`,
+managed or not with the current playbook, etc.
+
+Namespaces may be filtered using a label selector :
+
+  blackbeard get namespaces -l team=dating,env!=prod`,
 	Run: func(cmd *cobra.Command, args []string) {
 		err := runGetNamespaces()
 		if err != nil {
-			logrus.Fatal(err.Error())
+			exit(err)
 		}
 
 	},
 }
 
+var selector string
+
 func NewGetNamespacesCommand() *cobra.Command {
+	addOutputFlag(getNamespacesCmd)
+	getNamespacesCmd.Flags().StringVarP(&selector, "selector", "l", "", "Label selector of the namespaces to show (ex: team=dating,env!=prod)")
 	return getNamespacesCmd
 }
 
 func runGetNamespaces() error {
 
-	api := newAPI(newFileClient(playbookDir), newKubernetesClient())
+	api, _ := newCommandAPI()
 
-	namespaces, err := api.ListNamespaces()
+	namespaces, err := api.ListNamespaces(selector)
 	if err != nil {
-		return errors.New(fmt.Sprintf("an error occurend when getting information about namespaces : %v", err))
+		return fmt.Errorf("an error occurend when getting information about namespaces : %w", err)
 	}
 
-	w := new(tabwriter.Writer)
-	w.Init(os.Stdout, 0, 8, 0, '\t', 0)
-	fmt.Fprintln(w, "Namespace\tPhase\tStatus\tManaged\t")
+	tbl := newTable(
+		[]string{"Namespace", "Phase", "Status", "Managed"},
+		"TTL", "Owner", "Playbook", "Last Applied", "Labels",
+	)
 	for _, namespace := range namespaces {
-		fmt.Fprint(w, fmt.Sprintf("%s\t%s\t%d%%\t%t\t\n", namespace.Name, namespace.Phase, namespace.Status, namespace.Managed))
+		var labels []string
+		for k, v := range namespace.Labels {
+			labels = append(labels, k+"="+v)
+		}
+		sort.Strings(labels)
+
+		tbl.addRow(
+			[]string{namespace.Name, namespace.Phase, fmt.Sprintf("%d%%", namespace.Status), strconv.FormatBool(namespace.Managed)},
+			namespace.TTL, namespace.Owner, namespace.Playbook, namespace.LastApplied, strings.Join(labels, ","),
+		)
 	}
-	fmt.Fprintln(w)
-	w.Flush()
 
-	return nil
+	return printObject(os.Stdout, output, namespaces, tbl)
 
 }
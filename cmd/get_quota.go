@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"os"
+	"strconv"
+
+	"github.com/spf13/cobra"
+)
+
+var getQuotaCmd = &cobra.Command{
+	Use:   "quota",
+	Short: "Show the usage of the resource quotas of a given namespace.",
+	Long: `This command displays, for each resource limited by a resource quota of the namespace,
+the current usage against the hard limit.
+
+Quotas are created from the size profile of the namespace, defined in the namespace.yaml file of the playbook.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runGetQuota(); err != nil {
+			exit(err)
+		}
+	},
+}
+
+func NewGetQuotaCommand() *cobra.Command {
+	addCommonNamespaceCommandFlags(getQuotaCmd)
+	addOutputFlag(getQuotaCmd)
+	return getQuotaCmd
+}
+
+func runGetQuota() error {
+
+	if namespace == "" {
+		return errNamespaceRequired()
+	}
+
+	api, _ := newCommandAPI()
+
+	usages, err := api.GetQuota(namespace)
+	if err != nil {
+		return err
+	}
+
+	tbl := newTable([]string{"Resource", "Used", "Hard", "Usage"}, "Quota")
+	for _, u := range usages {
+		tbl.addRow([]string{u.Resource, u.Used, u.Hard, strconv.Itoa(u.Percent) + "%"}, u.Quota)
+	}
+
+	return printObject(os.Stdout, output, usages, tbl)
+}
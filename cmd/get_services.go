@@ -1,12 +1,10 @@
 package cmd
 
 import (
-	"errors"
 	"fmt"
 	"os"
-	"text/tabwriter"
+	"strconv"
 
-	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 )
 
@@ -18,7 +16,7 @@ or the url where you can join services throw ingress.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		err := runGetServices()
 		if err != nil {
-			logrus.Fatal(err.Error())
+			exit(err)
 		}
 
 	},
@@ -26,34 +24,39 @@ or the url where you can join services throw ingress.`,
 
 func NewGetServicesCommand() *cobra.Command {
 	addCommonNamespaceCommandFlags(getServicesCmd)
+	addOutputFlag(getServicesCmd)
 	return getServicesCmd
 }
 
 func runGetServices() error {
 
 	if namespace == "" {
-		return errors.New("you must specified a namespace using the --namespace flag")
+		return errNamespaceRequired()
 	}
 
-	api := newAPI(newFileClient(playbookDir), newKubernetesClient())
+	api, _ := newCommandAPI()
 
 	// get exposed services (NodePort, LoadBalancer)
 	services, err := api.ListExposedServices(namespace)
 	if err != nil {
-		return errors.New(fmt.Sprintf("an error occurend when getting information about services : %v", err))
+		return fmt.Errorf("an error occurend when getting information about services : %w", err)
 	}
 
-	w := new(tabwriter.Writer)
-	w.Init(os.Stdout, 0, 8, 0, '\t', 0)
-	fmt.Fprintln(w, "Service Name\tAddress\tPort\tExposed Port\t")
+	tbl := newTable([]string{"Service Name", "URL", "Source", "Port"}, "Address", "Exposed Port", "TLS Secret")
 	for _, svc := range services {
 		for _, p := range svc.Ports {
-			fmt.Fprintf(w, fmt.Sprintf("%s\t%s\t%d\t%d\t\n", svc.Name, svc.Addr, p.Port, p.ExposedPort))
+			url := p.URL
+			if url == "" {
+				url = svc.URL
+			}
+
+			tbl.addRow(
+				[]string{svc.Name, url, svc.Source, strconv.Itoa(int(p.Port))},
+				svc.Addr, strconv.Itoa(int(p.ExposedPort)), svc.TLSSecret,
+			)
 		}
 	}
-	fmt.Fprintln(w)
-	w.Flush()
 
-	return nil
+	return printObject(os.Stdout, output, services, tbl)
 
 }
@@ -0,0 +1,22 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var inventoryCmd = &cobra.Command{
+	Use:   "inventory",
+	Short: "Inspect the inventories of the namespaces.",
+	Long: `This command groups the sub-commands working on the inventories of the namespaces, like diff.
+`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+	},
+}
+
+func NewInventoryCommand() *cobra.Command {
+	inventoryCmd.AddCommand(NewInventoryDiffCommand())
+
+	return inventoryCmd
+}
@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Meetic/blackbeard/pkg/errors"
+	"github.com/Meetic/blackbeard/pkg/playbook"
+)
+
+var diffDefaults bool
+
+var inventoryDiffCmd = &cobra.Command{
+	Use:   "diff NAMESPACE [AGAINST]",
+	Short: "Show the values differing between the inventories of two namespaces.",
+	Long: `This command compares the values of the inventory of a namespace with the values of the inventory of another
+namespace, or with the default inventory of the playbook using --defaults :
+
+  blackbeard inventory diff my-namespace staging
+  blackbeard inventory diff my-namespace --defaults
+
+Values of the namespace missing from the other inventory are reported as added, values missing from the namespace
+as removed. Applications are matched by name, whatever their order in the inventories.
+`,
+	Args: cobra.RangeArgs(1, 2),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runInventoryDiff(args, diffDefaults); err != nil {
+			exit(err)
+		}
+	},
+}
+
+func NewInventoryDiffCommand() *cobra.Command {
+	addOutputFlag(inventoryDiffCmd)
+	inventoryDiffCmd.Flags().BoolVar(&diffDefaults, "defaults", false, "compare the inventory with the default inventory of the playbook")
+
+	return inventoryDiffCmd
+}
+
+func runInventoryDiff(args []string, defaults bool) error {
+	var against string
+
+	switch {
+	case len(args) == 2 && defaults:
+		return errors.New(errors.Invalid, "you must either give a namespace to compare with or use the --defaults flag, not both")
+	case len(args) == 2:
+		against = args[1]
+	case !defaults:
+		return errors.New(errors.Invalid, "you must give a namespace to compare with or use the --defaults flag")
+	}
+
+	api, _ := newCommandAPI()
+
+	diff, err := api.Inventories().Diff(args[0], against)
+	if err != nil {
+		return err
+	}
+
+	return printInventoryDiff(os.Stdout, output, diff)
+}
+
+// printInventoryDiff prints a row for each value differing between the inventories, along with the value of each of them.
+func printInventoryDiff(out io.Writer, format string, diff *playbook.InventoryDiff) error {
+	against := diff.Against
+	if against == "" {
+		against = "defaults"
+	}
+
+	tbl := newTable([]string{"Path", "Change", diff.Namespace, against})
+
+	for _, d := range diff.Differences {
+		tbl.addRow([]string{d.Path, d.Change, formatValue(d.Value), formatValue(d.Against)})
+	}
+
+	return printObject(out, format, diff, tbl)
+}
@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Meetic/blackbeard/pkg/playbook"
+)
+
+func TestPrintInventoryDiff(t *testing.T) {
+	diff := &playbook.InventoryDiff{
+		Namespace: "test",
+		Differences: []playbook.Difference{
+			{Path: "front.version", Change: playbook.ChangeChanged, Value: "1.1", Against: "1.0"},
+			{Path: "microservices[search]", Change: playbook.ChangeAdded, Value: map[string]interface{}{"name": "search"}},
+		},
+	}
+
+	out := bytes.Buffer{}
+	assert.Nil(t, printInventoryDiff(&out, outputTable, diff))
+	lines := strings.Split(out.String(), "\n")
+	assert.Equal(t, []string{"Path", "Change", "test", "defaults"}, strings.Fields(lines[0]))
+	assert.Equal(t, `front.version changed "1.1" "1.0"`, strings.Join(strings.Fields(lines[1]), " "))
+	assert.Equal(t, `microservices[search] added {"name":"search"} <none>`, strings.Join(strings.Fields(lines[2]), " "))
+
+	diff.Against = "staging"
+	out.Reset()
+	assert.Nil(t, printInventoryDiff(&out, outputTable, diff))
+	assert.Equal(t, []string{"Path", "Change", "test", "staging"}, strings.Fields(strings.Split(out.String(), "\n")[0]))
+}
+
+func TestInventoryDiffArgs(t *testing.T) {
+	assert.Error(t, runInventoryDiff([]string{"test"}, false))
+	assert.Error(t, runInventoryDiff([]string{"test", "staging"}, true))
+}
@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Meetic/blackbeard/pkg/resource"
+)
+
+var (
+	follow    bool
+	since     time.Duration
+	container string
+)
+
+// logColors are the ANSI colors used to prefix log lines by pod
+var logColors = []string{"31", "32", "33", "34", "35", "36", "91", "92", "93", "94", "95", "96"}
+
+var logsCmd = &cobra.Command{
+	Use:   "logs [workload]",
+	Short: "Show the logs of the pods of a namespace.",
+	Long: `This command aggregates the logs of all pods of a namespace, or of the pods of a given workload.
+Each line is prefixed by the pod and the container that wrote it.
+
+The workload is the name of a deployment, a statefulset or a job, optionally prefixed by its kind (ex: job/migration).
+`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		var workload string
+		if len(args) > 0 {
+			workload = args[0]
+		}
+
+		if err := runLogs(namespace, workload); err != nil {
+			exit(err)
+		}
+	},
+}
+
+func NewLogsCommand() *cobra.Command {
+	addCommonNamespaceCommandFlags(logsCmd)
+	logsCmd.Flags().BoolVarP(&follow, "follow", "f", false, "Keep streaming new logs")
+	logsCmd.Flags().DurationVar(&since, "since", 0, "Only show logs newer than this duration (ex: 10m)")
+	logsCmd.Flags().StringVarP(&container, "container", "c", "", "Only show the logs of this container")
+
+	return logsCmd
+}
+
+func runLogs(namespace, workload string) error {
+
+	if namespace == "" {
+		return errNamespaceRequired()
+	}
+
+	api, _ := newCommandAPI()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	lines := make(chan resource.LogLine)
+	errs := make(chan error, 1)
+
+	go func() {
+		errs <- api.Pods().Logs(ctx, namespace, resource.LogOptions{
+			Workload:  workload,
+			Container: container,
+			Follow:    follow,
+			Since:     since,
+		}, lines)
+		close(lines)
+	}()
+
+	colored := isTerminal(os.Stdout)
+	for line := range lines {
+		printLogLine(os.Stdout, line, colored)
+	}
+
+	return <-errs
+}
+
+// printLogLine writes a log line prefixed by its pod and container.
+// The prefix color is always the same for a given pod.
+func printLogLine(out io.Writer, line resource.LogLine, colored bool) {
+	prefix := line.Pod + " " + line.Container
+	if colored {
+		h := fnv.New32a()
+		h.Write([]byte(line.Pod))
+		prefix = fmt.Sprintf("\x1b[%sm%s\x1b[0m", logColors[h.Sum32()%uint32(len(logColors))], prefix)
+	}
+
+	fmt.Fprintf(out, "%s %s\n", prefix, line.Message)
+}
+
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,25 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Meetic/blackbeard/pkg/resource"
+)
+
+func TestPrintLogLine(t *testing.T) {
+	line := resource.LogLine{Pod: "api-1", Container: "php", Message: "started"}
+
+	out := bytes.Buffer{}
+	printLogLine(&out, line, false)
+	assert.Equal(t, "api-1 php started\n", out.String())
+
+	colored := bytes.Buffer{}
+	printLogLine(&colored, line, true)
+	other := bytes.Buffer{}
+	printLogLine(&other, line, true)
+	assert.Equal(t, colored.String(), other.String())
+	assert.Contains(t, colored.String(), "\x1b[")
+}
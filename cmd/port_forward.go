@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Meetic/blackbeard/pkg/errors"
+	"github.com/Meetic/blackbeard/pkg/resource"
+)
+
+var portForwardCmd = &cobra.Command{
+	Use:   "port-forward svc/NAME [LOCAL_PORT:]REMOTE_PORT",
+	Short: "Forward a local port to a service of a namespace.",
+	Long: `This command forwards a local port to a ready pod of a service, including services which are not exposed
+outside of the cluster such as headless services.
+
+When the pod restarts, the forwarding is established again to another ready pod using the same local port.
+Without local port, a free port is chosen.
+
+This command requires a kubernetes config and is not available when using a blackbeard server.
+`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runPortForward(namespace, args[0], args[1]); err != nil {
+			exit(err)
+		}
+	},
+}
+
+func NewPortForwardCommand() *cobra.Command {
+	addCommonNamespaceCommandFlags(portForwardCmd)
+	return portForwardCmd
+}
+
+func runPortForward(namespace, target, ports string) error {
+
+	if namespace == "" {
+		return errNamespaceRequired()
+	}
+
+	if isRemote() {
+		return errors.New(errors.Invalid, "port-forward is not available when using a blackbeard server")
+	}
+
+	pf, err := parsePortForward(namespace, target, ports)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	forwards := resource.NewPortForwardService(newKubernetesClient().PortForwards())
+
+	return forwards.Forward(ctx, pf, func(localPort int) {
+		fmt.Printf("Forwarding from http://localhost:%d to svc/%s:%d\n", localPort, pf.Service, pf.RemotePort)
+	})
+}
+
+// parsePortForward reads a service given as svc/NAME, service/NAME or NAME and ports given as [LOCAL:]REMOTE
+func parsePortForward(namespace, target, ports string) (resource.PortForward, error) {
+	pf := resource.PortForward{Namespace: namespace, Service: target}
+
+	if kind, name, ok := strings.Cut(target, "/"); ok {
+		if kind != "svc" && kind != "service" && kind != "services" {
+			return pf, errors.New(errors.Invalid, "only services can be forwarded, use svc/NAME")
+		}
+		pf.Service = name
+	}
+
+	local, remote, ok := strings.Cut(ports, ":")
+	if !ok {
+		local, remote = "0", ports
+	}
+
+	var err error
+
+	if pf.LocalPort, err = strconv.Atoi(local); err != nil || pf.LocalPort < 0 {
+		return pf, errors.New(errors.Invalid, "invalid local port %q", local)
+	}
+
+	if pf.RemotePort, err = strconv.Atoi(remote); err != nil || pf.RemotePort <= 0 {
+		return pf, errors.New(errors.Invalid, "invalid remote port %q", remote)
+	}
+
+	return pf, nil
+}
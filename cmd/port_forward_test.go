@@ -0,0 +1,25 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Meetic/blackbeard/pkg/errors"
+	"github.com/Meetic/blackbeard/pkg/resource"
+)
+
+func TestParsePortForward(t *testing.T) {
+	pf, err := parsePortForward("test", "svc/api", "8080:80")
+	assert.Nil(t, err)
+	assert.Equal(t, resource.PortForward{Namespace: "test", Service: "api", LocalPort: 8080, RemotePort: 80}, pf)
+
+	pf, err = parsePortForward("test", "api", "50051")
+	assert.Nil(t, err)
+	assert.Equal(t, resource.PortForward{Namespace: "test", Service: "api", LocalPort: 0, RemotePort: 50051}, pf)
+
+	for _, args := range [][]string{{"deploy/api", "80"}, {"svc/api", "http"}, {"svc/api", "a:80"}} {
+		_, err := parsePortForward("test", args[0], args[1])
+		assert.True(t, errors.Is(err, errors.Invalid), args)
+	}
+}
@@ -0,0 +1,193 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/util/jsonpath"
+	"sigs.k8s.io/yaml"
+
+	"github.com/Meetic/blackbeard/pkg/errors"
+	"github.com/Meetic/blackbeard/pkg/playbook"
+)
+
+// Output formats supported by the -o flag
+const (
+	outputTable      = "table"
+	outputWide       = "wide"
+	outputJSON       = "json"
+	outputYAML       = "yaml"
+	outputJSONPath   = "jsonpath="
+	outputGoTemplate = "go-template="
+)
+
+var output string
+
+func addOutputFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&output, "output", "o", outputTable, "Output format. One of: table|wide|json|yaml|jsonpath=<template>|go-template=<template>")
+}
+
+// validateOutput returns an error if the output format is not supported
+func validateOutput(format string) error {
+	switch {
+	case format == "", format == outputTable, format == outputWide, format == outputJSON, format == outputYAML:
+		return nil
+	case strings.HasPrefix(format, outputJSONPath), strings.HasPrefix(format, outputGoTemplate):
+		return nil
+	}
+
+	return errors.New(errors.Invalid, "unknown output format %q, use one of table, wide, json, yaml, jsonpath=<template> or go-template=<template>", format)
+}
+
+// isMachineReadable returns true if the output format is meant to be parsed by another program
+func isMachineReadable(format string) bool {
+	return format != "" && format != outputTable && format != outputWide
+}
+
+// table is the tabular representation of an object.
+// Wide columns are only printed using the wide output format.
+type table struct {
+	header     []string
+	wideHeader []string
+	rows       [][]string
+	wideRows   [][]string
+}
+
+func newTable(header []string, wideHeader ...string) *table {
+	return &table{header: header, wideHeader: wideHeader}
+}
+
+// addRow adds a row to the table. wide are the cells of the wide columns.
+func (t *table) addRow(cells []string, wide ...string) {
+	t.rows = append(t.rows, cells)
+	t.wideRows = append(t.wideRows, wide)
+}
+
+func (t *table) print(out io.Writer, wide bool) error {
+	w := new(tabwriter.Writer)
+	w.Init(out, 0, 8, 0, '\t', 0)
+
+	header := t.header
+	if wide {
+		header = append(append([]string{}, t.header...), t.wideHeader...)
+	}
+	fmt.Fprintln(w, strings.Join(header, "\t")+"\t")
+
+	for i, row := range t.rows {
+		cells := row
+		if wide {
+			cells = append(append([]string{}, row...), t.wideRows[i]...)
+		}
+		fmt.Fprintln(w, strings.Join(cells, "\t")+"\t")
+	}
+
+	fmt.Fprintln(w)
+
+	return w.Flush()
+}
+
+// printObject prints obj using the given output format.
+// tbl is the tabular representation of obj, used by the table and wide formats.
+func printObject(out io.Writer, format string, obj interface{}, tbl *table) error {
+	switch {
+	case format == "" || format == outputTable:
+		return tbl.print(out, false)
+	case format == outputWide:
+		return tbl.print(out, true)
+	case format == outputJSON:
+		b, err := json.MarshalIndent(obj, "", "  ")
+		if err != nil {
+			return errors.Wrap(err, errors.Internal, "unable to encode output as json")
+		}
+		_, err = fmt.Fprintln(out, string(b))
+		return err
+	case format == outputYAML:
+		b, err := yaml.Marshal(obj)
+		if err != nil {
+			return errors.Wrap(err, errors.Internal, "unable to encode output as yaml")
+		}
+		_, err = out.Write(b)
+		return err
+	case strings.HasPrefix(format, outputJSONPath):
+		return printJSONPath(out, strings.TrimPrefix(format, outputJSONPath), obj)
+	case strings.HasPrefix(format, outputGoTemplate):
+		return printGoTemplate(out, strings.TrimPrefix(format, outputGoTemplate), obj)
+	}
+
+	return validateOutput(format)
+}
+
+// generic converts obj into maps and slices so templates use the json field names
+func generic(obj interface{}) (interface{}, error) {
+	b, err := json.Marshal(obj)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.Internal, "unable to encode output")
+	}
+
+	var data interface{}
+	if err := json.Unmarshal(b, &data); err != nil {
+		return nil, errors.Wrap(err, errors.Internal, "unable to encode output")
+	}
+
+	return data, nil
+}
+
+func printJSONPath(out io.Writer, tpl string, obj interface{}) error {
+	j := jsonpath.New("output")
+	if err := j.Parse(tpl); err != nil {
+		return errors.Wrap(err, errors.Invalid, "invalid jsonpath template %q", tpl)
+	}
+
+	data, err := generic(obj)
+	if err != nil {
+		return err
+	}
+
+	if err := j.Execute(out, data); err != nil {
+		return errors.Wrap(err, errors.Invalid, "unable to execute jsonpath template %q", tpl)
+	}
+
+	_, err = fmt.Fprintln(out)
+	return err
+}
+
+func printGoTemplate(out io.Writer, tpl string, obj interface{}) error {
+	t, err := template.New("output").Parse(tpl)
+	if err != nil {
+		return errors.Wrap(err, errors.Invalid, "invalid go template %q", tpl)
+	}
+
+	data, err := generic(obj)
+	if err != nil {
+		return err
+	}
+
+	if err := t.Execute(out, data); err != nil {
+		return errors.Wrap(err, errors.Invalid, "unable to execute go template %q", tpl)
+	}
+
+	_, err = fmt.Fprintln(out)
+	return err
+}
+
+// result is the outcome of a command changing a namespace
+type result struct {
+	Namespace string             `json:"namespace"`
+	Action    string             `json:"action"`
+	Inventory playbook.Inventory `json:"inventory"`
+}
+
+// printResult prints the result of a command changing a namespace when a machine readable output is requested.
+// Otherwise, commands only log their progress.
+func printResult(out io.Writer, format string, res result) error {
+	if !isMachineReadable(format) {
+		return nil
+	}
+
+	return printObject(out, format, res, nil)
+}
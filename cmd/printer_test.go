@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Meetic/blackbeard/pkg/api"
+	"github.com/Meetic/blackbeard/pkg/errors"
+)
+
+func namespacesTable(namespaces []api.Namespace) *table {
+	tbl := newTable([]string{"Namespace", "Phase"}, "Owner")
+	for _, ns := range namespaces {
+		tbl.addRow([]string{ns.Name, ns.Phase}, ns.Owner)
+	}
+	return tbl
+}
+
+func TestPrintObject(t *testing.T) {
+	namespaces := []api.Namespace{{Name: "foo", Phase: "Active", Owner: "alice"}}
+
+	tests := []struct {
+		format   string
+		expected string
+	}{
+		{"table", "Namespace\tPhase\t\nfoo\t\tActive\t\n\n"},
+		{"wide", "Namespace\tPhase\tOwner\t\nfoo\t\tActive\talice\t\n\n"},
+		{"jsonpath={[*].name}", "foo\n"},
+		{"go-template={{range .}}{{.owner}}{{end}}", "alice\n"},
+		{"yaml", "- managed: false\n  name: foo\n  owner: alice\n  phase: Active\n  status: 0\n"},
+		{"json", "[\n  {\n    \"name\": \"foo\",\n    \"phase\": \"Active\",\n    \"status\": 0,\n    \"managed\": false,\n    \"owner\": \"alice\"\n  }\n]\n"},
+	}
+
+	for _, test := range tests {
+		out := bytes.Buffer{}
+		assert.Nil(t, printObject(&out, test.format, namespaces, namespacesTable(namespaces)), test.format)
+		assert.Equal(t, test.expected, out.String(), test.format)
+	}
+}
+
+func TestPrintObjectInvalidFormat(t *testing.T) {
+	for _, format := range []string{"xml", "jsonpath={.name", "go-template={{.name"} {
+		err := printObject(&bytes.Buffer{}, format, []api.Namespace{}, newTable(nil))
+		assert.True(t, errors.Is(err, errors.Invalid), format)
+	}
+}
+
+func TestPrintResult(t *testing.T) {
+	out := bytes.Buffer{}
+	assert.Nil(t, printResult(&out, "table", result{Namespace: "foo", Action: "created"}))
+	assert.Empty(t, out.String())
+
+	assert.Nil(t, printResult(&out, "jsonpath={.action}", result{Namespace: "foo", Action: "created"}))
+	assert.Equal(t, "created\n", out.String())
+}
@@ -1,43 +1,54 @@
 package cmd
 
 import (
-	"errors"
+	"os"
 
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
+
+	"github.com/Meetic/blackbeard/pkg/api"
 )
 
 // resetCmd represents the reset command
 var resetCmd = &cobra.Command{
 	Use:   "reset",
 	Short: "Reset a namespace based on the template files and the default inventory.",
-	Long:  `This command will override the inventory and the config files for the given namespace and apply the changes into Kubernetes.`,
+	Long: `This command will override the inventory and the config files for the given namespace and apply the changes into Kubernetes.
+
+Use --selector or --all to reset many namespaces at once.`,
 
 	Run: func(cmd *cobra.Command, args []string) {
+		if isBulk(bulkOpts) {
+			if err := runBulkCommand(api.BulkReset, bulkOpts); err != nil {
+				exit(err)
+			}
+			return
+		}
+
 		err := runReset(namespace)
 		if err != nil {
-			logrus.Fatal(err.Error())
+			exit(err)
 		}
 	},
 }
 
 func NewResetCommand() *cobra.Command {
 	addCommonNamespaceCommandFlags(resetCmd)
+	addOutputFlag(resetCmd)
+	addBulkFlags(resetCmd)
 	return resetCmd
 }
 
 func runReset(namespace string) error {
 
 	if namespace == "" {
-		return errors.New("you must specified a namespace using the --namespace flag")
+		return errNamespaceRequired()
 	}
 
-	files := newFileClient(playbookDir)
-
-	api := newAPI(files, newKubernetesClient())
+	api, configPath := newCommandAPI()
 
 	//Reset inventory file
-	err := api.Reset(namespace, files.ConfigPath())
+	err := api.Reset(namespace, configPath)
 	if err != nil {
 		return err
 	}
@@ -46,5 +57,14 @@ func runReset(namespace string) error {
 		"namespace": namespace,
 	}).Info("namespace has been reset successfully")
 
-	return nil
+	if !isMachineReadable(output) {
+		return nil
+	}
+
+	inv, err := api.Inventories().Get(namespace)
+	if err != nil {
+		return err
+	}
+
+	return printResult(os.Stdout, output, result{Namespace: namespace, Action: "reset", Inventory: inv})
 }
@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/Meetic/blackbeard/pkg/errors"
+)
+
+var restartAll bool
+
+var restartCmd = &cobra.Command{
+	Use:   "restart [deployment|statefulset] [NAME]",
+	Short: "Restart the pods of a deployment or a statefulset.",
+	Long: `This command recreates the pods of a deployment or a statefulset using a rolling update, like kubectl rollout restart.
+It is useful after updating a ConfigMap or pushing a new version of an image using the same tag.
+
+Use --all to restart every deployment and statefulset of the namespace.
+With --wait, the command returns once the rollouts are complete and the new pods are ready.
+`,
+	Args: cobra.MaximumNArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runRestart(namespace, args); err != nil {
+			exit(err)
+		}
+	},
+}
+
+func NewRestartCommand() *cobra.Command {
+	addCommonNamespaceCommandFlags(restartCmd)
+	restartCmd.Flags().BoolVar(&restartAll, "all", false, "restart every deployment and statefulset of the namespace")
+	restartCmd.Flags().BoolVar(&wait, "wait", false, "wait until the rollouts are complete and the new pods are ready")
+	restartCmd.Flags().DurationVarP(&timeout, "timeout", "t", defaultTimeout, "The max time to wait for pods to be all running.")
+
+	return restartCmd
+}
+
+func runRestart(namespace string, args []string) error {
+
+	if namespace == "" {
+		return errNamespaceRequired()
+	}
+
+	api, _ := newCommandAPI()
+
+	if restartAll {
+		if len(args) > 0 {
+			return errors.New(errors.Invalid, "a workload can not be given with --all")
+		}
+
+		restarted, err := api.RestartAll(namespace)
+		if err != nil {
+			return err
+		}
+
+		logrus.WithFields(logrus.Fields{
+			"namespace": namespace,
+			"workloads": restarted,
+		}).Info("workloads restarted")
+	} else {
+		kind, name, err := workloadArgs(args)
+		if err != nil {
+			return err
+		}
+
+		if err := api.Restart(namespace, kind, name); err != nil {
+			return err
+		}
+
+		logrus.WithFields(logrus.Fields{
+			"namespace": namespace,
+			kind:        name,
+		}).Info("workload restarted")
+	}
+
+	// restarted workloads are not ready until their rollout is complete, so the old pods are not waited for
+	if wait {
+		return waitForNamespaceReady(api, namespace)
+	}
+
+	return nil
+}
+
+// workloadArgs returns the kind and the name of a workload given as "kind name" or "kind/name"
+func workloadArgs(args []string) (string, string, error) {
+	switch len(args) {
+	case 2:
+		return args[0], args[1], nil
+	case 1:
+		if kind, name, ok := strings.Cut(args[0], "/"); ok {
+			return kind, name, nil
+		}
+	}
+
+	return "", "", errors.New(errors.Invalid, "a workload is required : restart deployment NAME, restart statefulset NAME or restart --all")
+}
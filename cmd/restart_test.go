@@ -0,0 +1,24 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Meetic/blackbeard/pkg/errors"
+)
+
+func TestWorkloadArgs(t *testing.T) {
+	kind, name, err := workloadArgs([]string{"deployment", "api"})
+	assert.Nil(t, err)
+	assert.Equal(t, "deployment", kind)
+	assert.Equal(t, "api", name)
+
+	kind, name, err = workloadArgs([]string{"sts/mysql"})
+	assert.Nil(t, err)
+	assert.Equal(t, "sts", kind)
+	assert.Equal(t, "mysql", name)
+
+	_, _, err = workloadArgs([]string{"api"})
+	assert.True(t, errors.Is(err, errors.Invalid))
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/spf13/viper"
 
 	"github.com/Meetic/blackbeard/pkg/api"
+	"github.com/Meetic/blackbeard/pkg/client"
 	"github.com/Meetic/blackbeard/pkg/files"
 	"github.com/Meetic/blackbeard/pkg/kubernetes"
 	"github.com/sirupsen/logrus"
@@ -21,6 +22,8 @@ var (
 	cfgFile           string
 	playbookDir       string
 	kubectlConfigPath string
+	server            string
+	token             string
 	v                 string
 	namespace         string
 	cors              bool
@@ -50,26 +53,50 @@ This action can be done using the "apply" command.
 func NewBlackbeardCommand() *cobra.Command {
 
 	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
-		if err := setUpLogs(os.Stdout, v); err != nil {
+		if err := validateOutput(output); err != nil {
+			return err
+		}
+
+		// keep stdout parsable when a machine readable output is requested
+		out := os.Stdout
+		if isMachineReadable(output) {
+			out = os.Stderr
+		}
+
+		if err := setUpLogs(out, v); err != nil {
 			return err
 		}
 		return nil
 	}
 
 	rootCmd.AddCommand(NewServeCommand())
+	rootCmd.AddCommand(NewAdoptCommand())
 	rootCmd.AddCommand(NewApplyCommand())
+	rootCmd.AddCommand(NewCheckCommand())
 	rootCmd.AddCommand(NewCreateCommand())
 	rootCmd.AddCommand(NewDeleteCommand())
+	rootCmd.AddCommand(NewDetachCommand())
+	rootCmd.AddCommand(NewDoctorCommand())
+	rootCmd.AddCommand(NewExecCommand())
 	rootCmd.AddCommand(NewGetCommand())
+	rootCmd.AddCommand(NewInventoryCommand())
+	rootCmd.AddCommand(NewLogsCommand())
+	rootCmd.AddCommand(NewPortForwardCommand())
 	rootCmd.AddCommand(NewResetCommand())
+	rootCmd.AddCommand(NewRestartCommand())
+	rootCmd.AddCommand(NewUpgradeCommand())
 	rootCmd.AddCommand(NewVersionCommand())
 
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.blackbeard.yaml)")
 	rootCmd.PersistentFlags().StringVar(&playbookDir, "dir", "", "Use the specified directory as root path to execute commands. Default is the current directory.")
 	rootCmd.PersistentFlags().StringVar(&kubectlConfigPath, "kube-config-path", kubernetes.KubeConfigDefaultPath(), "kubectl config file")
 	rootCmd.PersistentFlags().StringVarP(&v, "verbosity", "v", logrus.InfoLevel.String(), "Log level (debug, info, warn, error, fatal, panic")
+	rootCmd.PersistentFlags().StringVar(&server, "server", "", "Url of a blackbeard server. When set, commands call the server REST api instead of using a local playbook.")
+	rootCmd.PersistentFlags().StringVar(&token, "token", "", "Bearer token sent to the blackbeard server")
 
 	viper.BindPFlag("working-dir", rootCmd.PersistentFlags().Lookup("dir"))
+	viper.BindPFlag("server", rootCmd.PersistentFlags().Lookup("server"))
+	viper.BindPFlag("token", rootCmd.PersistentFlags().Lookup("token"))
 
 	initConfig()
 
@@ -105,6 +132,8 @@ func initConfig() {
 	}
 
 	playbookDir = viper.GetString("working-dir")
+	server = viper.GetString("server")
+	token = viper.GetString("token")
 
 }
 
@@ -117,7 +146,7 @@ func askForConfirmation(message string, reader io.Reader) bool {
 
 		response, err := r.ReadString('\n')
 		if err != nil {
-			logrus.Fatal(err)
+			exit(err)
 		}
 
 		response = strings.ToLower(strings.TrimSpace(response))
@@ -133,35 +162,67 @@ func askForConfirmation(message string, reader io.Reader) bool {
 func newKubernetesClient() *kubernetes.Client {
 	kube, err := kubernetes.NewClient(kubectlConfigPath)
 	if err != nil {
-		logrus.Fatal(err.Error())
+		exit(err)
 	}
 
 	return kube
 }
 
-func newFileClient(dir string) *files.Client {
-	f, err := files.NewClient(dir)
+func newFileClient(dir string, opts ...files.Option) *files.Client {
+	f, err := files.NewClient(dir, opts...)
 	if err != nil {
-		logrus.Fatal(err.Error())
+		exit(err)
 	}
 
 	return f
 
 }
 
+// newCommandAPI returns the api used by commands, with the configs path to give to it.
+// When a blackbeard server is configured, the api calls the server REST api and the configs path is empty.
+// Otherwise, it uses the playbook located in the working dir and the local kubernetes config.
+func newCommandAPI() (api.Api, string) {
+	if isRemote() {
+		return client.NewApi(newRemoteClient()), ""
+	}
+
+	files := newFileClient(playbookDir)
+
+	return newAPI(files, newKubernetesClient()), files.ConfigPath()
+}
+
+// isRemote returns true if commands must call a blackbeard server.
+func isRemote() bool {
+	return server != ""
+}
+
+func newRemoteClient() *client.Client {
+	c, err := client.NewClient(server, client.WithToken(token), client.WithRetries(2, time.Second))
+	if err != nil {
+		exit(err)
+	}
+
+	return c
+}
+
 func newAPI(files *files.Client, kube *kubernetes.Client) api.Api {
-	return api.NewApi(
-		files.Inventories(),
-		files.Configs(),
-		files.Playbooks(),
-		kube.Namespaces(),
-		kube.Pods(),
-		kube.Deployments(),
-		kube.Statefulsets(),
-		kube.Services(),
-		kube.Cluster(),
-		kube.Jobs(),
-	)
+	return api.NewApi(api.Repositories{
+		Inventories:    files.Inventories(),
+		Configs:        files.Configs(),
+		Playbooks:      files.Playbooks(),
+		Profiles:       files.Profiles(),
+		Namespaces:     kube.Namespaces(),
+		Pods:           kube.Pods(),
+		Deployments:    kube.Deployments(),
+		Statefulsets:   kube.Statefulsets(),
+		Services:       kube.Services(),
+		Cluster:        kube.Cluster(),
+		Jobs:           kube.Jobs(),
+		Objects:        kube.Objects(),
+		Execs:          kube.Execs(),
+		Drifts:         kube.Drifts(),
+		ReadinessRules: kube.ReadinessRules(),
+	})
 }
 
 func setUpLogs(out io.Writer, level string) error {
@@ -1,11 +1,30 @@
 package cmd
 
 import (
+	"context"
+	"path/filepath"
+	"time"
+
+	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 
+	"github.com/Meetic/blackbeard/pkg/api"
+	"github.com/Meetic/blackbeard/pkg/files"
 	"github.com/Meetic/blackbeard/pkg/http"
 )
 
+var (
+	enableExec        bool
+	execOrigins       []string
+	reconcileInterval time.Duration
+	reconcileFix      bool
+	driftInterval     time.Duration
+	playbookGit       string
+	playbookRef       string
+	playbookRefresh   time.Duration
+	playbookCache     string
+)
+
 // serveCmd represents the serve command
 var serveCmd = &cobra.Command{
 	Use:   "serve",
@@ -19,19 +38,68 @@ This API let the client use all the features provided by Blackbeard such as crea
 
 func NewServeCommand() *cobra.Command {
 	serveCmd.Flags().BoolVar(&cors, "cors", false, "Enable cors")
+	serveCmd.Flags().BoolVar(&enableExec, "enable-exec", false, "Enable the exec websocket, letting clients run commands in the pods of namespaces created by blackbeard")
+	serveCmd.Flags().StringSliceVar(&execOrigins, "exec-allowed-origins", nil, "Origins of the pages allowed to open the exec websocket, besides the server host (ex: https://dashboard.example.com)")
 	serveCmd.Flags().IntVar(&port, "port", 8080, "Use a specific port")
+	serveCmd.Flags().DurationVar(&reconcileInterval, "reconcile-interval", 10*time.Minute, "Interval between two reconciliations of the inventories and the namespaces. 0 disables the reconciler")
+	serveCmd.Flags().BoolVar(&reconcileFix, "reconcile-fix", false, "Fix the drift found by the reconciler instead of only reporting it")
+	serveCmd.Flags().DurationVar(&driftInterval, "drift-interval", 5*time.Minute, "Interval between two comparisons of the namespaces with their configs. 0 disables the drift detection")
+
+	serveCmd.Flags().StringVar(&playbookGit, "playbook-git", "", "Read the templates and the defaults of the playbook from this git repository instead of the working dir")
+	serveCmd.Flags().StringVar(&playbookRef, "playbook-ref", "HEAD", "Git reference (branch, tag or commit) of the playbook to use")
+	serveCmd.Flags().DurationVar(&playbookRefresh, "playbook-refresh", time.Minute, "Interval between two fetches of the playbook git repository. 0 disables the refresh")
+	serveCmd.Flags().StringVar(&playbookCache, "playbook-cache", "", "Directory where the playbook git repository is cloned (default \"<dir>/.playbook\")")
 
 	return serveCmd
 }
 
 func runServe() {
-	files := newFileClient(playbookDir)
+	var opts []files.Option
+
+	if playbookGit != "" {
+		cache := playbookCache
+		if cache == "" {
+			cache = filepath.Join(playbookDir, ".playbook")
+		}
+
+		playbooks, err := files.NewGitPlaybookRepository(playbookGit, playbookRef, cache)
+		if err != nil {
+			exit(err)
+		}
+
+		if playbookRefresh > 0 {
+			go files.RefreshPlaybook(context.Background(), playbooks, playbookRefresh)
+		}
+
+		opts = append(opts, files.WithPlaybookRepository(playbooks))
+	}
+
+	f := newFileClient(playbookDir, opts...)
+
+	blackbeard := newAPI(f, newKubernetesClient())
+
+	go func() {
+		if err := f.WatchPlaybook(context.Background()); err != nil {
+			logrus.Errorf("unable to watch the playbook, changes will not be reloaded : %v", err)
+		}
+	}()
+
+	go blackbeard.WatchNamespaceDeleted()
+
+	if reconcileInterval > 0 {
+		go api.RunReconciler(context.Background(), blackbeard, reconcileInterval, reconcileFix)
+	}
 
-	api := newAPI(files, newKubernetesClient())
+	if driftInterval > 0 {
+		go api.RunDriftDetector(context.Background(), blackbeard, f.ConfigPath(), driftInterval)
+	}
 
-	go api.WatchNamespaceDeleted()
+	var handlerOpts []http.HandlerOption
+	if enableExec {
+		handlerOpts = append(handlerOpts, http.WithExec(), http.WithExecAllowedOrigins(execOrigins...))
+	}
 
-	h := http.NewHandler(api, files.ConfigPath(), cors)
+	h := http.NewHandler(blackbeard, f.ConfigPath(), cors, handlerOpts...)
 	s := http.NewServer(h)
 
 	// start http web server
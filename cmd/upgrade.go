@@ -0,0 +1,140 @@
+package cmd
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"strconv"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/Meetic/blackbeard/pkg/api"
+	"github.com/Meetic/blackbeard/pkg/playbook"
+)
+
+var upgradeApply bool
+
+// upgradeCmd represents the upgrade command
+var upgradeCmd = &cobra.Command{
+	Use:   "upgrade",
+	Short: "Merge the changes of the default inventory into existing inventories.",
+	Long: `This command merges the changes made to the default inventory of the playbook since an inventory was generated
+into this inventory, without losing the values changed in the inventory :
+
+  - values added to the defaults, a new application for instance, are added to the inventory;
+  - values changed or removed in the defaults are changed or removed in the inventory, unless they were changed in the inventory;
+  - values changed both in the defaults and in the inventory are reported as conflicts, and keep their inventory value.
+
+Use --all or --selector to upgrade many inventories, and --apply to apply the upgraded inventories to their namespace :
+
+  blackbeard upgrade --all --apply
+  blackbeard upgrade --selector team=search --concurrency 8 --continue-on-error
+`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runUpgrade(namespace, bulkOpts, upgradeApply); err != nil {
+			exit(err)
+		}
+	},
+}
+
+func NewUpgradeCommand() *cobra.Command {
+	addCommonNamespaceCommandFlags(upgradeCmd)
+	addOutputFlag(upgradeCmd)
+	addBulkFlags(upgradeCmd)
+	upgradeCmd.Flags().BoolVar(&upgradeApply, "apply", false, "apply the upgraded inventories to their namespace")
+
+	return upgradeCmd
+}
+
+func runUpgrade(namespace string, opts api.BulkOptions, apply bool) error {
+	if isBulk(opts) {
+		return runBulkUpgrade(opts, apply)
+	}
+
+	if namespace == "" {
+		return errNamespaceRequired()
+	}
+
+	a, configPath := newCommandAPI()
+
+	report, err := a.Upgrade(namespace, apply, configPath)
+	if err != nil {
+		return err
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"namespace": namespace,
+		"changed":   report.Changed(),
+		"conflicts": len(report.Conflicts),
+		"applied":   report.Applied,
+	}).Info("inventory upgraded")
+
+	return printUpgradeReports(os.Stdout, output, []*playbook.UpgradeReport{report})
+}
+
+// runBulkUpgrade upgrades the inventories of the namespaces selected by opts and prints the reports of the
+// upgraded ones
+func runBulkUpgrade(opts api.BulkOptions, apply bool) error {
+	opts.Apply = apply
+
+	a, configPath := newCommandAPI()
+
+	bulk, err := runBulk(a, configPath, api.BulkUpgrade, opts)
+	if bulk == nil {
+		return err
+	}
+
+	reports := make([]*playbook.UpgradeReport, 0, len(bulk.Results))
+	for _, r := range bulk.Results {
+		if r.Upgrade != nil {
+			reports = append(reports, r.Upgrade)
+		}
+	}
+
+	if perr := printUpgradeReports(os.Stdout, output, reports); perr != nil {
+		return perr
+	}
+
+	return err
+}
+
+// printUpgradeReports prints a row for each value changed by the upgrades, and for each conflict.
+// The wide output format adds the inventory and default values of the conflicts.
+func printUpgradeReports(out io.Writer, format string, reports []*playbook.UpgradeReport) error {
+	tbl := newTable([]string{"Namespace", "Change", "Path", "Applied"}, "Current", "Default")
+
+	for _, r := range reports {
+		applied := strconv.FormatBool(r.Applied)
+
+		for _, change := range []struct {
+			name  string
+			paths []string
+		}{{"added", r.Added}, {"updated", r.Updated}, {"removed", r.Removed}} {
+			for _, path := range change.paths {
+				tbl.addRow([]string{r.Namespace, change.name, path, applied}, "", "")
+			}
+		}
+
+		for _, c := range r.Conflicts {
+			tbl.addRow([]string{r.Namespace, "conflicted", c.Path, applied}, formatValue(c.Current), formatValue(c.Default))
+		}
+	}
+
+	return printObject(out, format, reports, tbl)
+}
+
+// formatValue returns the json representation of an inventory value, or <none> if it is missing
+func formatValue(v interface{}) string {
+	if v == nil {
+		return "<none>"
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "<invalid>"
+	}
+
+	return string(b)
+}
@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Meetic/blackbeard/pkg/playbook"
+)
+
+func TestPrintUpgradeReports(t *testing.T) {
+	reports := []*playbook.UpgradeReport{
+		{
+			Namespace: "test",
+			MergeResult: playbook.MergeResult{
+				Added:     []string{"microservices[api-search]"},
+				Conflicts: []playbook.Conflict{{Path: "front.version", Base: "1.0", Current: "1.1", Default: "2.0"}},
+			},
+			Applied: true,
+		},
+	}
+
+	out := bytes.Buffer{}
+	assert.Nil(t, printUpgradeReports(&out, outputTable, reports))
+	lines := strings.Split(out.String(), "\n")
+	assert.Equal(t, []string{"Namespace", "Change", "Path", "Applied"}, strings.Fields(lines[0]))
+	assert.Equal(t, "test added microservices[api-search] true", strings.Join(strings.Fields(lines[1]), " "))
+	assert.Equal(t, "test conflicted front.version true", strings.Join(strings.Fields(lines[2]), " "))
+
+	out.Reset()
+	assert.Nil(t, printUpgradeReports(&out, outputWide, reports))
+	lines = strings.Split(out.String(), "\n")
+	assert.Equal(t, `test conflicted front.version true "1.1" "2.0"`, strings.Join(strings.Fields(lines[2]), " "))
+	assert.Equal(t, "test added microservices[api-search] true", strings.Join(strings.Fields(lines[1]), " "))
+}
@@ -0,0 +1,65 @@
+package api
+
+import (
+	"github.com/sirupsen/logrus"
+
+	"github.com/Meetic/blackbeard/pkg/errors"
+	"github.com/Meetic/blackbeard/pkg/playbook"
+)
+
+// Adopt brings an existing namespace under blackbeard management : the namespace is labelled manager=blackbeard
+// and an inventory is generated from the defaults of the playbook.
+// If inferVersions is true, the versions of the inventory are read from the image tags of the live deployments
+// (see playbook.InferVersions). Nothing is applied to the namespace.
+func (api *api) Adopt(namespace string, inferVersions bool) (playbook.Inventory, error) {
+	if api.inventories.Exists(namespace) {
+		return playbook.Inventory{}, errors.New(errors.AlreadyExists, "namespace %s is already managed by blackbeard", namespace)
+	}
+
+	var tags map[string]string
+
+	if inferVersions {
+		var err error
+		if tags, err = api.workloads.ImageTags(namespace); err != nil {
+			return playbook.Inventory{}, err
+		}
+	}
+
+	if err := api.namespaces.Adopt(namespace); err != nil {
+		return playbook.Inventory{}, err
+	}
+
+	inv, err := api.inventories.Create(namespace)
+	if err != nil {
+		return playbook.Inventory{}, err
+	}
+
+	if inferVersions {
+		updated := playbook.InferVersions(inv.Values, tags)
+
+		logrus.WithFields(logrus.Fields{"namespace": namespace, "applications": updated}).
+			Debug("versions inferred from deployments")
+
+		if err := api.inventories.Update(namespace, inv); err != nil {
+			return playbook.Inventory{}, err
+		}
+	}
+
+	if err := api.configs.Generate(inv); err != nil {
+		return playbook.Inventory{}, err
+	}
+
+	return inv, nil
+}
+
+// Detach stops managing a namespace : the manager=blackbeard label is removed, as well as the inventory and the
+// configs of the namespace. The namespace and its workloads are left running.
+func (api *api) Detach(namespace string) error {
+	if err := api.namespaces.Detach(namespace); err != nil {
+		return err
+	}
+
+	api.deletePlaybook(namespace)
+
+	return nil
+}
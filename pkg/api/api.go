@@ -1,11 +1,13 @@
 package api
 
 import (
+	"context"
 	"strings"
 	"time"
 
 	"github.com/sirupsen/logrus"
 
+	"github.com/Meetic/blackbeard/pkg/errors"
 	"github.com/Meetic/blackbeard/pkg/playbook"
 	"github.com/Meetic/blackbeard/pkg/resource"
 	"github.com/Meetic/blackbeard/pkg/version"
@@ -18,53 +20,93 @@ type Api interface {
 	Namespaces() resource.NamespaceService
 	Playbooks() playbook.PlaybookService
 	Pods() resource.PodService
-	Create(namespace string) (playbook.Inventory, error)
+	Create(namespace string, opts CreateOptions) (playbook.Inventory, error)
 	Delete(namespace string, wait bool) error
+	Adopt(namespace string, inferVersions bool) (playbook.Inventory, error)
+	Detach(namespace string) error
 	ListExposedServices(namespace string) ([]resource.Service, error)
-	ListNamespaces() ([]Namespace, error)
+	GetQuota(namespace string) ([]resource.QuotaUsage, error)
+	Check(ctx context.Context, namespace string) (*resource.CheckReport, error)
+	ListNamespaces(selector string) ([]Namespace, error)
+	ListInventories(selector string) ([]playbook.Inventory, error)
 	Reset(namespace string, configPath string) error
 	Apply(namespace string, configPath string) error
+	Upgrade(namespace string, apply bool, configPath string) (*playbook.UpgradeReport, error)
 	Update(namespace string, inventory playbook.Inventory, configPath string) error
-	WaitForNamespaceReady(namespace string, timeout time.Duration, bar progress) error
+	WaitForNamespaceReady(namespace string, timeout time.Duration, bar Progress) error
 	GetVersion() (*Version, error)
-	DeleteResource(namespace string, resource string) error
+	ListResources(namespace, kind string) ([]resource.Object, error)
+	GetResource(namespace, kind, name string) (*resource.Object, error)
+	DeleteResource(namespace, kind, name string) error
+	Restart(namespace, kind, name string) error
+	RestartAll(namespace string) ([]string, error)
+	Exec(ctx context.Context, namespace string, opts resource.ExecOptions) error
 	WatchNamespaceDeleted()
+	Reconcile(fix bool) (*ReconcileReport, error)
+	Drift(namespace string) (*resource.DriftReport, error)
+	StartBulk(operation string, opts BulkOptions, configPath string) (*BulkReport, error)
+	GetBulk(id string) (*BulkReport, error)
 }
 
 type api struct {
 	inventories playbook.InventoryService
 	configs     playbook.ConfigService
 	playbooks   playbook.PlaybookService
+	profiles    resource.ProfileRepository
 	namespaces  resource.NamespaceService
 	pods        resource.PodService
 	services    resource.ServiceService
+	checks      resource.CheckService
 	cluster     resource.ClusterService
-	job         resource.JobService
+	objects     resource.ObjectService
+	workloads   resource.WorkloadService
+	execs       resource.ExecService
+	drifts      resource.DriftService
+	bulks       *bulkOperations
+}
+
+// Repositories are the storages of the playbook and of the kubernetes objects used by the api.
+// Profiles are the namespace profiles of the playbook, giving the quota, limits and network policies of namespaces.
+// Drifts compares the live objects of namespaces with their rendered configs.
+// ReadinessRules are the readiness rules of the kinds taken into account in the namespace status, in addition to
+// deployments, statefulsets and jobs.
+type Repositories struct {
+	Inventories    playbook.InventoryRepository
+	Configs        playbook.ConfigRepository
+	Playbooks      playbook.PlaybookRepository
+	Profiles       resource.ProfileRepository
+	Namespaces     resource.NamespaceRepository
+	Pods           resource.PodRepository
+	Deployments    resource.DeploymentRepository
+	Statefulsets   resource.StatefulsetRepository
+	Services       resource.ServiceRepository
+	Cluster        resource.ClusterRepository
+	Jobs           resource.JobRepository
+	Objects        resource.ObjectRepository
+	Execs          resource.ExecRepository
+	Drifts         resource.DriftRepository
+	ReadinessRules []resource.ReadinessRule
 }
 
 // NewApi creates a blackbeard api. The blackbeard api is responsible for managing playbooks and namespaces.
-// Parameters are struct implementing respectively Inventory, Config, Namespace, Pod and Service interfaces.
-func NewApi(
-	inventories playbook.InventoryRepository,
-	configs playbook.ConfigRepository,
-	playbooks playbook.PlaybookRepository,
-	namespaces resource.NamespaceRepository,
-	pods resource.PodRepository,
-	deployments resource.DeploymentRepository,
-	statefulsets resource.StatefulsetRepository,
-	services resource.ServiceRepository,
-	cluster resource.ClusterRepository,
-	job resource.JobRepository,
-) Api {
+func NewApi(r Repositories) Api {
+	serviceService := resource.NewServiceService(r.Services)
+
 	api := &api{
-		inventories: playbook.NewInventoryService(inventories, playbook.NewPlaybookService(playbooks)),
-		configs:     playbook.NewConfigService(configs, playbook.NewPlaybookService(playbooks)),
-		playbooks:   playbook.NewPlaybookService(playbooks),
-		namespaces:  resource.NewNamespaceService(namespaces, pods, deployments, statefulsets, job),
-		pods:        resource.NewPodService(pods),
-		services:    resource.NewServiceService(services),
-		cluster:     resource.NewClusterService(cluster),
-		job:         resource.NewJobService(job),
+		inventories: playbook.NewInventoryService(r.Inventories, playbook.NewPlaybookService(r.Playbooks)),
+		configs:     playbook.NewConfigService(r.Configs, playbook.NewPlaybookService(r.Playbooks)),
+		playbooks:   playbook.NewPlaybookService(r.Playbooks),
+		profiles:    r.Profiles,
+		namespaces:  resource.NewNamespaceService(r.Namespaces, r.Pods, r.Deployments, r.Statefulsets, r.Jobs, r.ReadinessRules...),
+		pods:        resource.NewPodService(r.Pods),
+		services:    serviceService,
+		checks:      resource.NewCheckService(serviceService, nil),
+		cluster:     resource.NewClusterService(r.Cluster),
+		objects:     resource.NewObjectService(r.Objects, r.Namespaces),
+		workloads:   resource.NewWorkloadService(r.Deployments, r.Statefulsets),
+		execs:       resource.NewExecService(r.Execs, r.Namespaces),
+		drifts:      resource.NewDriftService(r.Drifts),
+		bulks:       newBulkOperations(),
 	}
 
 	return api
@@ -91,20 +133,83 @@ func (api *api) Pods() resource.PodService {
 
 // Create is responsible for creating an inventory, a set of kubernetes configs and a kubernetes namespace
 // for a given namespace.
+// The namespace is limited by the given profile. If profile is empty, the profile of the existing inventory is kept,
+// or the profile of the default inventory is used.
+// It is labelled and annotated with the metadata defined by the playbook, overridden by the given ones.
 // If an inventory already exist, Create will log the error and continue the process. Configs will be override.
-func (api *api) Create(namespace string) (playbook.Inventory, error) {
-	if err := api.namespaces.Create(namespace); err != nil {
+// The playbook ref and the values of an existing inventory are only replaced when a playbook ref is given.
+func (api *api) Create(namespace string, opts CreateOptions) (playbook.Inventory, error) {
+	current, err := api.inventories.Get(namespace)
+	if err != nil && !errors.Is(err, errors.NotFound) {
+		return playbook.Inventory{}, err
+	}
+
+	ref := opts.PlaybookRef
+	if ref == "" {
+		ref = current.PlaybookRef
+	}
+
+	playbooks, err := api.playbooks.At(ref)
+	if err != nil {
+		return playbook.Inventory{}, err
+	}
+
+	def, err := playbooks.GetDefault()
+	if err != nil {
+		return playbook.Inventory{}, err
+	}
+
+	profile := opts.Profile
+	if profile == "" {
+		profile = current.Profile
+	}
+	if profile == "" {
+		profile = def.Profile
+	}
+
+	p, err := api.profiles.Get(profile)
+	if err != nil {
+		return playbook.Inventory{}, err
+	}
+
+	metadata, err := api.profiles.Metadata()
+	if err != nil {
+		return playbook.Inventory{}, err
+	}
+
+	metadata = resource.NamespaceMetadata{
+		Labels:      merge(metadata.Labels, opts.Labels),
+		Annotations: merge(metadata.Annotations, opts.Annotations),
+	}
+
+	if err := api.namespaces.Create(namespace, metadata, p); err != nil {
 		return playbook.Inventory{}, err
 	}
 
 	inv, err := api.inventories.Create(namespace)
 	if err != nil {
-		switch e := err.(type) {
-		default:
-			return playbook.Inventory{}, e
-		case *playbook.ErrorInventoryAlreadyExist:
-			logrus.Warn(e.Error())
-			logrus.Info("Process continue")
+		if !errors.Is(err, errors.AlreadyExists) {
+			return playbook.Inventory{}, err
+		}
+
+		logrus.Warn(err.Error())
+		logrus.Info("Process continue")
+
+		if inv, err = api.inventories.Get(namespace); err != nil {
+			return playbook.Inventory{}, err
+		}
+	}
+
+	if opts.PlaybookRef != "" && opts.PlaybookRef != inv.PlaybookRef {
+		inv.PlaybookRef = opts.PlaybookRef
+		inv.Values = def.Values
+		inv.Base = playbook.CopyValues(def.Values)
+	}
+
+	if profile != inv.Profile || opts.PlaybookRef != "" {
+		inv.Profile = profile
+		if err := api.inventories.Update(namespace, inv); err != nil {
+			return playbook.Inventory{}, err
 		}
 	}
 
@@ -138,6 +243,16 @@ func (api *api) ListExposedServices(namespace string) ([]resource.Service, error
 	return api.services.ListExposed(namespace)
 }
 
+// GetQuota returns the usage of the resources limited by the quotas of a namespace
+func (api *api) GetQuota(namespace string) ([]resource.QuotaUsage, error) {
+	return api.namespaces.GetQuota(namespace)
+}
+
+// Check probes the services exposed by a namespace and runs their smoke checks
+func (api *api) Check(ctx context.Context, namespace string) (*resource.CheckReport, error) {
+	return api.checks.Check(ctx, namespace)
+}
+
 // Reset resets an inventory, the associated configs and the kubernetes namespaces to default values.
 // Defaults values are defines by the InventoryService GetDefault() method.
 func (api *api) Reset(namespace string, configPath string) error {
@@ -152,11 +267,17 @@ func (api *api) Reset(namespace string, configPath string) error {
 		return err
 	}
 
+	if err := api.applyProfile(inv); err != nil {
+		return err
+	}
+
 	//Apply changes to Kubernetes
 	if err = api.namespaces.ApplyConfig(namespace, configPath); err != nil {
 		return err
 	}
 
+	api.markApplied(inv)
+
 	return nil
 }
 
@@ -174,13 +295,76 @@ func (api *api) Apply(namespace string, configPath string) error {
 		return err
 	}
 
+	if err := api.applyProfile(inv); err != nil {
+		return err
+	}
+
 	if err := api.namespaces.ApplyConfig(inv.Namespace, configPath); err != nil {
 		return err
 	}
 
+	api.markApplied(inv)
+
 	return nil
 }
 
+// Upgrade merges the changes of the playbook defaults into the inventory of a namespace (see InventoryService.Upgrade).
+// If apply is true and the upgrade changed the inventory, the configs are generated and applied to the namespace.
+// The report is returned along with the error of the apply, since the upgraded inventory is saved anyway.
+func (api *api) Upgrade(namespace string, apply bool, configPath string) (*playbook.UpgradeReport, error) {
+	report, err := api.inventories.Upgrade(namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	if !apply || !report.Changed() {
+		return report, nil
+	}
+
+	if err := api.Apply(namespace, configPath); err != nil {
+		return report, err
+	}
+
+	report.Applied = true
+
+	return report, nil
+}
+
+// applyProfile updates the quota, limits and network policies of a namespace to match the profile of its inventory
+func (api *api) applyProfile(inv playbook.Inventory) error {
+	p, err := api.profiles.Get(inv.Profile)
+	if err != nil {
+		return err
+	}
+
+	return api.namespaces.ApplyProfile(inv.Namespace, p)
+}
+
+// playbookVersion returns the version of the playbook used to generate the configs of an inventory
+func (api *api) playbookVersion(inv playbook.Inventory) (playbook.Version, error) {
+	playbooks, err := api.playbooks.At(inv.PlaybookRef)
+	if err != nil {
+		return playbook.Version{}, err
+	}
+
+	return playbooks.Version()
+}
+
+// markApplied records the last time configs were applied to a namespace, and the version of the playbook used.
+// A failure is only logged since configs have already been applied.
+func (api *api) markApplied(inv playbook.Inventory) {
+	namespace := inv.Namespace
+	annotations := map[string]string{resource.AnnotationLastApplied: time.Now().UTC().Format(time.RFC3339)}
+
+	if v, err := api.playbookVersion(inv); err == nil {
+		annotations[resource.AnnotationPlaybook] = v.String()
+	}
+
+	if err := api.namespaces.Annotate(namespace, annotations); err != nil {
+		logrus.WithField("namespace", namespace).Warnf("unable to record last apply : %v", err)
+	}
+}
+
 // Update replace the inventory associated to the given namespace by the one set in parameters
 // and apply the changes to configs and kubernetes namespace (using the Apply method)
 func (api *api) Update(namespace string, inventory playbook.Inventory, configPath string) error {
@@ -195,16 +379,41 @@ func (api *api) Update(namespace string, inventory playbook.Inventory, configPat
 	return nil
 }
 
-// DeleteResource delete a resource from a namespace
-// Deletion of a Job only for now
-func (api *api) DeleteResource(namespace, resource string) error {
-	if err := api.job.Delete(namespace, resource); err != nil {
-		return err
-	}
+// ListResources returns the objects of the given kind in a namespace
+func (api *api) ListResources(namespace, kind string) ([]resource.Object, error) {
+	return api.objects.List(namespace, kind)
+}
 
-	return nil
+// GetResource returns an object of a namespace
+func (api *api) GetResource(namespace, kind, name string) (*resource.Object, error) {
+	return api.objects.Get(namespace, kind, name)
+}
+
+// DeleteResource deletes an object from a namespace managed by blackbeard.
+// Applying the inventory again will recreate it if it is part of the playbook.
+func (api *api) DeleteResource(namespace, kind, name string) error {
+	return api.objects.Delete(namespace, kind, name)
+}
+
+// Restart recreates the pods of a deployment or a statefulset
+func (api *api) Restart(namespace, kind, name string) error {
+	return api.workloads.Restart(namespace, kind, name)
 }
 
+// RestartAll restarts every deployment and statefulset of a namespace and returns them as kind/name
+func (api *api) RestartAll(namespace string) ([]string, error) {
+	return api.workloads.RestartAll(namespace)
+}
+
+// Exec runs a command in a pod of a namespace managed by blackbeard
+func (api *api) Exec(ctx context.Context, namespace string, opts resource.ExecOptions) error {
+	return api.execs.Exec(ctx, namespace, opts)
+}
+
+// WatchNamespaceDeleted deletes the inventory and the configs of the namespaces once they are deleted.
+// When the namespaces are listed again, for instance once the server restarted, the inventories of the namespaces
+// deleted meanwhile are only reported, since a namespace may be missing for a while. The reconciler deletes them
+// when it fixes the drift.
 func (api *api) WatchNamespaceDeleted() {
 	events := make(chan resource.NamespaceEvent, 0)
 
@@ -212,13 +421,35 @@ func (api *api) WatchNamespaceDeleted() {
 
 	// handle delete of inventories and configs files
 	for event := range events {
-		if event.Type == "DELETED" {
+		switch event.Type {
+		case "DELETED":
 			api.deletePlaybook(event.Namespace)
 
 			logrus.
 				WithFields(logrus.Fields{"component": "watcher", "event": "delete", "namespace": event.Namespace}).
 				Debug("Playbook deleted")
+		case resource.NamespaceSynced:
+			api.reportOrphanPlaybooks()
+		}
+	}
+}
+
+// reportOrphanPlaybooks logs the inventories whose namespace does not exist anymore
+func (api *api) reportOrphanPlaybooks() {
+	invs, err := api.inventories.List()
+	if err != nil {
+		logrus.WithField("component", "watcher").Errorf("unable to list the inventories : %v", err)
+		return
+	}
+
+	for _, inv := range invs {
+		if _, err := api.namespaces.Get(inv.Namespace); !errors.Is(err, errors.NotFound) {
+			continue
 		}
+
+		logrus.
+			WithFields(logrus.Fields{"component": "watcher", "event": "sync", "namespace": inv.Namespace}).
+			Warn("the namespace of the inventory does not exist, the reconciler deletes it when --reconcile-fix is set")
 	}
 }
 
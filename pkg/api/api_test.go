@@ -7,59 +7,100 @@ import (
 	"k8s.io/client-go/kubernetes/fake"
 
 	"github.com/Meetic/blackbeard/pkg/api"
-	"github.com/Meetic/blackbeard/pkg/kubernetes"
+	"github.com/Meetic/blackbeard/pkg/errors"
+	"github.com/Meetic/blackbeard/pkg/files"
 	"github.com/Meetic/blackbeard/pkg/mock"
-	"github.com/Meetic/blackbeard/pkg/resource"
 )
 
 var (
 	kube = fake.NewSimpleClientset()
 
-	blackbeard = api.NewApi(
-		mock.NewInventoryRepository(),
-		mock.NewConfigRepository(),
-		mock.NewPlaybookRepository(),
-		mock.NewNamespaceRepository(kube, false),
-		kubernetes.NewPodRepository(kube),
-		kubernetes.NewDeploymentRepository(kube),
-		kubernetes.NewStatefulsetRepository(kube),
-		kubernetes.NewServiceRepository(kube, "kube.test"),
-		kubernetes.NewClusterRepository(),
-		kubernetes.NewJobRepository(kube),
-	)
+	blackbeard = api.NewApi(mock.NewRepositories(kube))
 )
 
-type clusterRepositoryMock struct{}
-
-func (clusterRepositoryMock) GetVersion() (*resource.Version, error) {
-	return &resource.Version{
-		ServerVersion: struct {
-			Major string `json:"major"`
-			Minor string `json:"minor"`
-		}{Major: "1", Minor: "2"},
-		ClientVersion: struct {
-			Major string `json:"major"`
-			Minor string `json:"minor"`
-		}{Major: "0", Minor: "9"},
-	}, nil
+// newInventoryApi returns an api storing the inventories in files, so that the changes made to them are kept
+func newInventoryApi(t *testing.T) api.Api {
+	repositories := mock.NewRepositories(fake.NewSimpleClientset())
+	repositories.Inventories = files.NewInventoryRepository(t.TempDir())
+
+	return api.NewApi(repositories)
 }
 
 func TestGetVersion(t *testing.T) {
-	blackbeard = api.NewApi(
-		mock.NewInventoryRepository(),
-		mock.NewConfigRepository(),
-		mock.NewPlaybookRepository(),
-		mock.NewNamespaceRepository(kube, false),
-		kubernetes.NewPodRepository(kube),
-		kubernetes.NewDeploymentRepository(kube),
-		kubernetes.NewStatefulsetRepository(kube),
-		kubernetes.NewServiceRepository(kube, "kube.test"),
-		new(clusterRepositoryMock),
-		kubernetes.NewJobRepository(kube),
-	)
-
 	version, err := blackbeard.GetVersion()
 
 	assert.Nil(t, err)
 	assert.Equal(t, version, &api.Version{Blackbeard: "dev", Kubernetes: "1.2", Kubectl: "0.9"})
 }
+
+func TestCreateWithProfile(t *testing.T) {
+	inv, err := blackbeard.Create("test", api.CreateOptions{Profile: "large"})
+	assert.Nil(t, err)
+	assert.Equal(t, "large", inv.Profile)
+
+	inv, err = blackbeard.Create("test", api.CreateOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, "", inv.Profile)
+
+	_, err = blackbeard.Create("test", api.CreateOptions{Profile: "huge"})
+	assert.True(t, errors.Is(err, errors.Invalid))
+}
+
+func TestCreateKeepsProfile(t *testing.T) {
+	blackbeard := newInventoryApi(t)
+
+	_, err := blackbeard.Create("test", api.CreateOptions{Profile: "large"})
+	assert.Nil(t, err)
+
+	inv, err := blackbeard.Create("test", api.CreateOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, "large", inv.Profile)
+
+	inv, err = blackbeard.Create("test", api.CreateOptions{Profile: "small"})
+	assert.Nil(t, err)
+	assert.Equal(t, "small", inv.Profile)
+}
+
+func TestResetKeepsProfileAndSelfHeal(t *testing.T) {
+	blackbeard := newInventoryApi(t)
+
+	inv, err := blackbeard.Create("test", api.CreateOptions{Profile: "large"})
+	assert.Nil(t, err)
+
+	inv.SelfHeal = true
+	assert.Nil(t, blackbeard.Inventories().Update("test", inv))
+
+	assert.Nil(t, blackbeard.Reset("test", "configs"))
+
+	inv, err = blackbeard.Inventories().Get("test")
+	assert.Nil(t, err)
+	assert.Equal(t, "large", inv.Profile)
+	assert.True(t, inv.SelfHeal)
+}
+
+func TestCreateWithPlaybookRef(t *testing.T) {
+	inv, err := blackbeard.Create("test", api.CreateOptions{PlaybookRef: "v2"})
+	assert.Nil(t, err)
+	assert.Equal(t, "v2", inv.PlaybookRef)
+	assert.NotEmpty(t, inv.Values)
+}
+
+func TestCreateKeepsPinnedInventory(t *testing.T) {
+	blackbeard := newInventoryApi(t)
+
+	inv, err := blackbeard.Create("test", api.CreateOptions{PlaybookRef: "v2"})
+	assert.Nil(t, err)
+
+	inv.Values = map[string]interface{}{"custom": true}
+	assert.Nil(t, blackbeard.Inventories().Update("test", inv))
+
+	inv, err = blackbeard.Create("test", api.CreateOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, "v2", inv.PlaybookRef)
+	assert.Equal(t, map[string]interface{}{"custom": true}, inv.Values)
+
+	inv, err = blackbeard.Create("test", api.CreateOptions{PlaybookRef: "v3"})
+	assert.Nil(t, err)
+	assert.Equal(t, "v3", inv.PlaybookRef)
+	assert.NotContains(t, inv.Values, "custom")
+}
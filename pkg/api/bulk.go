@@ -0,0 +1,356 @@
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/Meetic/blackbeard/pkg/errors"
+	"github.com/Meetic/blackbeard/pkg/playbook"
+)
+
+// Operations which may be run on many namespaces at once
+const (
+	BulkApply   = "apply"
+	BulkReset   = "reset"
+	BulkUpgrade = "upgrade"
+	BulkDelete  = "delete"
+)
+
+// Status of a bulk operation, and of the operation on each of its namespaces
+const (
+	BulkPending   = "pending"
+	BulkRunning   = "running"
+	BulkSucceeded = "succeeded"
+	BulkFailed    = "failed"
+	// BulkSkipped is the status of the namespaces left untouched because a namespace failed and the operation
+	// does not continue on error.
+	BulkSkipped = "skipped"
+)
+
+const (
+	// DefaultBulkConcurrency is the number of namespaces processed at the same time by default
+	DefaultBulkConcurrency = 4
+	// bulkRetention is the time the reports of the finished bulk operations are kept
+	bulkRetention = time.Hour
+)
+
+// BulkOptions selects the namespaces of a bulk operation, using a label selector or All, and tells how to run it.
+// Concurrency is the number of namespaces processed at the same time, DefaultBulkConcurrency if not set.
+// If ContinueOnError is false, the namespaces not started yet are skipped once a namespace failed.
+// Apply applies the upgraded inventories of an upgrade operation.
+type BulkOptions struct {
+	Selector        string `json:"selector,omitempty"`
+	All             bool   `json:"all,omitempty"`
+	Concurrency     int    `json:"concurrency,omitempty"`
+	ContinueOnError bool   `json:"continueOnError,omitempty"`
+	Apply           bool   `json:"apply,omitempty"`
+}
+
+// BulkResult is the result of a bulk operation on a namespace.
+// Upgrade is the report of the upgrade of the inventory of the namespace, for upgrade operations.
+type BulkResult struct {
+	Namespace string                  `json:"namespace"`
+	Status    string                  `json:"status"`
+	Error     string                  `json:"error,omitempty"`
+	Upgrade   *playbook.UpgradeReport `json:"upgrade,omitempty"`
+}
+
+// BulkReport is the state of a bulk operation : its status, running until every namespace has been processed,
+// and the result of each namespace.
+type BulkReport struct {
+	ID         string       `json:"id"`
+	Operation  string       `json:"operation"`
+	Selector   string       `json:"selector,omitempty"`
+	Status     string       `json:"status"`
+	StartedAt  time.Time    `json:"startedAt"`
+	FinishedAt *time.Time   `json:"finishedAt,omitempty"`
+	Results    []BulkResult `json:"results"`
+}
+
+// Done returns true if every namespace of the operation has been processed
+func (r BulkReport) Done() bool {
+	return r.Status != BulkRunning
+}
+
+// Count returns the number of namespaces having the given status
+func (r BulkReport) Count(status string) int {
+	var n int
+	for _, res := range r.Results {
+		if res.Status == status {
+			n++
+		}
+	}
+
+	return n
+}
+
+// bulkOperations keeps the running bulk operations, and the finished ones during bulkRetention
+type bulkOperations struct {
+	mu         sync.Mutex
+	operations map[string]*bulkOperation
+}
+
+// bulkFunc runs a bulk operation on a namespace, completing its result
+type bulkFunc func(namespace string, result *BulkResult) error
+
+type bulkOperation struct {
+	mu     sync.Mutex
+	report BulkReport
+}
+
+// StartBulk runs an operation on the inventories of the namespaces matching opts in the background and returns
+// the report of the operation, whose ID gives its progress (see GetBulk).
+// The operation is one of apply, reset, upgrade and delete.
+func (api *api) StartBulk(operation string, opts BulkOptions, configPath string) (*BulkReport, error) {
+	do, err := api.newBulkFunc(operation, opts, configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.Selector == "" && !opts.All {
+		return nil, errors.New(errors.Invalid, "a bulk operation requires a namespace selector, or all the namespaces")
+	}
+	if opts.Selector != "" && opts.All {
+		return nil, errors.New(errors.Invalid, "a bulk operation requires either a namespace selector or all the namespaces, not both")
+	}
+	if opts.Concurrency < 0 {
+		return nil, errors.New(errors.Invalid, "the concurrency of a bulk operation must be positive")
+	}
+
+	invs, err := api.ListInventories(opts.Selector)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := newBulkID()
+	if err != nil {
+		return nil, err
+	}
+
+	op := &bulkOperation{report: BulkReport{
+		ID:        id,
+		Operation: operation,
+		Selector:  opts.Selector,
+		Status:    BulkRunning,
+		StartedAt: time.Now().UTC(),
+		Results:   make([]BulkResult, 0, len(invs)),
+	}}
+
+	for _, inv := range invs {
+		op.report.Results = append(op.report.Results, BulkResult{Namespace: inv.Namespace, Status: BulkPending})
+	}
+
+	api.bulks.add(op)
+
+	go op.run(do, opts)
+
+	return op.snapshot(), nil
+}
+
+// GetBulk returns the report of a bulk operation
+func (api *api) GetBulk(id string) (*BulkReport, error) {
+	return api.bulks.get(id)
+}
+
+// newBulkFunc returns the function running an operation on a namespace
+func (api *api) newBulkFunc(operation string, opts BulkOptions, configPath string) (bulkFunc, error) {
+	switch operation {
+	case BulkApply:
+		return func(namespace string, _ *BulkResult) error {
+			return api.Apply(namespace, configPath)
+		}, nil
+	case BulkReset:
+		return func(namespace string, _ *BulkResult) error {
+			return api.Reset(namespace, configPath)
+		}, nil
+	case BulkUpgrade:
+		return func(namespace string, result *BulkResult) error {
+			report, err := api.Upgrade(namespace, opts.Apply, configPath)
+			result.Upgrade = report
+			return err
+		}, nil
+	case BulkDelete:
+		return func(namespace string, _ *BulkResult) error {
+			return api.Delete(namespace, false)
+		}, nil
+	}
+
+	return nil, errors.New(errors.Invalid, "unknown bulk operation %q, must be one of %s, %s, %s or %s",
+		operation, BulkApply, BulkReset, BulkUpgrade, BulkDelete)
+}
+
+// WaitForBulk polls the report of a bulk operation every interval until every namespace has been processed,
+// or until ctx is done.
+func WaitForBulk(ctx context.Context, api Api, id string, interval time.Duration) (*BulkReport, error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		report, err := api.GetBulk(id)
+		if err != nil {
+			return nil, err
+		}
+
+		if report.Done() {
+			return report, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return report, errors.Wrap(ctx.Err(), errors.Timeout, "bulk operation %s is still running", id)
+		case <-ticker.C:
+		}
+	}
+}
+
+func newBulkOperations() *bulkOperations {
+	return &bulkOperations{operations: make(map[string]*bulkOperation)}
+}
+
+// add stores a new operation, forgetting the operations finished for more than bulkRetention
+func (b *bulkOperations) add(op *bulkOperation) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for id, o := range b.operations {
+		if r := o.snapshot(); r.FinishedAt != nil && time.Since(*r.FinishedAt) > bulkRetention {
+			delete(b.operations, id)
+		}
+	}
+
+	b.operations[op.report.ID] = op
+}
+
+func (b *bulkOperations) get(id string) (*BulkReport, error) {
+	b.mu.Lock()
+	op, ok := b.operations[id]
+	b.mu.Unlock()
+
+	if !ok {
+		return nil, errors.New(errors.NotFound, "the bulk operation %s does not exist", id)
+	}
+
+	return op.snapshot(), nil
+}
+
+// run processes the namespaces of the operation, at most opts.Concurrency at the same time
+func (op *bulkOperation) run(do bulkFunc, opts BulkOptions) {
+	concurrency := opts.Concurrency
+	if concurrency == 0 {
+		concurrency = DefaultBulkConcurrency
+	}
+
+	logger := logrus.WithFields(logrus.Fields{"component": "bulk", "id": op.report.ID, "operation": op.report.Operation})
+
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+
+	for i, ns := range op.namespaces() {
+		sem <- struct{}{}
+
+		if !opts.ContinueOnError && op.failed() {
+			<-sem
+			op.set(i, BulkResult{Namespace: ns, Status: BulkSkipped})
+			continue
+		}
+
+		op.set(i, BulkResult{Namespace: ns, Status: BulkRunning})
+
+		wg.Add(1)
+		go func(i int, ns string) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			result := BulkResult{Namespace: ns, Status: BulkSucceeded}
+
+			if err := do(ns, &result); err != nil {
+				logger.WithField("namespace", ns).Errorf("bulk operation failed : %v", err)
+				result.Status, result.Error = BulkFailed, err.Error()
+			} else {
+				logger.WithField("namespace", ns).Info("bulk operation succeeded")
+			}
+
+			op.set(i, result)
+		}(i, ns)
+	}
+
+	wg.Wait()
+
+	op.finish()
+
+	r := op.snapshot()
+	logger.WithFields(logrus.Fields{
+		"succeeded": r.Count(BulkSucceeded),
+		"failed":    r.Count(BulkFailed),
+		"skipped":   r.Count(BulkSkipped),
+	}).Info("bulk operation finished")
+}
+
+func (op *bulkOperation) namespaces() []string {
+	op.mu.Lock()
+	defer op.mu.Unlock()
+
+	namespaces := make([]string, 0, len(op.report.Results))
+	for _, r := range op.report.Results {
+		namespaces = append(namespaces, r.Namespace)
+	}
+
+	return namespaces
+}
+
+func (op *bulkOperation) set(i int, result BulkResult) {
+	op.mu.Lock()
+	defer op.mu.Unlock()
+
+	op.report.Results[i] = result
+}
+
+func (op *bulkOperation) failed() bool {
+	op.mu.Lock()
+	defer op.mu.Unlock()
+
+	return op.report.Count(BulkFailed) > 0
+}
+
+func (op *bulkOperation) finish() {
+	op.mu.Lock()
+	defer op.mu.Unlock()
+
+	now := time.Now().UTC()
+	op.report.FinishedAt = &now
+
+	op.report.Status = BulkSucceeded
+	if op.report.Count(BulkFailed) > 0 {
+		op.report.Status = BulkFailed
+	}
+}
+
+// snapshot returns a copy of the report, which is not modified by the running operation
+func (op *bulkOperation) snapshot() *BulkReport {
+	op.mu.Lock()
+	defer op.mu.Unlock()
+
+	r := op.report
+	r.Results = append([]BulkResult(nil), op.report.Results...)
+	if r.Results == nil {
+		r.Results = make([]BulkResult, 0)
+	}
+
+	return &r
+}
+
+func newBulkID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", errors.Wrap(err, errors.Internal, "unable to generate a bulk operation id")
+	}
+
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,133 @@
+package api_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/Meetic/blackbeard/pkg/api"
+	"github.com/Meetic/blackbeard/pkg/errors"
+	"github.com/Meetic/blackbeard/pkg/files"
+	"github.com/Meetic/blackbeard/pkg/kubernetes"
+	"github.com/Meetic/blackbeard/pkg/mock"
+	"github.com/Meetic/blackbeard/pkg/playbook"
+	"github.com/Meetic/blackbeard/pkg/resource"
+)
+
+// bulkNamespaces lists the namespaces of a kube fake, so that selectors match their labels,
+// and mocks the other operations since applying configs runs kubectl.
+type bulkNamespaces struct {
+	resource.NamespaceRepository
+	kube resource.NamespaceRepository
+}
+
+func (ns bulkNamespaces) List(selector string) ([]resource.Namespace, error) {
+	return ns.kube.List(selector)
+}
+
+// newBulkApi returns an api managing the namespaces a, b and c. The profile of b is unknown, so applying it fails.
+// a and c are labelled team=search, b is labelled team=front.
+func newBulkApi(t *testing.T) api.Api {
+	inventories := files.NewInventoryRepository(t.TempDir())
+	for _, inv := range []playbook.Inventory{{Namespace: "a"}, {Namespace: "b", Profile: "unknown"}, {Namespace: "c"}} {
+		assert.Nil(t, inventories.Create(inv))
+	}
+
+	kube := fake.NewSimpleClientset()
+	for name, team := range map[string]string{"a": "search", "b": "front", "c": "search"} {
+		ns := newNamespace(name, true)
+		ns.Labels["team"] = team
+		assert.Nil(t, kube.Tracker().Add(ns))
+	}
+
+	repositories := mock.NewRepositories(kube)
+	repositories.Inventories = inventories
+	repositories.Namespaces = bulkNamespaces{
+		NamespaceRepository: repositories.Namespaces,
+		kube:                kubernetes.NewNamespaceRepository(kube),
+	}
+
+	return api.NewApi(repositories)
+}
+
+func runBulk(t *testing.T, blackbeard api.Api, operation string, opts api.BulkOptions) *api.BulkReport {
+	report, err := blackbeard.StartBulk(operation, opts, "")
+	assert.Nil(t, err)
+
+	report, err = api.WaitForBulk(context.Background(), blackbeard, report.ID, 10*time.Millisecond)
+	assert.Nil(t, err)
+
+	return report
+}
+
+func TestBulkStopsOnError(t *testing.T) {
+	report := runBulk(t, newBulkApi(t), api.BulkApply, api.BulkOptions{All: true, Concurrency: 1})
+
+	assert.Equal(t, api.BulkApply, report.Operation)
+	assert.Equal(t, api.BulkFailed, report.Status)
+	assert.NotNil(t, report.FinishedAt)
+	assert.Equal(t, []api.BulkResult{
+		{Namespace: "a", Status: api.BulkSucceeded},
+		{Namespace: "b", Status: api.BulkFailed, Error: "unknown profile unknown, available profiles : large, small"},
+		{Namespace: "c", Status: api.BulkSkipped},
+	}, report.Results)
+}
+
+func TestBulkContinueOnError(t *testing.T) {
+	report := runBulk(t, newBulkApi(t), api.BulkApply, api.BulkOptions{All: true, ContinueOnError: true})
+
+	assert.Equal(t, api.BulkFailed, report.Status)
+	assert.Equal(t, 2, report.Count(api.BulkSucceeded))
+	assert.Equal(t, 1, report.Count(api.BulkFailed))
+	assert.Equal(t, 0, report.Count(api.BulkSkipped))
+}
+
+func TestBulkSelector(t *testing.T) {
+	report := runBulk(t, newBulkApi(t), api.BulkUpgrade, api.BulkOptions{Selector: "team=search"})
+
+	assert.Equal(t, api.BulkSucceeded, report.Status)
+	assert.Len(t, report.Results, 2)
+	for i, namespace := range []string{"a", "c"} {
+		assert.Equal(t, namespace, report.Results[i].Namespace)
+		assert.Equal(t, api.BulkSucceeded, report.Results[i].Status)
+		assert.NotNil(t, report.Results[i].Upgrade)
+	}
+}
+
+func TestBulkUpgradeApplyFailure(t *testing.T) {
+	report := runBulk(t, newBulkApi(t), api.BulkUpgrade, api.BulkOptions{Selector: "team=front", Apply: true})
+
+	assert.Equal(t, api.BulkFailed, report.Status)
+	assert.Len(t, report.Results, 1)
+	assert.Equal(t, "unknown profile unknown, available profiles : large, small", report.Results[0].Error)
+
+	// the upgraded inventory is saved even though applying it failed
+	upgrade := report.Results[0].Upgrade
+	if assert.NotNil(t, upgrade) {
+		assert.True(t, upgrade.Changed())
+		assert.False(t, upgrade.Applied)
+	}
+}
+
+func TestBulkInvalid(t *testing.T) {
+	blackbeard := newBulkApi(t)
+
+	for _, test := range []struct {
+		operation string
+		opts      api.BulkOptions
+	}{
+		{"restart", api.BulkOptions{All: true}},
+		{api.BulkApply, api.BulkOptions{}},
+		{api.BulkApply, api.BulkOptions{All: true, Selector: "team=search"}},
+		{api.BulkApply, api.BulkOptions{All: true, Concurrency: -1}},
+	} {
+		_, err := blackbeard.StartBulk(test.operation, test.opts, "")
+		assert.True(t, errors.Is(err, errors.Invalid), "%s %+v", test.operation, test.opts)
+	}
+
+	_, err := blackbeard.GetBulk("unknown")
+	assert.True(t, errors.Is(err, errors.NotFound))
+}
@@ -0,0 +1,105 @@
+package api
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/Meetic/blackbeard/pkg/resource"
+)
+
+// Drift compares the live objects of a namespace with the configs rendered by the last apply.
+// Only the fields set in the configs are compared. A namespace never applied has nothing to drift from,
+// so an empty report is returned for it.
+func (api *api) Drift(namespace string) (*resource.DriftReport, error) {
+	if _, err := api.inventories.Get(namespace); err != nil {
+		return nil, err
+	}
+
+	ns, err := api.namespaces.Get(namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	if ns.Annotations[resource.AnnotationLastApplied] == "" {
+		return &resource.DriftReport{
+			Namespace: namespace,
+			Objects:   make([]resource.ObjectDrift, 0),
+			CheckedAt: time.Now().UTC(),
+		}, nil
+	}
+
+	configs, err := api.configs.Get(namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	manifests := make([]string, 0, len(configs))
+	for _, c := range configs {
+		manifests = append(manifests, c.Values)
+	}
+
+	return api.drifts.Drift(namespace, manifests)
+}
+
+// RunDriftDetector looks for drifted namespaces every interval, until ctx is done.
+// Drifted namespaces are logged. The ones whose inventory opted into self healing are applied again.
+func RunDriftDetector(ctx context.Context, api Api, configPath string, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		DetectDrifts(api, configPath)
+	}
+}
+
+// DetectDrifts checks every managed namespace for drift and applies again the drifted ones having self healing enabled.
+// It returns the reports of the drifted namespaces.
+func DetectDrifts(api Api, configPath string) []*resource.DriftReport {
+	logger := logrus.WithFields(logrus.Fields{"component": "drift"})
+
+	invs, err := api.ListInventories("")
+	if err != nil {
+		logger.Errorf("unable to list inventories : %v", err)
+		return nil
+	}
+
+	var drifted []*resource.DriftReport
+
+	for _, inv := range invs {
+		report, err := api.Drift(inv.Namespace)
+		if err != nil {
+			logger.WithField("namespace", inv.Namespace).Warnf("unable to detect drift : %v", err)
+			continue
+		}
+
+		if !report.Drifted {
+			continue
+		}
+
+		drifted = append(drifted, report)
+
+		logger.WithFields(logrus.Fields{
+			"namespace": inv.Namespace,
+			"objects":   len(report.Objects),
+			"selfHeal":  inv.SelfHeal,
+		}).Warn("namespace drifted from its configs")
+
+		if !inv.SelfHeal {
+			continue
+		}
+
+		if err := api.Apply(inv.Namespace, configPath); err != nil {
+			logger.WithField("namespace", inv.Namespace).Errorf("unable to apply drifted namespace : %v", err)
+		}
+	}
+
+	return drifted
+}
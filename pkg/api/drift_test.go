@@ -0,0 +1,71 @@
+package api_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/Meetic/blackbeard/pkg/api"
+	"github.com/Meetic/blackbeard/pkg/playbook"
+	"github.com/Meetic/blackbeard/pkg/resource"
+)
+
+// driftApi reports every namespace but "synced" as drifted and records the applied namespaces
+type driftApi struct {
+	api.Api
+	applied []string
+}
+
+func (d *driftApi) ListInventories(selector string) ([]playbook.Inventory, error) {
+	return []playbook.Inventory{
+		{Namespace: "synced", SelfHeal: true},
+		{Namespace: "edited"},
+		{Namespace: "scaled", SelfHeal: true},
+	}, nil
+}
+
+func (d *driftApi) Drift(namespace string) (*resource.DriftReport, error) {
+	report := &resource.DriftReport{Namespace: namespace}
+	if namespace != "synced" {
+		report.Drifted = true
+		report.Objects = []resource.ObjectDrift{{Kind: "Deployment", Name: "api", Fields: []resource.FieldDrift{{Path: "spec.replicas"}}}}
+	}
+
+	return report, nil
+}
+
+func (d *driftApi) Apply(namespace string, configPath string) error {
+	d.applied = append(d.applied, namespace)
+	return nil
+}
+
+func TestDetectDrifts(t *testing.T) {
+	d := &driftApi{}
+
+	reports := api.DetectDrifts(d, "configs")
+
+	assert.Len(t, reports, 2)
+	assert.Equal(t, "edited", reports[0].Namespace)
+	assert.Equal(t, "scaled", reports[1].Namespace)
+	assert.Equal(t, []string{"scaled"}, d.applied)
+}
+
+func TestDrift(t *testing.T) {
+	report, err := blackbeard.Drift("test")
+
+	assert.Nil(t, err)
+	assert.False(t, report.Drifted)
+	assert.Equal(t, 1, report.Checked)
+}
+
+func TestDriftNeverApplied(t *testing.T) {
+	blackbeard := newMetadataApi(fake.NewSimpleClientset(newNamespace("test", true)))
+
+	report, err := blackbeard.Drift("test")
+
+	assert.Nil(t, err)
+	assert.False(t, report.Drifted)
+	assert.Equal(t, 0, report.Checked)
+	assert.Empty(t, report.Objects)
+}
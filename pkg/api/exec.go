@@ -0,0 +1,26 @@
+package api
+
+import "github.com/Meetic/blackbeard/pkg/errors"
+
+// Types of the messages exchanged on an exec websocket
+const (
+	ExecStdin  = "stdin"
+	ExecEOF    = "eof"
+	ExecResize = "resize"
+	ExecStdout = "stdout"
+	ExecStderr = "stderr"
+	ExecExit   = "exit"
+	ExecError  = "error"
+)
+
+// ExecMessage is a json message exchanged on an exec websocket.
+// Clients send stdin, eof and resize messages. The server sends stdout and stderr messages,
+// then a final exit message containing the exit code of the command, or an error message.
+type ExecMessage struct {
+	Type   string          `json:"type"`
+	Data   string          `json:"data,omitempty"`
+	Width  uint16          `json:"width,omitempty"`
+	Height uint16          `json:"height,omitempty"`
+	Code   int             `json:"code,omitempty"`
+	Error  *errors.Problem `json:"error,omitempty"`
+}
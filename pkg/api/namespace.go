@@ -1,9 +1,10 @@
 package api
 
 import (
-	"fmt"
 	"time"
 
+	"github.com/Meetic/blackbeard/pkg/errors"
+	"github.com/Meetic/blackbeard/pkg/playbook"
 	"github.com/Meetic/blackbeard/pkg/resource"
 )
 
@@ -14,19 +15,43 @@ const (
 // Namespace represents a kubernetes namespace enrich with informations from the playbook.
 type Namespace struct {
 	//Name is the namespace name
-	Name string
+	Name string `json:"name"`
 	//Phase is the namespace status phase. It could be "active" or "terminating"
-	Phase string
+	Phase string `json:"phase"`
 	//Status is the namespace status. It is a percentage of runnning pods vs all pods in the namespace.
-	Status int
+	Status int `json:"status"`
 	//Managed is true if the namespace as an associated inventory on the current playbook. False if not.
-	Managed bool
+	Managed bool `json:"managed"`
+	//TTL is the time to live of the namespace, read from the blackbeard.io/ttl annotation.
+	TTL string `json:"ttl,omitempty"`
+	//Owner is the namespace owner, read from the blackbeard.io/owner annotation.
+	Owner string `json:"owner,omitempty"`
+	//Playbook is the playbook used to configure the namespace, read from the blackbeard.io/playbook annotation.
+	Playbook string `json:"playbook,omitempty"`
+	//LastApplied is the last time configs were applied, read from the blackbeard.io/last-applied annotation.
+	LastApplied string `json:"lastApplied,omitempty"`
+	//Labels are the labels of the namespace.
+	Labels map[string]string `json:"labels,omitempty"`
+	//Annotations are the annotations of the namespace.
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// CreateOptions are the options of a namespace creation.
+// Profile is the size profile of the namespace. The default profile of the playbook is used if it is empty.
+// Labels and Annotations are set on the namespace, in addition to the ones defined by the playbook.
+// PlaybookRef pins the git reference of the playbook used by the namespace.
+type CreateOptions struct {
+	Profile     string            `json:"profile,omitempty"`
+	PlaybookRef string            `json:"playbookRef,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
 }
 
 // ListNamespaces returns a list of Namespace.
 // For each kubernetes namespace, it checks if an associated inventory exists.
-func (api *api) ListNamespaces() ([]Namespace, error) {
-	nsList, err := api.namespaces.List()
+// Only the namespaces matching the given label selector are returned, if it is not empty.
+func (api *api) ListNamespaces(selector string) ([]Namespace, error) {
+	nsList, err := api.namespaces.List(selector)
 	if err != nil {
 		return nil, err
 	}
@@ -40,6 +65,14 @@ func (api *api) ListNamespaces() ([]Namespace, error) {
 			Phase:   ns.Phase,
 			Status:  ns.Status,
 			Managed: false,
+
+			TTL:         ns.Annotations[resource.AnnotationTTL],
+			Owner:       ns.Annotations[resource.AnnotationOwner],
+			Playbook:    ns.Annotations[resource.AnnotationPlaybook],
+			LastApplied: ns.Annotations[resource.AnnotationLastApplied],
+
+			Labels:      ns.Labels,
+			Annotations: ns.Annotations,
 		}
 
 		if api.inventories.Exists(ns.Name) {
@@ -53,38 +86,106 @@ func (api *api) ListNamespaces() ([]Namespace, error) {
 
 }
 
-type progress interface {
+// ListInventories returns the inventories of the playbook.
+// If selector is not empty, only the inventories of the namespaces matching this label selector are returned.
+func (api *api) ListInventories(selector string) ([]playbook.Inventory, error) {
+	invs, err := api.inventories.List()
+	if err != nil || selector == "" {
+		return invs, err
+	}
+
+	nsList, err := api.namespaces.List(selector)
+	if err != nil {
+		return nil, err
+	}
+
+	matching := make(map[string]bool, len(nsList))
+	for _, ns := range nsList {
+		matching[ns.Name] = true
+	}
+
+	var filtered []playbook.Inventory
+	for _, inv := range invs {
+		if matching[inv.Namespace] {
+			filtered = append(filtered, inv)
+		}
+	}
+
+	return filtered, nil
+}
+
+// merge returns the values of defaults overridden by the given values
+func merge(defaults, values map[string]string) map[string]string {
+	if len(defaults) == 0 && len(values) == 0 {
+		return nil
+	}
+
+	merged := make(map[string]string, len(defaults)+len(values))
+	for k, v := range defaults {
+		merged[k] = v
+	}
+	for k, v := range values {
+		merged[k] = v
+	}
+
+	return merged
+}
+
+// Progress is notified of the namespace status while waiting for a namespace to be ready.
+type Progress interface {
 	Set(int) error
 }
 
 // WaitForNamespaceReady wait until all pods in the specified namespace are ready.
-// And error is returned if the timeout is reach.
-func (api *api) WaitForNamespaceReady(namespace string, timeout time.Duration, bar progress) error {
+// An error is returned if the timeout is reached or if the namespace can not become ready.
+func (api *api) WaitForNamespaceReady(namespace string, timeout time.Duration, bar Progress) error {
+	return WaitForNamespaceReady(api.namespaces, namespace, timeout, bar)
+}
+
+// WaitForNamespaceReady polls the status of the given namespace until all workloads are ready.
+// It is used by Api implementations to share the same waiting logic.
+// It fails fast with an Upstream error when a workload failed or a pod has a terminal problem, such as an image
+// which can not be pulled, and with a Timeout error once timeout is reached.
+// Both errors list the blocking workloads and pods in their "blocking" detail.
+func WaitForNamespaceReady(namespaces resource.NamespaceService, namespace string, timeout time.Duration, bar Progress) error {
 
 	ticker := time.NewTicker(tickerDuration)
-	timerCh := time.NewTimer(timeout).C
-	doneCh := make(chan bool)
-
-	go func(bar progress, ns resource.NamespaceService, namespace string) {
-		for range ticker.C {
-			status, err := ns.GetStatus(namespace)
-			if err != nil {
-				ticker.Stop()
-			}
-			bar.Set(status.Status)
-			if status.Status == 100 {
-				doneCh <- true
-			}
-		}
-	}(bar, api.namespaces, namespace)
+	defer ticker.Stop()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	var last *resource.NamespaceStatus
 
 	for {
 		select {
-		case <-timerCh:
-			ticker.Stop()
-			return fmt.Errorf("time out : Some pods are not yet ready")
-		case <-doneCh:
+		case <-timer.C:
+			err := errors.New(errors.Timeout, "time out : Some pods are not yet ready")
+			if last != nil {
+				err = errors.WithDetail(err, "blocking", last.Blocking())
+			}
+			return err
+		case <-ticker.C:
+		}
+
+		status, err := namespaces.GetStatus(namespace)
+		if err != nil {
+			continue
+		}
+
+		last = status
+		bar.Set(status.Status)
+
+		if status.Status == 100 {
 			return nil
 		}
+
+		if status.Failed() {
+			return errors.WithDetail(
+				errors.New(errors.Upstream, "namespace %s can not become ready", namespace),
+				"blocking",
+				status.Blocking(),
+			)
+		}
 	}
 }
@@ -2,15 +2,132 @@ package api_test
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/Meetic/blackbeard/pkg/api"
+	"github.com/Meetic/blackbeard/pkg/errors"
+	"github.com/Meetic/blackbeard/pkg/kubernetes"
+	"github.com/Meetic/blackbeard/pkg/mock"
+	"github.com/Meetic/blackbeard/pkg/resource"
 )
 
 func TestApi_ListNamespaces(t *testing.T) {
-	namespaces, err := blackbeard.ListNamespaces()
+	namespaces, err := blackbeard.ListNamespaces("")
 
 	assert.Nil(t, err)
 	assert.NotNil(t, namespaces)
 	assert.Equal(t, "test", namespaces[0].Name)
 	assert.Equal(t, true, namespaces[0].Managed)
 }
+
+// progress records the statuses notified while waiting
+type progress []int
+
+func (p *progress) Set(status int) error {
+	*p = append(*p, status)
+	return nil
+}
+
+func newWaitNamespaces(objects ...runtime.Object) resource.NamespaceService {
+	objects = append(objects, &v1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: "test"},
+		Status:     v1.NamespaceStatus{Phase: v1.NamespaceActive},
+	})
+
+	kube := fake.NewSimpleClientset(objects...)
+
+	return resource.NewNamespaceService(
+		kubernetes.NewNamespaceRepository(kube),
+		kubernetes.NewPodRepository(kube),
+		kubernetes.NewDeploymentRepository(kube),
+		kubernetes.NewStatefulsetRepository(kube),
+		kubernetes.NewJobRepository(kube),
+	)
+}
+
+func TestWaitForNamespaceReadyFailsFast(t *testing.T) {
+	namespaces := newWaitNamespaces(
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "test"},
+			Status:     appsv1.DeploymentStatus{Replicas: 1},
+		},
+		&v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "api-1", Namespace: "test"},
+			Status: v1.PodStatus{
+				Phase: v1.PodPending,
+				ContainerStatuses: []v1.ContainerStatus{{
+					Name:  "php",
+					State: v1.ContainerState{Waiting: &v1.ContainerStateWaiting{Reason: "ImagePullBackOff", Message: "image not found"}},
+				}},
+			},
+		},
+	)
+
+	var bar progress
+	err := api.WaitForNamespaceReady(namespaces, "test", time.Minute, &bar)
+
+	assert.True(t, errors.Is(err, errors.Upstream))
+	assert.Equal(t, progress{0}, bar)
+	assert.Equal(t, []string{
+		"deployments/api: NotReady",
+		"pod api-1 (php): ImagePullBackOff: image not found",
+	}, errors.DetailsOf(err)["blocking"])
+}
+
+func TestWaitForNamespaceReadyTimeout(t *testing.T) {
+	namespaces := newWaitNamespaces(&appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "test"},
+		Status:     appsv1.DeploymentStatus{Replicas: 1},
+	})
+
+	var bar progress
+	err := api.WaitForNamespaceReady(namespaces, "test", 10*time.Millisecond, &bar)
+
+	assert.True(t, errors.Is(err, errors.Timeout))
+}
+
+func newMetadataApi(kube *fake.Clientset) api.Api {
+	repositories := mock.NewRepositories(kube)
+	repositories.Namespaces = kubernetes.NewNamespaceRepository(kube)
+
+	return api.NewApi(repositories)
+}
+
+func TestCreateWithMetadata(t *testing.T) {
+	blackbeard := newMetadataApi(fake.NewSimpleClientset())
+
+	_, err := blackbeard.Create("test1", api.CreateOptions{
+		Labels:      map[string]string{"ticket": "FEAT-123"},
+		Annotations: map[string]string{resource.AnnotationOwner: "john"},
+	})
+	assert.Nil(t, err)
+
+	_, err = blackbeard.Create("test2", api.CreateOptions{Labels: map[string]string{"team": "payment"}})
+	assert.Nil(t, err)
+
+	namespaces, err := blackbeard.ListNamespaces("team=dating")
+	assert.Nil(t, err)
+	assert.Len(t, namespaces, 1)
+	assert.Equal(t, "john", namespaces[0].Owner)
+	assert.Equal(t, map[string]string{
+		"team":                "dating",
+		"ticket":              "FEAT-123",
+		resource.LabelManager: resource.ManagerBlackbeard,
+	}, namespaces[0].Labels)
+
+	invs, err := blackbeard.ListInventories("team=payment")
+	assert.Nil(t, err)
+	assert.Len(t, invs, 1)
+	assert.Equal(t, "test2", invs[0].Namespace)
+
+	invs, err = blackbeard.ListInventories("")
+	assert.Nil(t, err)
+	assert.Len(t, invs, 2)
+}
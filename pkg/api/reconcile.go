@@ -0,0 +1,209 @@
+package api
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/Meetic/blackbeard/pkg/errors"
+	"github.com/Meetic/blackbeard/pkg/resource"
+)
+
+// Kinds of the problems found when reconciling inventories and namespaces
+const (
+	// OrphanInventory is an inventory whose namespace does not exist.
+	// It is fixed by deleting the inventory and its configs.
+	OrphanInventory = "OrphanInventory"
+	// OrphanConfigs is a configs dir without inventory. It is fixed by deleting the configs dir.
+	OrphanConfigs = "OrphanConfigs"
+	// UnlabelledNamespace is a namespace having an inventory but no manager=blackbeard label.
+	// It is fixed by labelling the namespace.
+	UnlabelledNamespace = "UnlabelledNamespace"
+	// MissingInventory is a namespace labelled manager=blackbeard without inventory.
+	// It is fixed by generating its inventory from the defaults of the playbook.
+	MissingInventory = "MissingInventory"
+)
+
+// Problem is a drift between the inventories on disk and the namespaces in the cluster
+type Problem struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace"`
+	Message   string `json:"message"`
+	// Fixed is true if the problem has been fixed.
+	Fixed bool `json:"fixed"`
+	// Error is the reason why the problem could not be fixed, if any.
+	Error string `json:"error,omitempty"`
+}
+
+// ReconcileReport lists the problems found when reconciling inventories and namespaces.
+// Problems are only fixed if DryRun is false.
+type ReconcileReport struct {
+	DryRun   bool      `json:"dryRun"`
+	Problems []Problem `json:"problems"`
+}
+
+// Reconcile compares the inventories and configs on disk with the namespaces of the cluster and reports :
+// inventories without namespace, configs without inventory, namespaces having an inventory but no
+// manager=blackbeard label and labelled namespaces without inventory.
+// If fix is true, each problem is fixed. A failing fix does not prevent the other ones from being applied.
+func (api *api) Reconcile(fix bool) (*ReconcileReport, error) {
+	problems, err := api.findProblems()
+	if err != nil {
+		return nil, err
+	}
+
+	report := &ReconcileReport{DryRun: !fix, Problems: problems}
+
+	if !fix {
+		return report, nil
+	}
+
+	for i, p := range report.Problems {
+		if err := api.fix(p); err != nil {
+			report.Problems[i].Error = err.Error()
+			continue
+		}
+		report.Problems[i].Fixed = true
+	}
+
+	return report, nil
+}
+
+func (api *api) findProblems() ([]Problem, error) {
+	var problems []Problem
+
+	invs, err := api.inventories.List()
+	if err != nil {
+		return nil, err
+	}
+
+	inventories := make(map[string]bool, len(invs))
+
+	for _, inv := range invs {
+		inventories[inv.Namespace] = true
+
+		ns, err := api.namespaces.Get(inv.Namespace)
+		if errors.Is(err, errors.NotFound) {
+			problems = append(problems, Problem{
+				Kind:      OrphanInventory,
+				Namespace: inv.Namespace,
+				Message:   "the inventory has no namespace",
+			})
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if ns.Labels[resource.LabelManager] != resource.ManagerBlackbeard {
+			problems = append(problems, Problem{
+				Kind:      UnlabelledNamespace,
+				Namespace: inv.Namespace,
+				Message:   "the namespace has an inventory but is not labelled " + resource.LabelManager + "=" + resource.ManagerBlackbeard,
+			})
+		}
+	}
+
+	configs, err := api.configs.List()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, namespace := range configs {
+		if !inventories[namespace] {
+			problems = append(problems, Problem{
+				Kind:      OrphanConfigs,
+				Namespace: namespace,
+				Message:   "the configs have no inventory",
+			})
+		}
+	}
+
+	namespaces, err := api.namespaces.List("")
+	if err != nil {
+		return nil, err
+	}
+
+	for _, ns := range namespaces {
+		if !inventories[ns.Name] {
+			problems = append(problems, Problem{
+				Kind:      MissingInventory,
+				Namespace: ns.Name,
+				Message:   "the namespace is managed by blackbeard but has no inventory",
+			})
+		}
+	}
+
+	sort.SliceStable(problems, func(i, j int) bool {
+		return problems[i].Namespace < problems[j].Namespace
+	})
+
+	return problems, nil
+}
+
+func (api *api) fix(p Problem) error {
+	switch p.Kind {
+	case OrphanInventory:
+		if err := api.inventories.Delete(p.Namespace); err != nil {
+			return err
+		}
+		return api.configs.Delete(p.Namespace)
+	case OrphanConfigs:
+		return api.configs.Delete(p.Namespace)
+	case UnlabelledNamespace:
+		return api.namespaces.Adopt(p.Namespace)
+	case MissingInventory:
+		inv, err := api.inventories.Create(p.Namespace)
+		if err != nil {
+			return err
+		}
+		return api.configs.Generate(inv)
+	}
+
+	return errors.New(errors.Internal, "unknown problem %s", p.Kind)
+}
+
+// RunReconciler reconciles inventories and namespaces every interval, until ctx is done.
+// The first reconciliation runs immediately, catching the namespaces deleted while the server was down.
+// Problems are logged, and fixed if fix is true.
+func RunReconciler(ctx context.Context, api Api, interval time.Duration, fix bool) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		report, err := api.Reconcile(fix)
+		if err != nil {
+			logrus.
+				WithFields(logrus.Fields{"component": "reconciler"}).
+				Errorf("unable to reconcile inventories and namespaces : %v", err)
+		} else {
+			logReport(report)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func logReport(report *ReconcileReport) {
+	for _, p := range report.Problems {
+		entry := logrus.WithFields(logrus.Fields{
+			"component": "reconciler",
+			"kind":      p.Kind,
+			"namespace": p.Namespace,
+			"fixed":     p.Fixed,
+		})
+
+		if p.Error != "" {
+			entry.Errorf("%s, unable to fix it : %s", p.Message, p.Error)
+			continue
+		}
+
+		entry.Warn(p.Message)
+	}
+}
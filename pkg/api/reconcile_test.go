@@ -0,0 +1,127 @@
+package api_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/Meetic/blackbeard/pkg/api"
+	"github.com/Meetic/blackbeard/pkg/files"
+	"github.com/Meetic/blackbeard/pkg/kubernetes"
+	"github.com/Meetic/blackbeard/pkg/mock"
+	"github.com/Meetic/blackbeard/pkg/playbook"
+	"github.com/Meetic/blackbeard/pkg/resource"
+)
+
+func newNamespace(name string, managed bool) *v1.Namespace {
+	ns := &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name}}
+	if managed {
+		ns.Labels = map[string]string{resource.LabelManager: resource.ManagerBlackbeard}
+	}
+	return ns
+}
+
+// newReconcileApi returns an api storing the inventories and the configs in files, and the namespaces in kube
+func newReconcileApi(kube *fake.Clientset, inventories playbook.InventoryRepository, configPath string) api.Api {
+	repositories := mock.NewRepositories(kube)
+	repositories.Inventories = inventories
+	repositories.Configs = files.NewConfigRepository(configPath)
+	repositories.Namespaces = kubernetes.NewNamespaceRepository(kube)
+
+	return api.NewApi(repositories)
+}
+
+func TestReconcile(t *testing.T) {
+	dir := t.TempDir()
+	inventoryPath := filepath.Join(dir, "inventories")
+	configPath := filepath.Join(dir, "configs")
+	assert.Nil(t, os.Mkdir(inventoryPath, 0755))
+	assert.Nil(t, os.MkdirAll(filepath.Join(configPath, "stale"), 0755))
+
+	inventories := files.NewInventoryRepository(inventoryPath)
+	for _, namespace := range []string{"managed", "orphan", "unlabelled"} {
+		assert.Nil(t, inventories.Create(playbook.Inventory{Namespace: namespace}))
+	}
+
+	kube := fake.NewSimpleClientset(
+		newNamespace("managed", true),
+		newNamespace("unlabelled", false),
+		newNamespace("lost", true),
+		newNamespace("kube-system", false),
+	)
+
+	blackbeard := newReconcileApi(kube, inventories, configPath)
+
+	report, err := blackbeard.Reconcile(false)
+	assert.Nil(t, err)
+	assert.True(t, report.DryRun)
+
+	var problems []string
+	for _, p := range report.Problems {
+		assert.False(t, p.Fixed)
+		problems = append(problems, p.Kind+"/"+p.Namespace)
+	}
+	assert.Equal(t, []string{
+		"MissingInventory/lost",
+		"OrphanInventory/orphan",
+		"OrphanConfigs/stale",
+		"UnlabelledNamespace/unlabelled",
+	}, problems)
+
+	report, err = blackbeard.Reconcile(true)
+	assert.Nil(t, err)
+	assert.False(t, report.DryRun)
+	assert.Len(t, report.Problems, 4)
+	for _, p := range report.Problems {
+		assert.True(t, p.Fixed, p.Kind)
+		assert.Empty(t, p.Error)
+	}
+
+	report, err = blackbeard.Reconcile(false)
+	assert.Nil(t, err)
+	assert.Empty(t, report.Problems)
+
+	assert.True(t, inventories.Exists("lost"))
+	assert.False(t, inventories.Exists("orphan"))
+	assert.NoDirExists(t, filepath.Join(configPath, "stale"))
+}
+
+func TestWatchNamespaceDeletedWhileNotWatched(t *testing.T) {
+	dir := t.TempDir()
+	inventoryPath := filepath.Join(dir, "inventories")
+	assert.Nil(t, os.Mkdir(inventoryPath, 0755))
+
+	inventories := files.NewInventoryRepository(inventoryPath)
+	for _, namespace := range []string{"managed", "deleted"} {
+		assert.Nil(t, inventories.Create(playbook.Inventory{Namespace: namespace}))
+	}
+
+	kube := fake.NewSimpleClientset(newNamespace("managed", true))
+
+	blackbeard := newReconcileApi(kube, inventories, filepath.Join(dir, "configs"))
+
+	hook := test.NewGlobal()
+	defer hook.Reset()
+
+	// the namespace was deleted before the watcher started, so the first list reports it, without deleting it
+	go blackbeard.WatchNamespaceDeleted()
+
+	assert.Eventually(t, func() bool {
+		for _, entry := range hook.AllEntries() {
+			if entry.Level == logrus.WarnLevel && entry.Data["namespace"] == "deleted" {
+				return true
+			}
+		}
+		return false
+	}, 2*time.Second, 10*time.Millisecond)
+	assert.True(t, inventories.Exists("deleted"))
+	assert.True(t, inventories.Exists("managed"))
+}
@@ -0,0 +1,354 @@
+package client
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/Meetic/blackbeard/pkg/api"
+	"github.com/Meetic/blackbeard/pkg/errors"
+	"github.com/Meetic/blackbeard/pkg/playbook"
+	"github.com/Meetic/blackbeard/pkg/resource"
+)
+
+// remoteApi implements api.Api by calling a blackbeard server.
+// Configs are generated and applied by the server, so configPath parameters are ignored.
+type remoteApi struct {
+	client *Client
+}
+
+// NewApi returns an api.Api calling the blackbeard server using the given client.
+func NewApi(client *Client) api.Api {
+	return &remoteApi{client: client}
+}
+
+func errNotAvailable(action string) error {
+	return errors.New(errors.Invalid, "%s is not available when using a blackbeard server", action)
+}
+
+// Inventories returns an InventoryService calling the server
+func (r *remoteApi) Inventories() playbook.InventoryService {
+	return &inventoryService{r.client}
+}
+
+// Namespaces returns a NamespaceService calling the server
+func (r *remoteApi) Namespaces() resource.NamespaceService {
+	return &namespaceService{r.client}
+}
+
+// Playbooks returns a PlaybookService calling the server
+func (r *remoteApi) Playbooks() playbook.PlaybookService {
+	return &playbookService{r.client}
+}
+
+// Pods returns a PodService. Only logs are exposed by the server.
+func (r *remoteApi) Pods() resource.PodService {
+	return &podService{r.client}
+}
+
+// Create creates a namespace and its inventory on the server
+func (r *remoteApi) Create(namespace string, opts api.CreateOptions) (playbook.Inventory, error) {
+	return r.client.CreateInventory(namespace, opts)
+}
+
+// Delete deletes a namespace and its inventory on the server.
+// The server always deletes the inventory once the namespace is actually deleted.
+func (r *remoteApi) Delete(namespace string, wait bool) error {
+	return r.client.DeleteInventory(namespace)
+}
+
+// Adopt brings an existing namespace under blackbeard management on the server
+func (r *remoteApi) Adopt(namespace string, inferVersions bool) (playbook.Inventory, error) {
+	return r.client.AdoptNamespace(namespace, inferVersions)
+}
+
+// Detach stops managing a namespace on the server
+func (r *remoteApi) Detach(namespace string) error {
+	return r.client.DetachNamespace(namespace)
+}
+
+// Reconcile reports and optionally fixes the drift between inventories and namespaces on the server
+func (r *remoteApi) Reconcile(fix bool) (*api.ReconcileReport, error) {
+	return r.client.Doctor(fix)
+}
+
+// StartBulk starts an operation on many namespaces on the server
+func (r *remoteApi) StartBulk(operation string, opts api.BulkOptions, configPath string) (*api.BulkReport, error) {
+	return r.client.StartBulk(operation, opts)
+}
+
+// GetBulk returns the progress of a bulk operation running on the server
+func (r *remoteApi) GetBulk(id string) (*api.BulkReport, error) {
+	return r.client.GetBulk(id)
+}
+
+// Drift compares the live objects of a namespace with its rendered configs on the server
+func (r *remoteApi) Drift(namespace string) (*resource.DriftReport, error) {
+	return r.client.GetDrift(namespace)
+}
+
+// ListExposedServices returns the exposed services of a namespace
+func (r *remoteApi) ListExposedServices(namespace string) ([]resource.Service, error) {
+	return r.client.ListServices(namespace)
+}
+
+// GetQuota returns the usage of the resource quotas of a namespace
+func (r *remoteApi) GetQuota(namespace string) ([]resource.QuotaUsage, error) {
+	return r.client.GetQuota(namespace)
+}
+
+// Check returns the report of the checks run by the server
+func (r *remoteApi) Check(ctx context.Context, namespace string) (*resource.CheckReport, error) {
+	return r.client.Check(ctx, namespace)
+}
+
+// ListNamespaces returns the namespaces known by the server
+func (r *remoteApi) ListNamespaces(selector string) ([]api.Namespace, error) {
+	return r.client.ListNamespaces(selector)
+}
+
+// ListInventories returns the inventories of the server
+func (r *remoteApi) ListInventories(selector string) ([]playbook.Inventory, error) {
+	return r.client.ListInventories(selector)
+}
+
+// Reset resets an inventory to the defaults of the server playbook and applies it
+func (r *remoteApi) Reset(namespace string, configPath string) error {
+	return r.client.ResetInventory(namespace)
+}
+
+// Upgrade merges the defaults of the server playbook into an inventory, and applies it if apply is true
+func (r *remoteApi) Upgrade(namespace string, apply bool, configPath string) (*playbook.UpgradeReport, error) {
+	return r.client.UpgradeInventory(namespace, apply)
+}
+
+// Apply applies the inventory stored on the server
+func (r *remoteApi) Apply(namespace string, configPath string) error {
+	return r.client.ApplyInventory(namespace)
+}
+
+// Update replaces the inventory stored on the server and applies it
+func (r *remoteApi) Update(namespace string, inventory playbook.Inventory, configPath string) error {
+	return r.client.UpdateInventory(namespace, inventory)
+}
+
+// WaitForNamespaceReady polls the namespace status from the server until all pods are ready
+func (r *remoteApi) WaitForNamespaceReady(namespace string, timeout time.Duration, bar api.Progress) error {
+	return api.WaitForNamespaceReady(r.Namespaces(), namespace, timeout, bar)
+}
+
+// GetVersion returns the versions used by the server
+func (r *remoteApi) GetVersion() (*api.Version, error) {
+	return r.client.GetVersion()
+}
+
+// ListResources returns the objects of the given kind in a namespace
+func (r *remoteApi) ListResources(namespace, kind string) ([]resource.Object, error) {
+	return r.client.ListResources(namespace, kind)
+}
+
+// GetResource returns an object of a namespace
+func (r *remoteApi) GetResource(namespace, kind, name string) (*resource.Object, error) {
+	return r.client.GetResource(namespace, kind, name)
+}
+
+// DeleteResource deletes an object from a namespace
+func (r *remoteApi) DeleteResource(namespace, kind, name string) error {
+	return r.client.DeleteResource(namespace, kind, name)
+}
+
+// Restart recreates the pods of a deployment or a statefulset
+func (r *remoteApi) Restart(namespace, kind, name string) error {
+	return r.client.Restart(namespace, kind, name)
+}
+
+// RestartAll lists the deployments and statefulsets of a namespace and restarts them one by one
+func (r *remoteApi) RestartAll(namespace string) ([]string, error) {
+	var restarted []string
+
+	for _, kind := range []string{resource.KindDeployment, resource.KindStatefulset} {
+		objects, err := r.client.ListResources(namespace, kind)
+		if err != nil {
+			return restarted, err
+		}
+
+		for _, o := range objects {
+			if err := r.client.Restart(namespace, kind, o.Name); err != nil {
+				return restarted, err
+			}
+			restarted = append(restarted, kind+"/"+o.Name)
+		}
+	}
+
+	return restarted, nil
+}
+
+// Exec runs a command in a pod using the exec websocket of the server
+func (r *remoteApi) Exec(ctx context.Context, namespace string, opts resource.ExecOptions) error {
+	return r.client.Exec(ctx, namespace, opts)
+}
+
+// WatchNamespaceDeleted does nothing : the server watches namespaces itself.
+func (r *remoteApi) WatchNamespaceDeleted() {
+	logrus.Warn("namespaces are watched by the blackbeard server")
+}
+
+type inventoryService struct {
+	client *Client
+}
+
+func (s *inventoryService) Create(namespace string) (playbook.Inventory, error) {
+	return s.client.CreateInventory(namespace, api.CreateOptions{})
+}
+
+func (s *inventoryService) Update(namespace string, inventory playbook.Inventory) error {
+	return s.client.UpdateInventory(namespace, inventory)
+}
+
+func (s *inventoryService) Get(namespace string) (playbook.Inventory, error) {
+	return s.client.GetInventory(namespace)
+}
+
+func (s *inventoryService) Exists(namespace string) bool {
+	_, err := s.client.GetInventory(namespace)
+	return err == nil
+}
+
+func (s *inventoryService) List() ([]playbook.Inventory, error) {
+	return s.client.ListInventories("")
+}
+
+func (s *inventoryService) Delete(namespace string) error {
+	return s.client.DeleteInventory(namespace)
+}
+
+func (s *inventoryService) Upgrade(namespace string) (*playbook.UpgradeReport, error) {
+	return s.client.UpgradeInventory(namespace, false)
+}
+
+func (s *inventoryService) Diff(namespace, against string) (*playbook.InventoryDiff, error) {
+	return s.client.DiffInventory(namespace, against)
+}
+
+func (s *inventoryService) Reset(namespace string) (playbook.Inventory, error) {
+	if err := s.client.ResetInventory(namespace); err != nil {
+		return playbook.Inventory{}, err
+	}
+
+	return s.client.GetInventory(namespace)
+}
+
+type namespaceService struct {
+	client *Client
+}
+
+func (s *namespaceService) Create(namespace string, metadata resource.NamespaceMetadata, profile *resource.NamespaceProfile) error {
+	opts := api.CreateOptions{Labels: metadata.Labels, Annotations: metadata.Annotations}
+	if profile != nil {
+		opts.Profile = profile.Name
+	}
+
+	_, err := s.client.CreateInventory(namespace, opts)
+	return err
+}
+
+func (s *namespaceService) ApplyProfile(namespace string, profile *resource.NamespaceProfile) error {
+	return errNotAvailable("applying profiles")
+}
+
+func (s *namespaceService) ApplyConfig(namespace string, configPath string) error {
+	return s.client.ApplyInventory(namespace)
+}
+
+func (s *namespaceService) Delete(namespace string) error {
+	return s.client.DeleteInventory(namespace)
+}
+
+func (s *namespaceService) GetStatus(namespace string) (*resource.NamespaceStatus, error) {
+	return s.client.GetStatus(namespace)
+}
+
+func (s *namespaceService) List(selector string) ([]resource.Namespace, error) {
+	namespaces, err := s.client.ListNamespaces(selector)
+	if err != nil {
+		return nil, err
+	}
+
+	var list []resource.Namespace
+	for _, ns := range namespaces {
+		list = append(list, resource.Namespace{
+			Name:        ns.Name,
+			Phase:       ns.Phase,
+			Status:      ns.Status,
+			Labels:      ns.Labels,
+			Annotations: ns.Annotations,
+		})
+	}
+
+	return list, nil
+}
+
+func (s *namespaceService) Get(namespace string) (*resource.Namespace, error) {
+	return nil, errNotAvailable("reading namespaces")
+}
+
+func (s *namespaceService) Adopt(namespace string) error {
+	return errNotAvailable("labelling namespaces")
+}
+
+func (s *namespaceService) Detach(namespace string) error {
+	return errNotAvailable("labelling namespaces")
+}
+
+func (s *namespaceService) GetQuota(namespace string) ([]resource.QuotaUsage, error) {
+	return s.client.GetQuota(namespace)
+}
+
+func (s *namespaceService) Annotate(namespace string, annotations map[string]string) error {
+	return errNotAvailable("annotating namespaces")
+}
+
+func (s *namespaceService) Watch(events chan resource.NamespaceEvent) {
+	close(events)
+}
+
+type playbookService struct {
+	client *Client
+}
+
+func (s *playbookService) GetDefault() (playbook.Inventory, error) {
+	return s.client.GetDefaults()
+}
+
+func (s *playbookService) GetTemplate() ([]playbook.ConfigTemplate, error) {
+	return nil, errNotAvailable("reading templates")
+}
+
+func (s *playbookService) Version() (playbook.Version, error) {
+	return s.client.GetPlaybook()
+}
+
+func (s *playbookService) Status() (playbook.Status, error) {
+	return s.client.GetPlaybookStatus()
+}
+
+func (s *playbookService) At(ref string) (playbook.PlaybookService, error) {
+	if ref == "" {
+		return s, nil
+	}
+
+	return nil, errNotAvailable("reading a playbook ref")
+}
+
+type podService struct {
+	client *Client
+}
+
+func (s *podService) List(namespace string) (resource.Pods, error) {
+	return nil, errNotAvailable("listing pods")
+}
+
+func (s *podService) Logs(ctx context.Context, namespace string, opts resource.LogOptions, lines chan<- resource.LogLine) error {
+	return s.client.StreamLogs(ctx, namespace, opts, lines)
+}
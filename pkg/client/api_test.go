@@ -0,0 +1,44 @@
+package client_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Meetic/blackbeard/pkg/api"
+	"github.com/Meetic/blackbeard/pkg/client"
+	"github.com/Meetic/blackbeard/pkg/errors"
+)
+
+func TestRemoteApi(t *testing.T) {
+	c, rec, _ := newServer(t)
+
+	blackbeard := client.NewApi(c)
+
+	inv, err := blackbeard.Create("test", api.CreateOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, "test", inv.Namespace)
+
+	assert.Nil(t, blackbeard.Apply("test", ""))
+	assert.Nil(t, blackbeard.Reset("test", ""))
+
+	namespaces, err := blackbeard.ListNamespaces("")
+	assert.Nil(t, err)
+	assert.Len(t, namespaces, 1)
+
+	assert.True(t, blackbeard.Inventories().Exists("test"))
+
+	_, err = blackbeard.Pods().List("test")
+	assert.True(t, errors.Is(err, errors.Invalid))
+
+	assert.Nil(t, blackbeard.Delete("test", false))
+
+	assert.Equal(t, []string{
+		"POST /inventories",
+		"POST /inventories/test/apply",
+		"POST /inventories/test/reset",
+		"GET /namespaces",
+		"GET /inventories/test",
+		"DELETE /inventories/test",
+	}, rec.requests)
+}
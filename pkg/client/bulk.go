@@ -0,0 +1,30 @@
+package client
+
+import (
+	"net/http"
+
+	"github.com/Meetic/blackbeard/pkg/api"
+)
+
+// StartBulk starts an operation on the namespaces selected by opts : apply, reset, upgrade or delete.
+// The server runs it in the background, and the ID of the returned report gives its progress (see GetBulk).
+func (c *Client) StartBulk(operation string, opts api.BulkOptions) (*api.BulkReport, error) {
+	var report api.BulkReport
+
+	if err := c.do(http.MethodPost, path("/bulk/%s", operation), nil, opts, &report); err != nil {
+		return nil, err
+	}
+
+	return &report, nil
+}
+
+// GetBulk returns the progress of a bulk operation
+func (c *Client) GetBulk(id string) (*api.BulkReport, error) {
+	var report api.BulkReport
+
+	if err := c.do(http.MethodGet, path("/bulk/%s", id), nil, nil, &report); err != nil {
+		return nil, err
+	}
+
+	return &report, nil
+}
@@ -0,0 +1,285 @@
+// Package client provides a Go client for the blackbeard REST api.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Meetic/blackbeard/pkg/errors"
+)
+
+const (
+	defaultTimeout   = 30 * time.Second
+	defaultRetryWait = 500 * time.Millisecond
+)
+
+// Client calls a blackbeard server.
+type Client struct {
+	server    *url.URL
+	http      *http.Client
+	token     string
+	retries   int
+	retryWait time.Duration
+}
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithToken sets a bearer token sent in the Authorization header of each request.
+func WithToken(token string) Option {
+	return func(c *Client) {
+		c.token = token
+	}
+}
+
+// WithTimeout sets the maximum duration of a single request.
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.http.Timeout = timeout
+	}
+}
+
+// WithRetries sets the number of times an idempotent request is retried when the server can not be reached
+// or is unavailable. The wait duration between two attempts doubles after each attempt.
+func WithRetries(retries int, wait time.Duration) Option {
+	return func(c *Client) {
+		c.retries = retries
+		c.retryWait = wait
+	}
+}
+
+// WithHTTPClient sets the http client used to send requests.
+func WithHTTPClient(client *http.Client) Option {
+	return func(c *Client) {
+		c.http = client
+	}
+}
+
+// NewClient returns a client for the blackbeard server located at the given url.
+func NewClient(server string, opts ...Option) (*Client, error) {
+	u, err := url.Parse(server)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return nil, errors.New(errors.Invalid, "invalid blackbeard server url %q", server)
+	}
+
+	c := &Client{
+		server:    u,
+		http:      &http.Client{Timeout: defaultTimeout},
+		retryWait: defaultRetryWait,
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c, nil
+}
+
+// Server returns the url of the blackbeard server.
+func (c *Client) Server() string {
+	return c.server.String()
+}
+
+// do sends a request to the server and decodes the json response into out.
+// in is encoded as the json body of the request if not nil.
+// Error responses are decoded into typed errors.
+func (c *Client) do(method, path string, query url.Values, in, out interface{}) error {
+	return c.doContext(context.Background(), method, path, query, in, out)
+}
+
+// doContext is do, the request and its retries being canceled when ctx is done.
+func (c *Client) doContext(ctx context.Context, method, path string, query url.Values, in, out interface{}) error {
+	resp, err := c.send(ctx, method, path, query, in)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return errors.Wrap(err, errors.Upstream, "unable to decode the response of %s %s", method, path)
+	}
+
+	return nil
+}
+
+// send sends a request to the server, retrying idempotent requests, and returns the response if it is successful.
+// The caller must close the response body.
+func (c *Client) send(ctx context.Context, method, path string, query url.Values, in interface{}) (*http.Response, error) {
+	var body []byte
+
+	if in != nil {
+		b, err := json.Marshal(in)
+		if err != nil {
+			return nil, errors.Wrap(err, errors.Invalid, "unable to encode the request body")
+		}
+		body = b
+	}
+
+	u := c.url(path, query)
+
+	attempts := 1
+	if idempotent(method) {
+		attempts += c.retries
+	}
+
+	wait := c.retryWait
+
+	var err error
+
+	for attempt := 0; attempt < attempts; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return nil, errors.Wrap(ctx.Err(), errors.Timeout, "request %s %s canceled", method, path)
+			case <-time.After(wait):
+			}
+			wait *= 2
+		}
+
+		var resp *http.Response
+
+		resp, err = c.attempt(ctx, c.http, method, u, body)
+		if err != nil {
+			continue
+		}
+
+		if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+			return resp, nil
+		}
+
+		err = decodeError(resp)
+		resp.Body.Close()
+
+		if !retryable(resp.StatusCode) {
+			return nil, err
+		}
+	}
+
+	return nil, err
+}
+
+// stream sends a GET request whose response body is read until ctx is done.
+// The client timeout does not apply and the request is never retried.
+// The caller must close the response body.
+func (c *Client) stream(ctx context.Context, path string, query url.Values) (*http.Response, error) {
+	streaming := *c.http
+	streaming.Timeout = 0
+
+	resp, err := c.attempt(ctx, &streaming, http.MethodGet, c.url(path, query), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		return nil, decodeError(resp)
+	}
+
+	return resp, nil
+}
+
+func (c *Client) url(path string, query url.Values) string {
+	u := *c.server
+	u.Path = strings.TrimSuffix(u.Path, "/") + path
+	u.RawQuery = query.Encode()
+
+	return u.String()
+}
+
+func (c *Client) attempt(ctx context.Context, client *http.Client, method, u string, body []byte) (*http.Response, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u, reader)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.Invalid, "unable to create request %s %s", method, u)
+	}
+
+	req.Header.Set("Accept", "application/json")
+
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		if e, ok := err.(net.Error); ok && e.Timeout() {
+			return nil, errors.Wrap(err, errors.Timeout, "%s %s", method, u)
+		}
+		return nil, errors.Wrap(err, errors.Upstream, "%s %s", method, u)
+	}
+
+	return resp, nil
+}
+
+// statusKinds maps http status codes to error kinds.
+// It is used when the server does not return a problem document.
+var statusKinds = map[int]errors.Kind{
+	http.StatusBadRequest:          errors.Invalid,
+	http.StatusUnprocessableEntity: errors.Invalid,
+	http.StatusUnauthorized:        errors.Forbidden,
+	http.StatusForbidden:           errors.Forbidden,
+	http.StatusNotFound:            errors.NotFound,
+	http.StatusConflict:            errors.Conflict,
+	http.StatusRequestTimeout:      errors.Timeout,
+	http.StatusGatewayTimeout:      errors.Timeout,
+	http.StatusBadGateway:          errors.Upstream,
+	http.StatusServiceUnavailable:  errors.Upstream,
+}
+
+// decodeError converts an error response into a typed error.
+func decodeError(resp *http.Response) error {
+	raw, _ := io.ReadAll(resp.Body)
+
+	var p errors.Problem
+	if err := json.Unmarshal(raw, &p); err == nil && p.Code != "" {
+		return p.Err()
+	}
+
+	kind, ok := statusKinds[resp.StatusCode]
+	if !ok {
+		kind = errors.Internal
+	}
+
+	msg := strings.TrimSpace(string(raw))
+	if msg == "" {
+		msg = http.StatusText(resp.StatusCode)
+	}
+
+	return errors.New(kind, "%s %s: %d %s", resp.Request.Method, resp.Request.URL.Path, resp.StatusCode, msg)
+}
+
+func idempotent(method string) bool {
+	return method == http.MethodGet || method == http.MethodPut || method == http.MethodDelete
+}
+
+func retryable(status int) bool {
+	return status == http.StatusBadGateway || status == http.StatusServiceUnavailable || status == http.StatusGatewayTimeout
+}
+
+func path(format string, args ...string) string {
+	escaped := make([]interface{}, len(args))
+	for i, a := range args {
+		escaped[i] = url.PathEscape(a)
+	}
+
+	return fmt.Sprintf(format, escaped...)
+}
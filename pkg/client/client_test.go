@@ -0,0 +1,338 @@
+package client_test
+
+import (
+	"bytes"
+	"context"
+	nethttp "net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/Meetic/blackbeard/pkg/api"
+	"github.com/Meetic/blackbeard/pkg/client"
+	"github.com/Meetic/blackbeard/pkg/errors"
+	"github.com/Meetic/blackbeard/pkg/http"
+	"github.com/Meetic/blackbeard/pkg/mock"
+	"github.com/Meetic/blackbeard/pkg/resource"
+)
+
+// recorder records the requests served by the handler
+type recorder struct {
+	sync.Mutex
+	handler  nethttp.Handler
+	requests []string
+}
+
+func (r *recorder) ServeHTTP(w nethttp.ResponseWriter, req *nethttp.Request) {
+	r.Lock()
+	r.requests = append(r.requests, req.Method+" "+req.URL.Path)
+	r.Unlock()
+
+	r.handler.ServeHTTP(w, req)
+}
+
+func newHandler() *http.Handler {
+	gin.SetMode(gin.TestMode)
+
+	kube := fake.NewSimpleClientset()
+
+	return http.NewHandler(
+		api.NewApi(mock.NewRepositories(kube)),
+		"configs",
+		false,
+		http.WithExec(),
+	)
+}
+
+func newServer(t *testing.T) (*client.Client, *recorder, *http.Handler) {
+	h := newHandler()
+	rec := &recorder{handler: h.Engine()}
+	srv := httptest.NewServer(rec)
+	t.Cleanup(srv.Close)
+
+	c, err := client.NewClient(srv.URL)
+	assert.Nil(t, err)
+
+	return c, rec, h
+}
+
+// matches returns true if the request path matches the gin route path
+func matches(route, path string) bool {
+	r := strings.Split(route, "/")
+	p := strings.Split(path, "/")
+
+	if len(r) != len(p) {
+		return false
+	}
+
+	for i := range r {
+		if !strings.HasPrefix(r[i], ":") && r[i] != p[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func TestClientCoversEveryRoute(t *testing.T) {
+	c, rec, h := newServer(t)
+
+	inv, err := c.CreateInventory("test", api.CreateOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, "test", inv.Namespace)
+
+	inv, err = c.GetInventory("test")
+	assert.Nil(t, err)
+	assert.Equal(t, "test", inv.Namespace)
+
+	quota, err := c.GetQuota("test")
+	assert.Nil(t, err)
+	assert.Equal(t, []resource.QuotaUsage{{Quota: "blackbeard", Resource: "pods", Hard: "20", Used: "5", Percent: 25}}, quota)
+
+	invs, err := c.ListInventories("")
+	assert.Nil(t, err)
+	assert.Len(t, invs, 2)
+
+	assert.Nil(t, c.UpdateInventory("test", inv))
+	assert.Nil(t, c.ApplyInventory("test"))
+	assert.Nil(t, c.ResetInventory("test"))
+
+	upgrade, err := c.UpgradeInventory("test", true)
+	assert.Nil(t, err)
+	assert.Equal(t, "test", upgrade.Namespace)
+	assert.False(t, upgrade.Applied)
+
+	diff, err := c.DiffInventory("test", "")
+	assert.Nil(t, err)
+	assert.Equal(t, "test", diff.Namespace)
+	assert.Empty(t, diff.Differences)
+
+	def, err := c.GetDefaults()
+	assert.Nil(t, err)
+	assert.Equal(t, "default", def.Namespace)
+
+	version, err := c.GetPlaybook()
+	assert.Nil(t, err)
+	assert.Equal(t, "main", version.Ref)
+
+	playbookStatus, err := c.GetPlaybookStatus()
+	assert.Nil(t, err)
+	assert.True(t, playbookStatus.Loaded)
+
+	status, err := c.GetStatus("test")
+	assert.Nil(t, err)
+	assert.Equal(t, "Active", status.Phase)
+
+	_, err = c.ListServices("test")
+	assert.Nil(t, err)
+
+	report, err := c.Check(context.Background(), "test")
+	assert.Nil(t, err)
+	assert.Equal(t, "test", report.Namespace)
+	assert.True(t, report.Passed)
+	assert.Empty(t, report.Results)
+
+	drift, err := c.GetDrift("test")
+	assert.Nil(t, err)
+	assert.Equal(t, "test", drift.Namespace)
+	assert.False(t, drift.Drifted)
+	assert.Equal(t, 1, drift.Checked)
+	assert.Empty(t, drift.Objects)
+
+	// following logs only returns once the context is done
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	assert.Nil(t, c.StreamLogs(ctx, "test", resource.LogOptions{Follow: true}, make(chan resource.LogLine)))
+	cancel()
+
+	namespaces, err := c.ListNamespaces("")
+	assert.Nil(t, err)
+	assert.Equal(t, []api.Namespace{{Name: "test", Phase: "Active", Status: 0, Managed: true}}, namespaces)
+
+	objects, err := c.ListResources("test", "jobs")
+	assert.Nil(t, err)
+	assert.Empty(t, objects)
+
+	_, err = c.GetResource("test", "jobs", "migration")
+	assert.True(t, errors.Is(err, errors.NotFound))
+
+	err = c.DeleteResource("test", "jobs", "migration")
+	assert.True(t, errors.Is(err, errors.NotFound))
+
+	err = c.Restart("test", "deployment", "api")
+	assert.True(t, errors.Is(err, errors.NotFound))
+
+	var out bytes.Buffer
+	err = c.Exec(context.Background(), "test", resource.ExecOptions{Target: "pod/api-1", Command: []string{"ls"}, Stdout: &out})
+	assert.Nil(t, err)
+	assert.Equal(t, "api-1: ls\n", out.String())
+
+	bulk, err := c.StartBulk(api.BulkApply, api.BulkOptions{All: true})
+	assert.Nil(t, err)
+	assert.NotEmpty(t, bulk.ID)
+	assert.Len(t, bulk.Results, 2)
+
+	bulk, err = api.WaitForBulk(context.Background(), client.NewApi(c), bulk.ID, 10*time.Millisecond)
+	assert.Nil(t, err)
+	assert.Equal(t, api.BulkSucceeded, bulk.Status)
+	assert.Equal(t, 2, bulk.Count(api.BulkSucceeded))
+
+	doctor, err := c.Doctor(false)
+	assert.Nil(t, err)
+	assert.True(t, doctor.DryRun)
+	assert.Equal(t, []api.Problem{{
+		Kind:      api.MissingInventory,
+		Namespace: "test",
+		Message:   "the namespace is managed by blackbeard but has no inventory",
+	}}, doctor.Problems)
+
+	doctor, err = c.Doctor(true)
+	assert.Nil(t, err)
+	assert.False(t, doctor.DryRun)
+	assert.True(t, doctor.Problems[0].Fixed)
+
+	_, err = c.AdoptNamespace("test", true)
+	assert.True(t, errors.Is(err, errors.AlreadyExists))
+
+	assert.Nil(t, c.DetachNamespace("test"))
+
+	assert.Nil(t, c.DeleteInventory("test"))
+
+	v, err := c.GetVersion()
+	assert.Nil(t, err)
+	assert.Equal(t, &api.Version{Blackbeard: "dev", Kubernetes: "1.2", Kubectl: "0.9"}, v)
+
+	assert.Nil(t, c.Ready())
+	assert.Nil(t, c.Alive())
+
+	for _, route := range h.Engine().Routes() {
+		if route.Path == "/openapi.json" || strings.HasPrefix(route.Path, "/docs") {
+			continue
+		}
+
+		covered := false
+		for _, req := range rec.requests {
+			method, path, _ := strings.Cut(req, " ")
+			if method == route.Method && matches(route.Path, path) {
+				covered = true
+			}
+		}
+
+		assert.True(t, covered, "route %s %s is not covered by the client", route.Method, route.Path)
+	}
+}
+
+func TestClientExec(t *testing.T) {
+	c, _, _ := newServer(t)
+
+	var out bytes.Buffer
+	err := c.Exec(context.Background(), "test", resource.ExecOptions{
+		Target:  "deploy/api",
+		Command: []string{"cat"},
+		Stdin:   strings.NewReader("hello"),
+		Stdout:  &out,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "api: cat\nhello", out.String())
+
+	err = c.Exec(context.Background(), "test", resource.ExecOptions{Target: "deploy/api", Command: []string{"false"}, Stdout: &out})
+	code, ok := resource.ExitCode(err)
+	assert.True(t, ok)
+	assert.Equal(t, 1, code)
+
+	err = c.Exec(context.Background(), "test", resource.ExecOptions{Target: "deploy/api", Stdout: &out})
+	assert.True(t, errors.Is(err, errors.Invalid))
+}
+
+func TestClientDecodesErrors(t *testing.T) {
+	c, _, _ := newServer(t)
+
+	_, err := c.CreateInventory("", api.CreateOptions{})
+
+	assert.True(t, errors.Is(err, errors.Invalid))
+}
+
+func TestClientToken(t *testing.T) {
+	h := newHandler()
+	srv := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(nethttp.StatusUnauthorized)
+			return
+		}
+		h.Engine().ServeHTTP(w, r)
+	}))
+	defer srv.Close()
+
+	anonymous, _ := client.NewClient(srv.URL)
+	assert.True(t, errors.Is(anonymous.Ready(), errors.Forbidden))
+
+	authenticated, _ := client.NewClient(srv.URL, client.WithToken("secret"))
+	assert.Nil(t, authenticated.Ready())
+}
+
+func TestClientRetries(t *testing.T) {
+	h := newHandler()
+
+	var calls int
+	srv := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		calls++
+		if calls < 3 {
+			w.WriteHeader(nethttp.StatusServiceUnavailable)
+			return
+		}
+		h.Engine().ServeHTTP(w, r)
+	}))
+	defer srv.Close()
+
+	noRetry, _ := client.NewClient(srv.URL)
+	assert.True(t, errors.Is(noRetry.Ready(), errors.Upstream))
+
+	calls = 0
+	withRetry, _ := client.NewClient(srv.URL, client.WithRetries(2, time.Millisecond))
+	assert.Nil(t, withRetry.Ready())
+	assert.Equal(t, 3, calls)
+}
+
+func TestClientTimeout(t *testing.T) {
+	srv := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		time.Sleep(100 * time.Millisecond)
+	}))
+	defer srv.Close()
+
+	c, _ := client.NewClient(srv.URL, client.WithTimeout(10*time.Millisecond))
+
+	assert.True(t, errors.Is(c.Ready(), errors.Timeout))
+}
+
+func TestClientCheckCanceled(t *testing.T) {
+	done := make(chan struct{})
+	srv := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-done:
+		}
+	}))
+	defer srv.Close()
+	defer close(done)
+
+	c, _ := client.NewClient(srv.URL, client.WithRetries(2, time.Millisecond))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := c.Check(ctx, "test")
+	assert.Error(t, err)
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestNewClientInvalidURL(t *testing.T) {
+	_, err := client.NewClient("not an url")
+
+	assert.True(t, errors.Is(err, errors.Invalid))
+}
@@ -0,0 +1,133 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/url"
+	"strconv"
+
+	"golang.org/x/net/websocket"
+
+	"github.com/Meetic/blackbeard/pkg/api"
+	"github.com/Meetic/blackbeard/pkg/errors"
+	"github.com/Meetic/blackbeard/pkg/resource"
+)
+
+// Exec runs a command in a pod of the given namespace using the exec websocket of the server.
+// A command exiting with a non zero code returns an error whose code is read using resource.ExitCode.
+func (c *Client) Exec(ctx context.Context, namespace string, opts resource.ExecOptions) error {
+	query := url.Values{
+		"target":  {opts.Target},
+		"command": opts.Command,
+		"tty":     {strconv.FormatBool(opts.TTY)},
+		"stdin":   {strconv.FormatBool(opts.Stdin != nil)},
+	}
+	if opts.Container != "" {
+		query.Set("container", opts.Container)
+	}
+
+	u, _ := url.Parse(c.url(path("/inventories/%s/exec", namespace), query))
+	if u.Scheme == "https" {
+		u.Scheme = "wss"
+	} else {
+		u.Scheme = "ws"
+	}
+
+	config, err := websocket.NewConfig(u.String(), c.server.String())
+	if err != nil {
+		return errors.Wrap(err, errors.Invalid, "invalid exec url %s", u)
+	}
+
+	if c.token != "" {
+		config.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	ws, err := websocket.DialConfig(config)
+	if err != nil {
+		return errors.Wrap(err, errors.Upstream, "unable to open the exec websocket of namespace %s", namespace)
+	}
+	defer ws.Close()
+
+	done := make(chan struct{})
+	defer close(done)
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			ws.Close()
+		case <-done:
+		}
+	}()
+
+	if opts.Stdin != nil {
+		go sendStdin(ws, opts.Stdin)
+	}
+
+	if opts.Resize != nil {
+		go func() {
+			for {
+				select {
+				case size, ok := <-opts.Resize:
+					if !ok {
+						return
+					}
+					websocket.JSON.Send(ws, api.ExecMessage{Type: api.ExecResize, Width: size.Width, Height: size.Height})
+				case <-done:
+					return
+				}
+			}
+		}()
+	}
+
+	stderr := opts.Stderr
+	if stderr == nil {
+		stderr = opts.Stdout
+	}
+
+	for {
+		var msg api.ExecMessage
+
+		if err := websocket.JSON.Receive(ws, &msg); err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return errors.Wrap(err, errors.Upstream, "exec websocket of namespace %s closed", namespace)
+		}
+
+		switch msg.Type {
+		case api.ExecStdout:
+			io.WriteString(opts.Stdout, msg.Data)
+		case api.ExecStderr:
+			io.WriteString(stderr, msg.Data)
+		case api.ExecExit:
+			if msg.Code != 0 {
+				return resource.NewExitError(msg.Code)
+			}
+			return nil
+		case api.ExecError:
+			if msg.Error == nil {
+				return errors.New(errors.Upstream, "exec failed")
+			}
+			return msg.Error.Err()
+		}
+	}
+}
+
+// sendStdin sends stdin as websocket messages, then an eof message
+func sendStdin(ws *websocket.Conn, stdin io.Reader) {
+	buf := make([]byte, 32*1024)
+
+	for {
+		n, err := stdin.Read(buf)
+		if n > 0 {
+			if websocket.JSON.Send(ws, api.ExecMessage{Type: api.ExecStdin, Data: string(buf[:n])}) != nil {
+				return
+			}
+		}
+
+		if err != nil {
+			websocket.JSON.Send(ws, api.ExecMessage{Type: api.ExecEOF})
+			return
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package client
+
+import (
+	"net/http"
+
+	"github.com/Meetic/blackbeard/pkg/api"
+)
+
+// GetVersion returns the blackbeard, kubernetes and kubectl versions used by the server.
+func (c *Client) GetVersion() (*api.Version, error) {
+	var v api.Version
+
+	if err := c.do(http.MethodGet, "/version", nil, nil, &v); err != nil {
+		return nil, err
+	}
+
+	return &v, nil
+}
+
+// Ready returns an error if the server is not ready to handle traffic.
+func (c *Client) Ready() error {
+	return c.do(http.MethodGet, "/ready", nil, nil, nil)
+}
+
+// Alive returns an error if the server is not alive.
+func (c *Client) Alive() error {
+	return c.do(http.MethodGet, "/alive", nil, nil, nil)
+}
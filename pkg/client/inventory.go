@@ -0,0 +1,180 @@
+package client
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/Meetic/blackbeard/pkg/api"
+	"github.com/Meetic/blackbeard/pkg/playbook"
+	"github.com/Meetic/blackbeard/pkg/resource"
+)
+
+// CreateInventory creates a namespace and its inventory from the playbook defaults.
+// The namespace is limited by the profile given in opts, or by the default profile of the playbook.
+func (c *Client) CreateInventory(namespace string, opts api.CreateOptions) (playbook.Inventory, error) {
+	var inv playbook.Inventory
+
+	err := c.do(http.MethodPost, "/inventories", nil, struct {
+		Namespace string `json:"namespace"`
+		api.CreateOptions
+	}{namespace, opts}, &inv)
+
+	return inv, err
+}
+
+// GetInventory returns the inventory of the given namespace.
+func (c *Client) GetInventory(namespace string) (playbook.Inventory, error) {
+	var inv playbook.Inventory
+
+	err := c.do(http.MethodGet, path("/inventories/%s", namespace), nil, nil, &inv)
+
+	return inv, err
+}
+
+// GetQuota returns the usage of the resource quotas of the given namespace, reported along with its inventory.
+func (c *Client) GetQuota(namespace string) ([]resource.QuotaUsage, error) {
+	var inv struct {
+		Quota []resource.QuotaUsage `json:"quota"`
+	}
+
+	err := c.do(http.MethodGet, path("/inventories/%s", namespace), nil, nil, &inv)
+
+	return inv.Quota, err
+}
+
+// ListInventories returns the existing inventories.
+// If selector is not empty, only the inventories of the namespaces matching this label selector are returned.
+func (c *Client) ListInventories(selector string) ([]playbook.Inventory, error) {
+	var invs []playbook.Inventory
+
+	err := c.do(http.MethodGet, "/inventories", selectorQuery(selector), nil, &invs)
+
+	return invs, err
+}
+
+// UpdateInventory replaces the inventory of the given namespace and applies it.
+func (c *Client) UpdateInventory(namespace string, inv playbook.Inventory) error {
+	return c.do(http.MethodPut, path("/inventories/%s", namespace), nil, inv, nil)
+}
+
+// ApplyInventory generates the configs of the inventory of the given namespace and applies them.
+func (c *Client) ApplyInventory(namespace string) error {
+	return c.do(http.MethodPost, path("/inventories/%s/apply", namespace), nil, nil, nil)
+}
+
+// ResetInventory resets the inventory of the given namespace to the playbook defaults and applies it.
+func (c *Client) ResetInventory(namespace string) error {
+	return c.do(http.MethodPost, path("/inventories/%s/reset", namespace), nil, nil, nil)
+}
+
+// AdoptNamespace brings an existing namespace under blackbeard management and returns its generated inventory.
+// If infer is true, the versions of the inventory are read from the image tags of the namespace deployments.
+func (c *Client) AdoptNamespace(namespace string, infer bool) (playbook.Inventory, error) {
+	var inv playbook.Inventory
+
+	query := url.Values{}
+	if infer {
+		query.Set("infer", "true")
+	}
+
+	err := c.do(http.MethodPost, path("/inventories/%s/adopt", namespace), query, nil, &inv)
+
+	return inv, err
+}
+
+// UpgradeInventory merges the new defaults of the playbook into the inventory of the given namespace.
+// If apply is true, the inventory is applied once upgraded.
+func (c *Client) UpgradeInventory(namespace string, apply bool) (*playbook.UpgradeReport, error) {
+	var report playbook.UpgradeReport
+
+	query := url.Values{}
+	if apply {
+		query.Set("apply", "true")
+	}
+
+	if err := c.do(http.MethodPost, path("/inventories/%s/upgrade", namespace), query, nil, &report); err != nil {
+		return nil, err
+	}
+
+	return &report, nil
+}
+
+// DiffInventory returns the values differing between the inventory of the given namespace and the inventory of
+// the against namespace, or the defaults of the playbook if against is empty.
+func (c *Client) DiffInventory(namespace, against string) (*playbook.InventoryDiff, error) {
+	var diff playbook.InventoryDiff
+
+	query := url.Values{}
+	if against != "" {
+		query.Set("against", against)
+	}
+
+	if err := c.do(http.MethodGet, path("/inventories/%s/diff", namespace), query, nil, &diff); err != nil {
+		return nil, err
+	}
+
+	return &diff, nil
+}
+
+// DetachNamespace stops managing the given namespace : its inventory is deleted but the namespace is left running.
+func (c *Client) DetachNamespace(namespace string) error {
+	return c.do(http.MethodPost, path("/inventories/%s/detach", namespace), nil, nil, nil)
+}
+
+// Doctor returns the drift between the inventories of the server and the namespaces of the cluster.
+// If fix is true, the server fixes it.
+func (c *Client) Doctor(fix bool) (*api.ReconcileReport, error) {
+	method := http.MethodGet
+	if fix {
+		method = http.MethodPost
+	}
+
+	var report api.ReconcileReport
+
+	if err := c.do(method, "/doctor", nil, nil, &report); err != nil {
+		return nil, err
+	}
+
+	return &report, nil
+}
+
+// DeleteInventory deletes the given namespace, its inventory and its configs.
+func (c *Client) DeleteInventory(namespace string) error {
+	return c.do(http.MethodDelete, path("/inventories/%s", namespace), nil, nil, nil)
+}
+
+// GetDefaults returns the default inventory of the playbook used by the server.
+func (c *Client) GetDefaults() (playbook.Inventory, error) {
+	var inv playbook.Inventory
+
+	err := c.do(http.MethodGet, "/defaults", nil, nil, &inv)
+
+	return inv, err
+}
+
+// GetPlaybook returns the version of the playbook used by the server.
+func (c *Client) GetPlaybook() (playbook.Version, error) {
+	var v playbook.Version
+
+	err := c.do(http.MethodGet, "/playbook", nil, nil, &v)
+
+	return v, err
+}
+
+// GetPlaybookStatus returns the load status of the playbook used by the server.
+func (c *Client) GetPlaybookStatus() (playbook.Status, error) {
+	var s playbook.Status
+
+	err := c.do(http.MethodGet, "/playbook/status", nil, nil, &s)
+
+	return s, err
+}
+
+// selectorQuery returns the query of a label selector, or nil if selector is empty
+func selectorQuery(selector string) url.Values {
+	if selector == "" {
+		return nil
+	}
+
+	return url.Values{"selector": {selector}}
+}
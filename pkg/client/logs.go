@@ -0,0 +1,63 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/url"
+	"strconv"
+
+	"github.com/Meetic/blackbeard/pkg/errors"
+	"github.com/Meetic/blackbeard/pkg/resource"
+)
+
+// StreamLogs sends the log lines of the given namespace to the lines channel.
+// When logs are followed, it returns once ctx is done. The lines channel is not closed.
+// An error written to the stream by the server is returned as a typed error.
+func (c *Client) StreamLogs(ctx context.Context, namespace string, opts resource.LogOptions, lines chan<- resource.LogLine) error {
+	query := url.Values{}
+	if opts.Workload != "" {
+		query.Set("workload", opts.Workload)
+	}
+	if opts.Container != "" {
+		query.Set("container", opts.Container)
+	}
+	if opts.Follow {
+		query.Set("follow", strconv.FormatBool(opts.Follow))
+	}
+	if opts.Since > 0 {
+		query.Set("since", opts.Since.String())
+	}
+
+	resp, err := c.stream(ctx, path("/inventories/%s/logs", namespace), query)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	decoder := json.NewDecoder(resp.Body)
+
+	for {
+		var record struct {
+			resource.LogLine
+			Error *errors.Problem `json:"error"`
+		}
+
+		if err := decoder.Decode(&record); err != nil {
+			if err == io.EOF || ctx.Err() != nil {
+				return nil
+			}
+			return errors.Wrap(err, errors.Upstream, "unable to decode logs of namespace %s", namespace)
+		}
+
+		if record.Error != nil {
+			return record.Error.Err()
+		}
+
+		select {
+		case lines <- record.LogLine:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package client
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/Meetic/blackbeard/pkg/api"
+	"github.com/Meetic/blackbeard/pkg/resource"
+)
+
+// ListNamespaces returns the namespaces managed by blackbeard.
+// If selector is not empty, only the namespaces matching this label selector are returned.
+func (c *Client) ListNamespaces(selector string) ([]api.Namespace, error) {
+	var namespaces []api.Namespace
+
+	err := c.do(http.MethodGet, "/namespaces", selectorQuery(selector), nil, &namespaces)
+
+	return namespaces, err
+}
+
+// GetStatus returns the status of the given namespace.
+func (c *Client) GetStatus(namespace string) (*resource.NamespaceStatus, error) {
+	var status resource.NamespaceStatus
+
+	if err := c.do(http.MethodGet, path("/inventories/%s/status", namespace), nil, nil, &status); err != nil {
+		return nil, err
+	}
+
+	return &status, nil
+}
+
+// ListServices returns the services exposed outside of the cluster for the given namespace.
+func (c *Client) ListServices(namespace string) ([]resource.Service, error) {
+	var services []resource.Service
+
+	err := c.do(http.MethodGet, path("/inventories/%s/services", namespace), nil, nil, &services)
+
+	return services, err
+}
+
+// Check probes the services exposed by a namespace and returns the report of the server.
+// The request is canceled when ctx is done.
+func (c *Client) Check(ctx context.Context, namespace string) (*resource.CheckReport, error) {
+	var report resource.CheckReport
+
+	if err := c.doContext(ctx, http.MethodGet, path("/inventories/%s/check", namespace), nil, nil, &report); err != nil {
+		return nil, err
+	}
+
+	return &report, nil
+}
+
+// GetDrift compares the live objects of a namespace with the configs rendered by its last apply.
+func (c *Client) GetDrift(namespace string) (*resource.DriftReport, error) {
+	var report resource.DriftReport
+
+	if err := c.do(http.MethodGet, path("/inventories/%s/drift", namespace), nil, nil, &report); err != nil {
+		return nil, err
+	}
+
+	return &report, nil
+}
+
+// ListResources returns the objects of the given kind in a namespace.
+func (c *Client) ListResources(namespace, kind string) ([]resource.Object, error) {
+	var objects []resource.Object
+
+	err := c.do(http.MethodGet, path("/resources/%s/%s", namespace, kind), nil, nil, &objects)
+
+	return objects, err
+}
+
+// GetResource returns an object of a namespace.
+func (c *Client) GetResource(namespace, kind, name string) (*resource.Object, error) {
+	var object resource.Object
+
+	if err := c.do(http.MethodGet, path("/resources/%s/%s/%s", namespace, kind, name), nil, nil, &object); err != nil {
+		return nil, err
+	}
+
+	return &object, nil
+}
+
+// DeleteResource deletes an object from a namespace managed by blackbeard.
+func (c *Client) DeleteResource(namespace, kind, name string) error {
+	return c.do(http.MethodDelete, path("/resources/%s/%s/%s", namespace, kind, name), nil, nil, nil)
+}
+
+// Restart recreates the pods of a deployment or a statefulset of the given namespace.
+func (c *Client) Restart(namespace, kind, name string) error {
+	return c.do(http.MethodPost, path("/resources/%s/%s/%s/restart", namespace, kind, name), nil, nil, nil)
+}
@@ -0,0 +1,130 @@
+// Package errors defines the typed errors shared by every blackbeard layer.
+//
+// An error carries a Kind describing its category. The http package maps the Kind to a status code
+// and the cli maps it to an exit code. Context may be added on the way up using fmt.Errorf and the %w verb,
+// the Kind of the original error is preserved.
+package errors
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Kind is the category of an error.
+type Kind string
+
+const (
+	// Internal is used for unexpected errors. It is the kind of any error not created by this package.
+	Internal Kind = "Internal"
+	// NotFound is used when a requested object (inventory, namespace, resource) does not exist.
+	NotFound Kind = "NotFound"
+	// AlreadyExists is used when an object could not be created because it already exists.
+	AlreadyExists Kind = "AlreadyExists"
+	// Invalid is used when the input given by the user is not valid.
+	Invalid Kind = "Invalid"
+	// Conflict is used when the request conflicts with the current state of an object.
+	Conflict Kind = "Conflict"
+	// Forbidden is used when the action is not allowed.
+	Forbidden Kind = "Forbidden"
+	// Timeout is used when an action did not complete in time.
+	Timeout Kind = "Timeout"
+	// Upstream is used when a dependency, such as the kubernetes api or kubectl, failed.
+	Upstream Kind = "Upstream"
+)
+
+// Error is a typed error.
+// Message describes what failed, Err is the underlying error if any.
+// Details may contain any information useful to the caller, such as the name of the object in error.
+type Error struct {
+	Kind    Kind
+	Message string
+	Details map[string]interface{}
+	Err     error
+}
+
+// Error returns the error message
+func (e *Error) Error() string {
+	if e.Err == nil {
+		return e.Message
+	}
+
+	if e.Message == "" {
+		return e.Err.Error()
+	}
+
+	return fmt.Sprintf("%s: %s", e.Message, e.Err.Error())
+}
+
+// Unwrap returns the underlying error
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// New creates an error of the given kind.
+func New(kind Kind, format string, args ...interface{}) error {
+	return &Error{
+		Kind:    kind,
+		Message: fmt.Sprintf(format, args...),
+	}
+}
+
+// Wrap creates an error of the given kind wrapping err.
+// If err is nil, Wrap returns nil.
+func Wrap(err error, kind Kind, format string, args ...interface{}) error {
+	if err == nil {
+		return nil
+	}
+
+	return &Error{
+		Kind:    kind,
+		Message: fmt.Sprintf(format, args...),
+		Err:     err,
+	}
+}
+
+// WithDetail adds a detail to the outermost typed error of the chain.
+// If err is not a typed error, it is wrapped into an Internal one.
+func WithDetail(err error, key string, value interface{}) error {
+	if err == nil {
+		return nil
+	}
+
+	var e *Error
+	if !errors.As(err, &e) {
+		e = &Error{Kind: Internal, Err: err}
+		err = e
+	}
+
+	if e.Details == nil {
+		e.Details = make(map[string]interface{})
+	}
+	e.Details[key] = value
+
+	return err
+}
+
+// KindOf returns the kind of the outermost typed error of the chain.
+// Errors not created by this package are Internal.
+func KindOf(err error) Kind {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Kind
+	}
+
+	return Internal
+}
+
+// Is returns true if err is of the given kind.
+func Is(err error, kind Kind) bool {
+	return err != nil && KindOf(err) == kind
+}
+
+// DetailsOf returns the details of the outermost typed error of the chain.
+func DetailsOf(err error) map[string]interface{} {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Details
+	}
+
+	return nil
+}
@@ -0,0 +1,63 @@
+package errors_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Meetic/blackbeard/pkg/errors"
+)
+
+func TestKindOf(t *testing.T) {
+	err := errors.New(errors.NotFound, "inventory %s not found", "foo")
+
+	assert.Equal(t, errors.NotFound, errors.KindOf(err))
+	assert.Equal(t, "inventory foo not found", err.Error())
+}
+
+func TestKindOfWrappedWithContext(t *testing.T) {
+	err := fmt.Errorf("get status: %w", errors.New(errors.Forbidden, "namespace foo"))
+
+	assert.True(t, errors.Is(err, errors.Forbidden))
+	assert.Equal(t, "get status: namespace foo", err.Error())
+}
+
+func TestKindOfUntyped(t *testing.T) {
+	assert.Equal(t, errors.Internal, errors.KindOf(fmt.Errorf("boom")))
+	assert.False(t, errors.Is(nil, errors.Internal))
+}
+
+func TestWrap(t *testing.T) {
+	cause := fmt.Errorf("connection refused")
+	err := errors.Wrap(cause, errors.Upstream, "list deployments")
+
+	assert.Equal(t, "list deployments: connection refused", err.Error())
+	assert.ErrorIs(t, err, cause)
+	assert.Nil(t, errors.Wrap(nil, errors.Upstream, "list deployments"))
+}
+
+func TestWithDetail(t *testing.T) {
+	err := errors.WithDetail(errors.New(errors.NotFound, "not found"), "namespace", "foo")
+
+	assert.Equal(t, map[string]interface{}{"namespace": "foo"}, errors.DetailsOf(err))
+
+	untyped := errors.WithDetail(fmt.Errorf("boom"), "namespace", "foo")
+
+	assert.Equal(t, errors.Internal, errors.KindOf(untyped))
+	assert.Equal(t, "boom", untyped.Error())
+}
+
+func TestProblemRoundTrip(t *testing.T) {
+	err := errors.WithDetail(errors.New(errors.AlreadyExists, "already exists"), "namespace", "foo")
+
+	p := errors.ProblemOf(err)
+
+	assert.Equal(t, errors.Problem{
+		Code:    errors.AlreadyExists,
+		Message: "already exists",
+		Details: map[string]interface{}{"namespace": "foo"},
+	}, p)
+	assert.True(t, errors.Is(p.Err(), errors.AlreadyExists))
+	assert.Equal(t, errors.Internal, errors.KindOf(errors.Problem{Message: "boom"}.Err()))
+}
@@ -0,0 +1,31 @@
+package errors
+
+// Problem is the JSON document describing an error returned by the blackbeard REST api.
+type Problem struct {
+	Code    Kind                   `json:"code"`
+	Message string                 `json:"message"`
+	Details map[string]interface{} `json:"details,omitempty"`
+}
+
+// ProblemOf returns the Problem describing err.
+func ProblemOf(err error) Problem {
+	return Problem{
+		Code:    KindOf(err),
+		Message: err.Error(),
+		Details: DetailsOf(err),
+	}
+}
+
+// Err returns the typed error described by the Problem.
+func (p Problem) Err() error {
+	code := p.Code
+	if code == "" {
+		code = Internal
+	}
+
+	return &Error{
+		Kind:    code,
+		Message: p.Message,
+		Details: p.Details,
+	}
+}
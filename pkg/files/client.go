@@ -1,67 +1,113 @@
 package files
 
 import (
-	"fmt"
+	"context"
 	"os"
 	"path/filepath"
 
+	"github.com/Meetic/blackbeard/pkg/errors"
 	"github.com/Meetic/blackbeard/pkg/playbook"
+	"github.com/Meetic/blackbeard/pkg/resource"
 )
 
 const (
 	templateDir  = "templates"
 	configDir    = "configs"
 	inventoryDir = "inventories"
-	defaultFile  = "defaults.json"
+	defaultsName = "defaults"
+	profileFile  = "namespace.yaml"
 )
 
 type Client struct {
 	configs       playbook.ConfigRepository
 	inventories   playbook.InventoryRepository
 	playbooks     playbook.PlaybookRepository
+	profiles      resource.ProfileRepository
 	inventoryPath string
+	inventoryExt  string
 	configPath    string
 }
 
-func NewClient(wd string) (*Client, error) {
+// Option configures a Client.
+type Option func(*Client)
+
+// WithPlaybookRepository reads the templates and the defaults from the given playbook, a git repository for
+// instance, instead of the working dir. The working dir then only holds the inventories, the configs and
+// the namespace profiles.
+func WithPlaybookRepository(playbooks playbook.PlaybookRepository) Option {
+	return func(c *Client) {
+		c.playbooks = playbooks
+	}
+}
+
+func NewClient(wd string, opts ...Option) (*Client, error) {
 	if ok, _ := fileExists(wd); ok != true {
-		return &Client{}, fmt.Errorf("Your specified working dir does not exit : %s", wd)
+		return &Client{}, errors.New(errors.Invalid, "Your specified working dir does not exit : %s", wd)
 	}
 
 	templatePath := filepath.Join(wd, templateDir)
 	configPath := filepath.Join(wd, configDir)
 	inventoryPath := filepath.Join(wd, inventoryDir)
-	defaultsPath := filepath.Join(wd, defaultFile)
+	defaultsPath := defaultsFile(wd)
+
+	// inventories are written in the format of the defaults
+	inventoryExt := filepath.Ext(defaultsPath)
+
+	c := &Client{
+		configs:       NewConfigRepository(configPath),
+		inventories:   newInventoryRepository(inventoryPath, inventoryExt),
+		profiles:      NewProfileRepository(filepath.Join(wd, profileFile)),
+		inventoryPath: inventoryPath,
+		inventoryExt:  inventoryExt,
+		configPath:    configPath,
+	}
 
-	if ok, _ := fileExists(templatePath); ok != true {
-		return &Client{}, fmt.Errorf("A playbook must contains a `%s` dir. No one has been found.\n"+
-			"Please check the playbook or change the working directory using the --dir option.", templateDir)
+	for _, opt := range opts {
+		opt(c)
 	}
 
-	if ok, _ := fileExists(defaultsPath); ok != true {
-		return &Client{}, fmt.Errorf("Your working directory must contains a `%s` file.\n"+
-			"Please check the playbook or change the working directory using the --dir option.", defaultFile)
+	if c.playbooks == nil {
+		if ok, _ := fileExists(templatePath); ok != true {
+			return &Client{}, errors.New(errors.Invalid, "A playbook must contains a `%s` dir. No one has been found.\n"+
+				"Please check the playbook or change the working directory using the --dir option.", templateDir)
+		}
+
+		if ok, _ := fileExists(defaultsPath); ok != true {
+			return &Client{}, errors.New(errors.Invalid, "Your working directory must contains a `%s.json` or a `%s.yaml` file.\n"+
+				"Please check the playbook or change the working directory using the --dir option.", defaultsName, defaultsName)
+		}
+
+		playbooks := NewWatchedPlaybookRepository(templatePath, defaultsPath)
+		// a failed load is reported by the playbook status and returned when reading templates or defaults
+		_ = playbooks.Reload()
+
+		c.playbooks = playbooks
 	}
 
 	if ok, _ := fileExists(configPath); ok != true {
 		if err := os.Mkdir(configPath, 0755); err != nil {
-			return &Client{}, fmt.Errorf("Impossible to create the %s directory. Please check directory rights.", configDir)
+			return &Client{}, errors.Wrap(err, errors.Forbidden, "Impossible to create the %s directory. Please check directory rights.", configDir)
 		}
 	}
 
 	if ok, _ := fileExists(inventoryPath); ok != true {
 		if err := os.Mkdir(inventoryPath, 0755); err != nil {
-			return &Client{}, fmt.Errorf("Impossible to create the %s directory. Please check directory rights.", inventoryDir)
+			return &Client{}, errors.Wrap(err, errors.Forbidden, "Impossible to create the %s directory. Please check directory rights.", inventoryDir)
 		}
 	}
 
-	return &Client{
-		configs:       NewConfigRepository(configPath),
-		inventories:   NewInventoryRepository(inventoryPath),
-		playbooks:     NewPlaybookRepository(templatePath, defaultsPath),
-		inventoryPath: inventoryPath,
-		configPath:    configPath,
-	}, nil
+	return c, nil
+}
+
+// WatchPlaybook reloads the templates and the defaults each time they change in the working dir, until ctx is done.
+// It returns immediately if the playbook is not read from the working dir.
+func (c *Client) WatchPlaybook(ctx context.Context) error {
+	playbooks, ok := c.playbooks.(WatchedPlaybookRepository)
+	if !ok {
+		return nil
+	}
+
+	return playbooks.Watch(ctx)
 }
 
 func (c *Client) Configs() playbook.ConfigRepository {
@@ -76,11 +122,34 @@ func (c *Client) Playbooks() playbook.PlaybookRepository {
 	return c.playbooks
 }
 
+// Profiles returns the namespace profiles of the playbook
+func (c *Client) Profiles() resource.ProfileRepository {
+	return c.profiles
+}
+
 // InventoryPath returns the inventory path for the current playbook
 func (c *Client) InventoryPath() string {
 	return c.inventoryPath
 }
 
+// InventoryFile returns the path of the inventory file of a namespace, json or yaml
+func (c *Client) InventoryFile(namespace string) string {
+	return newInventoryRepository(c.inventoryPath, c.inventoryExt).path(namespace)
+}
+
+// defaultsFile returns the path of the defaults file of a playbook dir : defaults.json, defaults.yaml or defaults.yml.
+// The path of defaults.json is returned if none exists.
+func defaultsFile(dir string) string {
+	for _, ext := range []string{jsonExt, yamlExt, ymlExt} {
+		path := filepath.Join(dir, defaultsName+ext)
+		if ok, _ := fileExists(path); ok {
+			return path
+		}
+	}
+
+	return filepath.Join(dir, defaultsName+jsonExt)
+}
+
 // ConfigPath return the config path for the current playbook
 func (c *Client) ConfigPath() string {
 	return c.configPath
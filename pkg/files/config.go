@@ -1,10 +1,10 @@
 package files
 
 import (
-	"fmt"
 	"os"
 	"path/filepath"
 
+	"github.com/Meetic/blackbeard/pkg/errors"
 	"github.com/Meetic/blackbeard/pkg/playbook"
 )
 
@@ -34,7 +34,7 @@ func (cr *configs) Save(namespace string, configs []playbook.Config) error {
 	configDir := filepath.Join(cr.configPath, namespace)
 	if _, err := os.Stat(configDir); os.IsNotExist(err) {
 		if e := os.Mkdir(configDir, os.ModePerm); e != nil {
-			return fmt.Errorf("the configs dir '%s' could not be created : %s", configDir, e.Error())
+			return errors.Wrap(e, errors.Internal, "the configs dir '%s' could not be created", configDir)
 		}
 	}
 
@@ -64,6 +64,55 @@ func (cr *configs) Delete(namespace string) error {
 	return os.RemoveAll(filepath.Join(cr.configPath, namespace))
 }
 
+// List returns the namespaces having a config dir.
+// If the configs dir does not exist, List returns an empty list.
+func (cr *configs) List() ([]string, error) {
+	entries, err := os.ReadDir(cr.configPath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, errors.Internal, "unable to read the configs dir %s", cr.configPath)
+	}
+
+	var namespaces []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			namespaces = append(namespaces, entry.Name())
+		}
+	}
+
+	return namespaces, nil
+}
+
+// Get reads the config files of a namespace, sorted by name
+func (cr *configs) Get(namespace string) ([]playbook.Config, error) {
+	if !cr.exists(namespace) {
+		return nil, errors.New(errors.NotFound, "no config has been generated for namespace %s", namespace)
+	}
+
+	entries, err := os.ReadDir(cr.path(namespace))
+	if err != nil {
+		return nil, errors.Wrap(err, errors.Internal, "unable to read the configs of namespace %s", namespace)
+	}
+
+	var configs []playbook.Config
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(cr.path(namespace), entry.Name()))
+		if err != nil {
+			return nil, errors.Wrap(err, errors.Internal, "unable to read config %s of namespace %s", entry.Name(), namespace)
+		}
+
+		configs = append(configs, playbook.Config{Name: entry.Name(), Values: string(data)})
+	}
+
+	return configs, nil
+}
+
 // exists return true if a config dir for the given namespace already exist.
 // Else, it return false.
 func (cr *configs) exists(namespace string) bool {
@@ -0,0 +1,48 @@
+package files_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Meetic/blackbeard/pkg/errors"
+	"github.com/Meetic/blackbeard/pkg/files"
+	"github.com/Meetic/blackbeard/pkg/playbook"
+)
+
+func TestConfigs(t *testing.T) {
+	configs := files.NewConfigRepository(t.TempDir())
+
+	namespaces, err := configs.List()
+	assert.Nil(t, err)
+	assert.Empty(t, namespaces)
+
+	_, err = configs.Get("test")
+	assert.True(t, errors.Is(err, errors.NotFound))
+
+	saved := []playbook.Config{{Name: "02_service.yml", Values: "kind: Service"}, {Name: "01_deployment.yml", Values: "kind: Deployment"}}
+	assert.Nil(t, configs.Save("test", saved))
+
+	namespaces, err = configs.List()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"test"}, namespaces)
+
+	read, err := configs.Get("test")
+	assert.Nil(t, err)
+	assert.Equal(t, []playbook.Config{saved[1], saved[0]}, read)
+
+	assert.Nil(t, configs.Delete("test"))
+
+	namespaces, err = configs.List()
+	assert.Nil(t, err)
+	assert.Empty(t, namespaces)
+}
+
+func TestConfigsWithoutDir(t *testing.T) {
+	configs := files.NewConfigRepository(filepath.Join(t.TempDir(), "configs"))
+
+	namespaces, err := configs.List()
+	assert.Nil(t, err)
+	assert.Empty(t, namespaces)
+}
@@ -0,0 +1,388 @@
+package files
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/Meetic/blackbeard/pkg/errors"
+	"github.com/Meetic/blackbeard/pkg/playbook"
+)
+
+const (
+	gitMirrorDir  = "repo.git"
+	gitCommitsDir = "commits"
+)
+
+// GitPlaybookRepository is a PlaybookRepository reading the playbook from a git repository.
+// Refresh fetches the repository and switches to the last commit of the followed ref.
+type GitPlaybookRepository interface {
+	playbook.PlaybookRepository
+	Refresh() error
+}
+
+type gitPlaybooks struct {
+	url   string
+	ref   string
+	cache string
+
+	// git serializes the git commands run on the mirror
+	git sync.Mutex
+	// update serializes the checkouts and the removal of worktrees
+	update sync.Mutex
+
+	mu         sync.RWMutex
+	current    *gitCheckout
+	previous   *gitCheckout
+	pinned     map[string]*gitCheckout
+	fetchedAt  time.Time
+	refreshErr error
+	failedAt   time.Time
+}
+
+// gitCheckout is the playbook at a given commit, checked out in its own worktree
+type gitCheckout struct {
+	WatchedPlaybookRepository
+	repo     *gitPlaybooks
+	ref      string
+	commit   string
+	dir      string
+	loadedAt time.Time
+}
+
+// NewGitPlaybookRepository clones the repository located at url in the cache dir and returns the playbook
+// at the given ref. The repository is mirrored in the cache and each commit in use is checked out in its
+// own worktree, so the templates and defaults of a commit never change while they are read.
+// An error is returned if the ref does not exist or if the playbook at this ref is not valid.
+func NewGitPlaybookRepository(url, ref, cache string) (GitPlaybookRepository, error) {
+	if err := os.MkdirAll(filepath.Join(cache, gitCommitsDir), 0755); err != nil {
+		return nil, errors.Wrap(err, errors.Forbidden, "unable to create the playbook cache dir %s", cache)
+	}
+
+	repo := &gitPlaybooks{
+		url:    url,
+		ref:    ref,
+		cache:  cache,
+		pinned: make(map[string]*gitCheckout),
+	}
+
+	mirror := filepath.Join(cache, gitMirrorDir)
+
+	if ok, _ := fileExists(mirror); ok {
+		if _, err := runGit(mirror, "remote", "set-url", "origin", url); err != nil {
+			return nil, err
+		}
+	} else if _, err := runGit(cache, "clone", "--mirror", url, gitMirrorDir); err != nil {
+		return nil, err
+	}
+
+	if err := repo.Refresh(); err != nil {
+		return nil, err
+	}
+
+	return repo, nil
+}
+
+// Refresh fetches the repository and switches to the last commit of the followed ref.
+// The new templates and defaults are validated before being used : if they are not valid, an error is returned
+// and the playbook keeps using the previous commit.
+func (r *gitPlaybooks) Refresh() error {
+	err := r.refresh()
+
+	r.mu.Lock()
+	r.refreshErr = err
+	if err != nil {
+		r.failedAt = time.Now().UTC()
+	}
+	r.mu.Unlock()
+
+	return err
+}
+
+func (r *gitPlaybooks) refresh() error {
+	if _, err := r.runGit("fetch", "--prune", "origin"); err != nil {
+		return err
+	}
+
+	r.update.Lock()
+	defer r.update.Unlock()
+
+	r.mu.Lock()
+	r.fetchedAt = time.Now().UTC()
+	current := r.current
+	r.mu.Unlock()
+
+	commit, err := r.resolve(r.ref)
+	if err != nil {
+		return err
+	}
+
+	if current != nil && current.commit == commit {
+		return nil
+	}
+
+	checkout, err := r.checkout(r.ref, commit)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	r.previous, r.current = r.current, checkout
+	r.mu.Unlock()
+
+	logrus.WithFields(logrus.Fields{
+		"component": "playbook",
+		"ref":       r.ref,
+		"commit":    commit,
+	}).Info("playbook updated")
+
+	r.prune()
+
+	return nil
+}
+
+// GetTemplate returns the templates of the commit in use
+func (r *gitPlaybooks) GetTemplate() ([]playbook.ConfigTemplate, error) {
+	return r.head().GetTemplate()
+}
+
+// GetDefault returns the default inventory of the commit in use
+func (r *gitPlaybooks) GetDefault() (playbook.Inventory, error) {
+	return r.head().GetDefault()
+}
+
+// Version returns the repository url, the followed ref and the commit in use
+func (r *gitPlaybooks) Version() (playbook.Version, error) {
+	return r.head().Version()
+}
+
+// Status returns the commit in use and the error of the last refresh if it failed
+func (r *gitPlaybooks) Status() (playbook.Status, error) {
+	status, err := r.head().Status()
+	if err != nil {
+		return playbook.Status{}, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if r.refreshErr != nil {
+		failedAt := r.failedAt
+		status.Error = r.refreshErr.Error()
+		status.FailedAt = &failedAt
+	}
+
+	return status, nil
+}
+
+// At returns the playbook at the given ref, which can be a branch, a tag or a commit.
+// If ref is empty, At returns the commit in use, which does not change on the next refresh.
+// A branch is resolved against the last fetch of the repository.
+func (r *gitPlaybooks) At(ref string) (playbook.PlaybookRepository, error) {
+	if ref == "" {
+		return r.head(), nil
+	}
+
+	commit, err := r.resolve(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	r.update.Lock()
+	defer r.update.Unlock()
+
+	r.mu.RLock()
+	checkout, ok := r.pinned[ref]
+	r.mu.RUnlock()
+
+	if ok && checkout.commit == commit {
+		return checkout, nil
+	}
+
+	if checkout, err = r.checkout(ref, commit); err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	r.pinned[ref] = checkout
+	r.mu.Unlock()
+
+	r.prune()
+
+	return checkout, nil
+}
+
+func (r *gitPlaybooks) head() *gitCheckout {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.current
+}
+
+// resolve returns the commit of a ref
+func (r *gitPlaybooks) resolve(ref string) (string, error) {
+	commit, err := r.runGit("rev-parse", "--verify", "--quiet", ref+"^{commit}")
+	if err != nil {
+		return "", errors.New(errors.NotFound, "playbook ref %s not found in %s", ref, r.url)
+	}
+
+	return commit, nil
+}
+
+// checkout returns the playbook at the given commit, checking it out if needed.
+// The worktree is removed if the playbook is not valid.
+func (r *gitPlaybooks) checkout(ref, commit string) (*gitCheckout, error) {
+	dir := filepath.Join(r.cache, gitCommitsDir, commit)
+
+	if ok, _ := fileExists(dir); !ok {
+		if _, err := r.runGit("worktree", "add", "--detach", dir, commit); err != nil {
+			return nil, err
+		}
+	}
+
+	checkout := &gitCheckout{
+		WatchedPlaybookRepository: NewWatchedPlaybookRepository(filepath.Join(dir, templateDir), defaultsFile(dir)),
+		repo:                      r,
+		ref:                       ref,
+		commit:                    commit,
+		dir:                       dir,
+		loadedAt:                  time.Now().UTC(),
+	}
+
+	// the templates of a commit never change, so they are only parsed once
+	if err := checkout.Reload(); err != nil {
+		r.remove(dir)
+		return nil, errors.Wrap(err, errors.Invalid, "invalid playbook at %s@%s", ref, commit)
+	}
+
+	return checkout, nil
+}
+
+// prune removes the worktrees which are neither in use, nor the previous one, nor pinned.
+// The previous worktree is kept since configs may still be generated from it while switching.
+func (r *gitPlaybooks) prune() {
+	r.mu.RLock()
+	keep := make(map[string]bool)
+	for _, c := range append([]*gitCheckout{r.current, r.previous}, r.pinnedCheckouts()...) {
+		if c != nil {
+			keep[c.dir] = true
+		}
+	}
+	r.mu.RUnlock()
+
+	dirs, _ := filepath.Glob(filepath.Join(r.cache, gitCommitsDir, "*"))
+	for _, dir := range dirs {
+		if !keep[dir] {
+			r.remove(dir)
+		}
+	}
+}
+
+func (r *gitPlaybooks) pinnedCheckouts() []*gitCheckout {
+	var checkouts []*gitCheckout
+	for _, c := range r.pinned {
+		checkouts = append(checkouts, c)
+	}
+
+	return checkouts
+}
+
+// remove deletes a worktree. A failure is only logged since the worktree is removed again on the next prune.
+func (r *gitPlaybooks) remove(dir string) {
+	if err := os.RemoveAll(dir); err != nil {
+		logrus.WithFields(logrus.Fields{"component": "playbook", "dir": dir}).
+			Warnf("unable to remove playbook worktree : %v", err)
+	}
+
+	if _, err := r.runGit("worktree", "prune"); err != nil {
+		logrus.WithFields(logrus.Fields{"component": "playbook"}).Warn(err)
+	}
+}
+
+func (r *gitPlaybooks) runGit(args ...string) (string, error) {
+	r.git.Lock()
+	defer r.git.Unlock()
+
+	return runGit(filepath.Join(r.cache, gitMirrorDir), args...)
+}
+
+// Version returns the repository url, the ref and the commit of the checkout
+func (c *gitCheckout) Version() (playbook.Version, error) {
+	c.repo.mu.RLock()
+	fetchedAt := c.repo.fetchedAt
+	c.repo.mu.RUnlock()
+
+	return playbook.Version{
+		Source:    c.repo.url,
+		Ref:       c.ref,
+		Commit:    c.commit,
+		UpdatedAt: &fetchedAt,
+	}, nil
+}
+
+// Status returns the version of the checkout, which is always loaded
+func (c *gitCheckout) Status() (playbook.Status, error) {
+	v, err := c.Version()
+	if err != nil {
+		return playbook.Status{}, err
+	}
+
+	loadedAt := c.loadedAt
+
+	return playbook.Status{Version: v, Loaded: true, LoadedAt: &loadedAt}, nil
+}
+
+// At returns the checkout itself if ref is empty, or the playbook at the given ref otherwise
+func (c *gitCheckout) At(ref string) (playbook.PlaybookRepository, error) {
+	if ref == "" {
+		return c, nil
+	}
+
+	return c.repo.At(ref)
+}
+
+// runGit runs a git command in dir and returns its trimmed output.
+// Git never prompts for credentials, they must be given by the url or the git configuration.
+func runGit(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		return "", errors.Wrap(err, errors.Upstream, "git %s failed : %s", args[0], strings.TrimSpace(stderr.String()))
+	}
+
+	return strings.TrimSpace(string(out)), nil
+}
+
+// RefreshPlaybook refreshes the playbook every interval, until ctx is done.
+// A failing refresh is logged and the playbook keeps using the previous commit.
+func RefreshPlaybook(ctx context.Context, playbooks GitPlaybookRepository, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if err := playbooks.Refresh(); err != nil {
+			logrus.
+				WithFields(logrus.Fields{"component": "playbook"}).
+				Errorf("unable to refresh the playbook : %v", err)
+		}
+	}
+}
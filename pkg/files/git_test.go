@@ -0,0 +1,158 @@
+package files_test
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Meetic/blackbeard/pkg/errors"
+	"github.com/Meetic/blackbeard/pkg/files"
+)
+
+// gitRemote is a bare repository pushed from a work tree, standing for the remote playbook repository
+type gitRemote struct {
+	t    *testing.T
+	bare string
+	work string
+}
+
+func newGitRemote(t *testing.T) *gitRemote {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	dir := t.TempDir()
+	r := &gitRemote{t: t, bare: filepath.Join(dir, "playbook.git"), work: filepath.Join(dir, "work")}
+
+	r.git(dir, "init", "--bare", "-b", "main", r.bare)
+	r.git(dir, "init", "-b", "main", r.work)
+	r.git(r.work, "remote", "add", "origin", r.bare)
+
+	return r
+}
+
+func (r *gitRemote) git(dir string, args ...string) {
+	cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	require.NoError(r.t, err, string(out))
+}
+
+// push commits the given template and defaults and pushes them to the bare repository
+func (r *gitRemote) push(template, defaults string, tags ...string) {
+	require.NoError(r.t, os.MkdirAll(filepath.Join(r.work, "templates"), 0755))
+	require.NoError(r.t, os.WriteFile(filepath.Join(r.work, "templates", "app.yml.tpl"), []byte(template), 0644))
+	require.NoError(r.t, os.WriteFile(filepath.Join(r.work, "defaults.json"), []byte(defaults), 0644))
+
+	r.git(r.work, "add", "-A")
+	r.git(r.work, "commit", "-m", "update playbook")
+	for _, tag := range tags {
+		r.git(r.work, "tag", tag)
+	}
+	r.git(r.work, "push", "--tags", "origin", "main")
+}
+
+func TestGitPlaybookRefresh(t *testing.T) {
+	remote := newGitRemote(t)
+	remote.push("version: {{.Values.version}}", `{"namespace":"default","values":{"version":"1.0"}}`, "v1")
+
+	cache := t.TempDir()
+	r, err := files.NewGitPlaybookRepository(remote.bare, "main", cache)
+	require.NoError(t, err)
+
+	v1, err := r.Version()
+	assert.Nil(t, err)
+	assert.Equal(t, remote.bare, v1.Source)
+	assert.Equal(t, "main", v1.Ref)
+	assert.Len(t, v1.Commit, 40)
+	assert.NotNil(t, v1.UpdatedAt)
+
+	pinned, err := r.At("")
+	assert.Nil(t, err)
+
+	remote.push("version: {{.Values.version}}", `{"namespace":"default","values":{"version":"2.0"}}`)
+	assert.Nil(t, r.Refresh())
+
+	v2, err := r.Version()
+	assert.Nil(t, err)
+	assert.NotEqual(t, v1.Commit, v2.Commit)
+
+	def, err := r.GetDefault()
+	assert.Nil(t, err)
+	assert.Equal(t, "2.0", def.Values["version"])
+
+	// the playbook returned before the refresh keeps the templates and defaults of its commit
+	def, err = pinned.GetDefault()
+	assert.Nil(t, err)
+	assert.Equal(t, "1.0", def.Values["version"])
+
+	// a tag pins the playbook to its commit
+	tagged, err := r.At("v1")
+	assert.Nil(t, err)
+	def, err = tagged.GetDefault()
+	assert.Nil(t, err)
+	assert.Equal(t, "1.0", def.Values["version"])
+
+	version, err := tagged.Version()
+	assert.Nil(t, err)
+	assert.Equal(t, "v1", version.Ref)
+	assert.Equal(t, v1.Commit, version.Commit)
+
+	_, err = r.At("unknown")
+	assert.True(t, errors.Is(err, errors.NotFound))
+}
+
+func TestGitPlaybookRefreshKeepsLastValidCommit(t *testing.T) {
+	remote := newGitRemote(t)
+	remote.push("version: {{.Values.version}}", `{"namespace":"default","values":{"version":"1.0"}}`)
+
+	r, err := files.NewGitPlaybookRepository(remote.bare, "main", t.TempDir())
+	require.NoError(t, err)
+
+	v1, _ := r.Version()
+
+	remote.push("version: {{.Values.version", `{"namespace":"default","values":{"version":"2.0"}}`)
+
+	err = r.Refresh()
+	assert.True(t, errors.Is(err, errors.Invalid))
+
+	v, _ := r.Version()
+	assert.Equal(t, v1.Commit, v.Commit)
+
+	tpls, err := r.GetTemplate()
+	assert.Nil(t, err)
+	assert.Len(t, tpls, 1)
+
+	def, err := r.GetDefault()
+	assert.Nil(t, err)
+	assert.Equal(t, "1.0", def.Values["version"])
+}
+
+func TestGitPlaybookNotFound(t *testing.T) {
+	remote := newGitRemote(t)
+	remote.push("version: 1", `{"namespace":"default"}`)
+
+	_, err := files.NewGitPlaybookRepository(remote.bare, "unknown", t.TempDir())
+	assert.True(t, errors.Is(err, errors.NotFound))
+}
+
+func TestGitPlaybookReusesCache(t *testing.T) {
+	remote := newGitRemote(t)
+	remote.push("version: 1", `{"namespace":"default"}`)
+
+	cache := t.TempDir()
+
+	_, err := files.NewGitPlaybookRepository(remote.bare, "main", cache)
+	require.NoError(t, err)
+
+	r, err := files.NewGitPlaybookRepository(remote.bare, "main", cache)
+	require.NoError(t, err)
+
+	tpls, err := r.GetTemplate()
+	assert.Nil(t, err)
+	assert.Len(t, tpls, 1)
+}
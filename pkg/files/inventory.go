@@ -1,28 +1,41 @@
 package files
 
 import (
-	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"sort"
 
+	"github.com/Meetic/blackbeard/pkg/errors"
 	"github.com/Meetic/blackbeard/pkg/playbook"
 )
 
 const (
-	inventoryFileSuffix = "inventory.json"
+	inventoryFileSuffix = "inventory"
 )
 
+// inventoryExts are the extensions of the inventory files, json or yaml
+var inventoryExts = []string{jsonExt, yamlExt, ymlExt}
+
 type inventories struct {
 	inventoryPath string
+	ext           string
 }
 
 // NewInventoryRepository returns a InventoryRepository
-// The parameter is the directory where are stored the inventories
+// The parameter is the directory where are stored the inventories.
+// Inventories may be written in json ({{namespace}}_inventory.json) or in yaml ({{namespace}}_inventory.yaml),
+// new inventories are written in json.
 func NewInventoryRepository(inventoryPath string) playbook.InventoryRepository {
+	return newInventoryRepository(inventoryPath, jsonExt)
+}
+
+// newInventoryRepository returns a InventoryRepository writing new inventories in the format of the given extension
+func newInventoryRepository(inventoryPath, ext string) *inventories {
 	return &inventories{
 		inventoryPath: inventoryPath,
+		ext:           ext,
 	}
 }
 
@@ -45,8 +58,14 @@ func (ir *inventories) Create(inventory playbook.Inventory) error {
 		return playbook.NewErrorInventoryAlreadyExist(inventory.Namespace)
 	}
 
-	j, _ := json.MarshalIndent(inventory, "", "    ")
-	return ioutil.WriteFile(ir.path(inventory.Namespace), j, 0644)
+	path := ir.path(inventory.Namespace)
+
+	data, err := marshal(path, nil, inventory)
+	if err != nil {
+		return errors.Wrap(err, errors.Internal, "unable to encode the inventory of %s", inventory.Namespace)
+	}
+
+	return ioutil.WriteFile(path, data, 0644)
 }
 
 // Delete remove an inventory file.
@@ -69,15 +88,25 @@ func (ir *inventories) Update(namespace string, inv playbook.Inventory) error {
 		if ir.Exists(inv.Namespace) {
 			return playbook.NewErrorInventoryAlreadyExist(inv.Namespace)
 		}
-		err := os.Rename(ir.path(namespace), ir.path(inv.Namespace))
+		// the renamed inventory keeps its format
+		old := ir.path(namespace)
+		err := os.Rename(old, ir.file(inv.Namespace, filepath.Ext(old)))
 		if err != nil {
 			return err
 		}
 	}
 
-	iJSON, _ := json.MarshalIndent(inv, "", "    ")
+	path := ir.path(inv.Namespace)
+
+	// comments of yaml inventories are kept
+	previous, _ := ioutil.ReadFile(path)
+
+	data, err := marshal(path, previous, inv)
+	if err != nil {
+		return errors.Wrap(err, errors.Internal, "unable to encode the inventory of %s", inv.Namespace)
+	}
 
-	err := ioutil.WriteFile(ir.path(inv.Namespace), iJSON, 0644)
+	err = ioutil.WriteFile(path, data, 0644)
 
 	if err != nil {
 		return err
@@ -91,7 +120,12 @@ func (ir *inventories) Update(namespace string, inv playbook.Inventory) error {
 func (ir *inventories) List() ([]playbook.Inventory, error) {
 	var inventories []playbook.Inventory
 
-	invFiles, _ := filepath.Glob(filepath.Join(ir.inventoryPath, fmt.Sprintf("*_%s", inventoryFileSuffix)))
+	var invFiles []string
+	for _, ext := range inventoryExts {
+		files, _ := filepath.Glob(filepath.Join(ir.inventoryPath, fmt.Sprintf("*_%s%s", inventoryFileSuffix, ext)))
+		invFiles = append(invFiles, files...)
+	}
+	sort.Strings(invFiles)
 
 	for _, invFile := range invFiles {
 		inv, err := ir.read(invFile)
@@ -113,7 +147,14 @@ func (ir *inventories) read(path string) (playbook.Inventory, error) {
 		return inv, err
 	}
 
-	json.Unmarshal(raw, &inv)
+	if err := unmarshal(path, raw, &inv); err != nil {
+		format := "json"
+		if isYAML(path) {
+			format = "yaml"
+		}
+		return inv, errors.Wrap(err, errors.Invalid, "the inventory %s is not a valid %s file", filepath.Base(path), format)
+	}
+
 	return inv, nil
 }
 
@@ -128,7 +169,19 @@ func (ir *inventories) Exists(namespace string) bool {
 	return false
 }
 
-// path return the inventory file path of a given namespace
+// path return the inventory file path of a given namespace : the existing json or yaml file if any, or the path
+// of a new inventory file otherwise
 func (ir *inventories) path(namespace string) string {
-	return filepath.Join(ir.inventoryPath, fmt.Sprintf("%s_%s", namespace, inventoryFileSuffix))
+	for _, ext := range inventoryExts {
+		path := ir.file(namespace, ext)
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+
+	return ir.file(namespace, ir.ext)
+}
+
+func (ir *inventories) file(namespace, ext string) string {
+	return filepath.Join(ir.inventoryPath, fmt.Sprintf("%s_%s%s", namespace, inventoryFileSuffix, ext))
 }
@@ -0,0 +1,120 @@
+package files_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Meetic/blackbeard/pkg/files"
+	"github.com/Meetic/blackbeard/pkg/playbook"
+)
+
+const yamlInventory = `# inventory of the search team
+namespace: test
+values:
+  # versions of the applications
+  api:
+    version: "1.0" # pinned until the next release
+    replicas: 1
+  front:
+    version: "2.0"
+`
+
+func TestYAMLInventoryKeepsComments(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "test_inventory.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(yamlInventory), 0644))
+
+	r := files.NewInventoryRepository(dir)
+
+	assert.True(t, r.Exists("test"))
+
+	inv, err := r.Get("test")
+	assert.Nil(t, err)
+	assert.Equal(t, "1.0", inv.Values["api"].(map[string]interface{})["version"])
+	assert.Equal(t, float64(1), inv.Values["api"].(map[string]interface{})["replicas"])
+
+	inv.Values["api"].(map[string]interface{})["version"] = "1.1"
+	inv.Values["cache"] = map[string]interface{}{"enabled": true}
+	delete(inv.Values, "front")
+
+	assert.Nil(t, r.Update("test", inv))
+
+	data, err := os.ReadFile(path)
+	assert.Nil(t, err)
+	assert.Equal(t, `# inventory of the search team
+namespace: test
+values:
+  # versions of the applications
+  api:
+    version: "1.1" # pinned until the next release
+    replicas: 1
+  cache:
+    enabled: true
+`, string(data))
+
+	_, err = os.Stat(filepath.Join(dir, "test_inventory.json"))
+	assert.True(t, os.IsNotExist(err))
+
+	invs, err := r.List()
+	assert.Nil(t, err)
+	assert.Len(t, invs, 1)
+}
+
+func TestYAMLInventoryRename(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "test_inventory.yaml"), []byte(yamlInventory), 0644))
+
+	r := files.NewInventoryRepository(dir)
+
+	inv, _ := r.Get("test")
+	inv.Namespace = "renamed"
+	assert.Nil(t, r.Update("test", inv))
+
+	assert.False(t, r.Exists("test"))
+	_, err := os.Stat(filepath.Join(dir, "renamed_inventory.yaml"))
+	assert.Nil(t, err)
+
+	assert.Nil(t, r.Delete("renamed"))
+	assert.False(t, r.Exists("renamed"))
+}
+
+func TestJSONAndYAMLInventories(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "test_inventory.yaml"), []byte(yamlInventory), 0644))
+
+	r := files.NewInventoryRepository(dir)
+	assert.Nil(t, r.Create(playbook.Inventory{Namespace: "other", Values: map[string]interface{}{"debug": true}}))
+
+	_, err := os.Stat(filepath.Join(dir, "other_inventory.json"))
+	assert.Nil(t, err)
+
+	invs, err := r.List()
+	assert.Nil(t, err)
+	assert.Len(t, invs, 2)
+}
+
+func TestYAMLDefaults(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "templates"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "templates", "app.yml.tpl"), []byte("version: {{.Values.version}}"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "defaults.yaml"), []byte("namespace: default\nvalues:\n  version: \"1.0\"\n"), 0644))
+
+	c, err := files.NewClient(dir)
+	require.NoError(t, err)
+
+	def, err := c.Playbooks().GetDefault()
+	assert.Nil(t, err)
+	assert.Equal(t, "1.0", def.Values["version"])
+
+	// new inventories are written in the format of the defaults
+	assert.Nil(t, c.Inventories().Create(playbook.Inventory{Namespace: "test", Values: def.Values}))
+	assert.Equal(t, filepath.Join(dir, "inventories", "test_inventory.yaml"), c.InventoryFile("test"))
+
+	inv, err := c.Inventories().Get("test")
+	assert.Nil(t, err)
+	assert.Equal(t, "1.0", inv.Values["version"])
+}
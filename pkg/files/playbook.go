@@ -1,13 +1,16 @@
 package files
 
 import (
-	"encoding/json"
 	"fmt"
+	"io/fs"
 	"io/ioutil"
 	"path/filepath"
+	"strings"
 	"text/template"
+	"time"
 
 	"github.com/Masterminds/sprig"
+	"github.com/Meetic/blackbeard/pkg/errors"
 	"github.com/Meetic/blackbeard/pkg/playbook"
 	"github.com/sirupsen/logrus"
 )
@@ -24,14 +27,21 @@ func NewPlaybookRepository(templatePath, defaultsPath string) playbook.PlaybookR
 	}
 }
 
-// GetTemplate returns the templates from the playbook
+// GetTemplate returns the templates from the playbook.
+// The template files are all read at once, so that the templates and the files returned by their getFile
+// func come from the same version of the playbook, even if it is being updated.
 func (p *playbooks) GetTemplate() ([]playbook.ConfigTemplate, error) {
 
 	// Get templates list
 	templates, _ := filepath.Glob(fmt.Sprintf("%s/*%s", p.templatePath, tplSuffix))
 
 	if templates == nil {
-		return nil, fmt.Errorf("no template files found in directory %s", p.templatePath)
+		return nil, errors.New(errors.NotFound, "no template files found in directory %s", p.templatePath)
+	}
+
+	files, err := p.readTemplateFiles()
+	if err != nil {
+		return nil, err
 	}
 
 	var cfgTpl []playbook.ConfigTemplate
@@ -39,11 +49,16 @@ func (p *playbooks) GetTemplate() ([]playbook.ConfigTemplate, error) {
 	for _, templ := range templates {
 		tpl := template.New(filepath.Base(templ))
 
-		p.initFuncMap(tpl) // add custom template functions
+		p.initFuncMap(tpl, files) // add custom template functions
 
-		tpl, err := tpl.ParseFiles(templ)
+		content, ok := files[filepath.Base(templ)]
+		if !ok {
+			return nil, errors.New(errors.Invalid, "template file %s has been removed while reading the playbook", templ)
+		}
+
+		tpl, err := tpl.Parse(content)
 		if err != nil {
-			return nil, fmt.Errorf("template cannot parse files: %v", err)
+			return nil, errors.Wrap(err, errors.Invalid, "template cannot parse files")
 		}
 
 		// create config file from tpl by removing the .tpl extension
@@ -61,7 +76,75 @@ func (p *playbooks) GetTemplate() ([]playbook.ConfigTemplate, error) {
 	return cfgTpl, nil
 }
 
-// GetDefault reads the default inventory file and return an Inventory where namespace is set to "default"
+// readTemplateFiles returns the content of the template files of the playbook, including the ones of its sub
+// directories, by their path relative to the template directory. Hidden directories are skipped.
+func (p *playbooks) readTemplateFiles() (map[string]string, error) {
+	files := make(map[string]string)
+
+	err := filepath.WalkDir(p.templatePath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() {
+			if path != p.templatePath && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if !strings.HasSuffix(path, tplSuffix) {
+			return nil
+		}
+
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(p.templatePath, path)
+		if err != nil {
+			return err
+		}
+
+		files[rel] = string(data)
+
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, errors.Invalid, "unable to read the template files")
+	}
+
+	return files, nil
+}
+
+// Version returns the directory of the playbook
+func (p *playbooks) Version() (playbook.Version, error) {
+	return playbook.Version{Source: filepath.Dir(p.templatePath)}, nil
+}
+
+// Status reads the playbook and returns whether its templates and defaults are valid
+func (p *playbooks) Status() (playbook.Status, error) {
+	v, _ := p.Version()
+	now := time.Now().UTC()
+
+	if err := validatePlaybook(p); err != nil {
+		return playbook.Status{Version: v, Error: err.Error(), FailedAt: &now}, nil
+	}
+
+	return playbook.Status{Version: v, Loaded: true, LoadedAt: &now}, nil
+}
+
+// At returns the playbook itself if ref is empty. A playbook directory has no git reference.
+func (p *playbooks) At(ref string) (playbook.PlaybookRepository, error) {
+	if ref != "" {
+		return nil, errors.New(errors.Invalid, "unable to use the playbook ref %s : playbook refs are only supported when the playbook is read from git", ref)
+	}
+
+	return p, nil
+}
+
+// GetDefault reads the default inventory file, in json or yaml, and return an Inventory where namespace is set to "default"
 func (p *playbooks) GetDefault() (playbook.Inventory, error) {
 
 	defaults, err := ioutil.ReadFile(p.defaultsPath)
@@ -72,14 +155,27 @@ func (p *playbooks) GetDefault() (playbook.Inventory, error) {
 
 	var inventory playbook.Inventory
 
-	if err := json.Unmarshal(defaults, &inventory); err != nil {
+	if err := unmarshal(p.defaultsPath, defaults, &inventory); err != nil {
 		return playbook.Inventory{}, playbook.NewErrorReadingDefaultsFile(err)
 	}
 
 	return inventory, nil
 }
 
-func (p *playbooks) initFuncMap(t *template.Template) {
+// validatePlaybook checks the templates of a playbook can be parsed and its defaults can be read
+func validatePlaybook(p playbook.PlaybookRepository) error {
+	if _, err := p.GetTemplate(); err != nil {
+		return err
+	}
+
+	_, err := p.GetDefault()
+
+	return err
+}
+
+// initFuncMap adds the custom template functions. getFile returns the content of a template file read along
+// with the templates, so a file outside of the template directory cannot be used.
+func (p *playbooks) initFuncMap(t *template.Template, files map[string]string) {
 	f := sprig.TxtFuncMap()
 	delete(f, "env")
 	delete(f, "expandenv")
@@ -87,11 +183,11 @@ func (p *playbooks) initFuncMap(t *template.Template) {
 	funcMap := make(template.FuncMap, 0)
 
 	funcMap["getFile"] = func(filename string) string {
-		data, err := ioutil.ReadFile(fmt.Sprintf("%s/%s%s", p.templatePath, filename, tplSuffix))
-		if err != nil {
-			logrus.Fatal(fmt.Errorf("template getFile func: %v", err))
+		data, ok := files[filepath.Clean(filename+tplSuffix)]
+		if !ok {
+			logrus.Fatal(fmt.Errorf("template getFile func: no template file %s%s in %s", filename, tplSuffix, p.templatePath))
 		}
-		return string(data)
+		return data
 	}
 
 	for k, v := range funcMap {
@@ -0,0 +1,186 @@
+package files
+
+import (
+	"context"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/sirupsen/logrus"
+
+	"github.com/Meetic/blackbeard/pkg/errors"
+	"github.com/Meetic/blackbeard/pkg/playbook"
+)
+
+// reloadDelay is the time to wait after the last change of the playbook before reloading it,
+// since editors and git checkouts usually write several files in a row.
+const reloadDelay = 200 * time.Millisecond
+
+// WatchedPlaybookRepository is a PlaybookRepository parsing the templates and reading the defaults of a playbook
+// dir once. Reload parses them again and Watch reloads them each time the dir changes.
+type WatchedPlaybookRepository interface {
+	playbook.PlaybookRepository
+	Reload() error
+	Watch(ctx context.Context) error
+}
+
+type watchedPlaybooks struct {
+	dir *playbooks
+
+	mu        sync.RWMutex
+	templates []playbook.ConfigTemplate
+	defaults  playbook.Inventory
+	err       error
+	loadedAt  time.Time
+	failedAt  time.Time
+}
+
+// NewWatchedPlaybookRepository returns a playbook reading its templates and defaults from the given paths.
+// Nothing is read before the first call to Reload.
+func NewWatchedPlaybookRepository(templatePath, defaultsPath string) WatchedPlaybookRepository {
+	return &watchedPlaybooks{
+		dir: &playbooks{templatePath, defaultsPath},
+		err: errors.New(errors.Internal, "the playbook has not been loaded"),
+	}
+}
+
+// Reload parses the templates and reads the defaults of the playbook. They are only used if both are valid :
+// otherwise, the error is returned and the previous templates and defaults are kept.
+func (p *watchedPlaybooks) Reload() error {
+	templates, err := p.dir.GetTemplate()
+	if err == nil {
+		var defaults playbook.Inventory
+		if defaults, err = p.dir.GetDefault(); err == nil {
+			p.mu.Lock()
+			p.templates, p.defaults, p.err = templates, defaults, nil
+			p.loadedAt = time.Now().UTC()
+			p.mu.Unlock()
+
+			return nil
+		}
+	}
+
+	p.mu.Lock()
+	p.err = err
+	p.failedAt = time.Now().UTC()
+	p.mu.Unlock()
+
+	return err
+}
+
+// GetTemplate returns the templates of the last valid load
+func (p *watchedPlaybooks) GetTemplate() ([]playbook.ConfigTemplate, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.templates == nil {
+		return nil, p.err
+	}
+
+	return p.templates, nil
+}
+
+// GetDefault returns the default inventory of the last valid load.
+// Its values are copied, so that the inventories created from it do not share them.
+func (p *watchedPlaybooks) GetDefault() (playbook.Inventory, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.templates == nil {
+		return playbook.Inventory{}, p.err
+	}
+
+	inv := p.defaults
+	inv.Values = playbook.CopyValues(p.defaults.Values)
+
+	return inv, nil
+}
+
+// Version returns the directory of the playbook
+func (p *watchedPlaybooks) Version() (playbook.Version, error) {
+	return p.dir.Version()
+}
+
+// Status returns the time of the last valid load, and the error of the last load if it failed
+func (p *watchedPlaybooks) Status() (playbook.Status, error) {
+	v, _ := p.Version()
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	status := playbook.Status{Version: v, Loaded: p.templates != nil}
+
+	if !p.loadedAt.IsZero() {
+		loadedAt := p.loadedAt
+		status.LoadedAt = &loadedAt
+	}
+
+	if p.err != nil && !p.failedAt.IsZero() {
+		failedAt := p.failedAt
+		status.Error = p.err.Error()
+		status.FailedAt = &failedAt
+	}
+
+	return status, nil
+}
+
+// At returns the playbook itself if ref is empty. A playbook directory has no git reference.
+func (p *watchedPlaybooks) At(ref string) (playbook.PlaybookRepository, error) {
+	if _, err := p.dir.At(ref); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// Watch reloads the playbook each time a template or the defaults file changes, until ctx is done.
+// A failing reload is logged and the previous templates and defaults are kept.
+func (p *watchedPlaybooks) Watch(ctx context.Context) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return errors.Wrap(err, errors.Internal, "unable to watch the playbook")
+	}
+	defer watcher.Close()
+
+	for _, dir := range []string{p.dir.templatePath, filepath.Dir(p.dir.defaultsPath)} {
+		if err := watcher.Add(dir); err != nil {
+			return errors.Wrap(err, errors.Internal, "unable to watch the playbook dir %s", dir)
+		}
+	}
+
+	reload := time.NewTimer(reloadDelay)
+	reload.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if p.isPlaybookFile(event.Name) {
+				reload.Reset(reloadDelay)
+			}
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			logrus.WithFields(logrus.Fields{"component": "playbook"}).Warnf("error while watching the playbook : %v", err)
+		case <-reload.C:
+			if err := p.Reload(); err != nil {
+				logrus.WithFields(logrus.Fields{"component": "playbook"}).
+					Errorf("unable to reload the playbook, the previous version is kept : %v", err)
+				continue
+			}
+			logrus.WithFields(logrus.Fields{"component": "playbook"}).Info("playbook reloaded")
+		}
+	}
+}
+
+// isPlaybookFile returns true if the file is a template or the defaults file
+func (p *watchedPlaybooks) isPlaybookFile(name string) bool {
+	return filepath.Clean(name) == filepath.Clean(p.dir.defaultsPath) ||
+		filepath.Dir(name) == filepath.Clean(p.dir.templatePath)
+}
@@ -0,0 +1,144 @@
+package files_test
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Meetic/blackbeard/pkg/files"
+)
+
+func writePlaybook(t *testing.T, dir, template, defaults string) {
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "templates"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "templates", "app.yml.tpl"), []byte(template), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "defaults.json"), []byte(defaults), 0644))
+}
+
+func TestWatchedPlaybookReload(t *testing.T) {
+	dir := t.TempDir()
+	writePlaybook(t, dir, "version: {{.Values.version}}", `{"namespace":"default","values":{"version":"1.0"}}`)
+
+	r := files.NewWatchedPlaybookRepository(filepath.Join(dir, "templates"), filepath.Join(dir, "defaults.json"))
+
+	_, err := r.GetTemplate()
+	assert.NotNil(t, err)
+
+	status, _ := r.Status()
+	assert.False(t, status.Loaded)
+
+	assert.Nil(t, r.Reload())
+
+	tpls, err := r.GetTemplate()
+	assert.Nil(t, err)
+	assert.Len(t, tpls, 1)
+
+	// a broken template keeps the previous version in use
+	writePlaybook(t, dir, "version: {{.Values.version", `{"namespace":"default","values":{"version":"2.0"}}`)
+	assert.NotNil(t, r.Reload())
+
+	def, err := r.GetDefault()
+	assert.Nil(t, err)
+	assert.Equal(t, "1.0", def.Values["version"])
+
+	status, _ = r.Status()
+	assert.True(t, status.Loaded)
+	assert.NotNil(t, status.LoadedAt)
+	assert.NotEmpty(t, status.Error)
+	assert.NotNil(t, status.FailedAt)
+	assert.Equal(t, dir, status.Version.Source)
+
+	writePlaybook(t, dir, "version: {{.Values.version}}", `{"namespace":"default","values":{"version":"2.0"}}`)
+	assert.Nil(t, r.Reload())
+
+	def, _ = r.GetDefault()
+	assert.Equal(t, "2.0", def.Values["version"])
+
+	status, _ = r.Status()
+	assert.Empty(t, status.Error)
+}
+
+func TestWatchedPlaybookDefaultsAreCopied(t *testing.T) {
+	dir := t.TempDir()
+	writePlaybook(t, dir, "version: 1", `{"namespace":"default","values":{"app":{"version":"1.0"}}}`)
+
+	r := files.NewWatchedPlaybookRepository(filepath.Join(dir, "templates"), filepath.Join(dir, "defaults.json"))
+	assert.Nil(t, r.Reload())
+
+	def, _ := r.GetDefault()
+	def.Values["app"].(map[string]interface{})["version"] = "2.0"
+
+	def, _ = r.GetDefault()
+	assert.Equal(t, "1.0", def.Values["app"].(map[string]interface{})["version"])
+}
+
+func TestWatchedPlaybookGetFileSnapshot(t *testing.T) {
+	dir := t.TempDir()
+	writePlaybook(t, dir, `script: {{ getFile "files/init.sh" }}`, `{"namespace":"default","values":{}}`)
+
+	script := filepath.Join(dir, "templates", "files", "init.sh.tpl")
+	require.NoError(t, os.MkdirAll(filepath.Dir(script), 0755))
+	require.NoError(t, os.WriteFile(script, []byte("v1"), 0644))
+
+	r := files.NewWatchedPlaybookRepository(filepath.Join(dir, "templates"), filepath.Join(dir, "defaults.json"))
+	assert.Nil(t, r.Reload())
+
+	// a checkout in progress updates the file used by the template, the playbook is not reloaded yet
+	require.NoError(t, os.WriteFile(script, []byte("v2"), 0644))
+
+	tpls, err := r.GetTemplate()
+	assert.Nil(t, err)
+
+	var out bytes.Buffer
+	assert.Nil(t, tpls[0].Template.Execute(&out, nil))
+	assert.Equal(t, "script: v1", out.String())
+
+	assert.Nil(t, r.Reload())
+
+	tpls, _ = r.GetTemplate()
+	out.Reset()
+	assert.Nil(t, tpls[0].Template.Execute(&out, nil))
+	assert.Equal(t, "script: v2", out.String())
+}
+
+func TestWatchedPlaybookWatch(t *testing.T) {
+	dir := t.TempDir()
+	writePlaybook(t, dir, "version: {{.Values.version}}", `{"namespace":"default","values":{"version":"1.0"}}`)
+
+	r := files.NewWatchedPlaybookRepository(filepath.Join(dir, "templates"), filepath.Join(dir, "defaults.json"))
+	assert.Nil(t, r.Reload())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- r.Watch(ctx)
+	}()
+
+	// leave some time to the watcher to start
+	time.Sleep(100 * time.Millisecond)
+
+	writePlaybook(t, dir, "version: {{.Values.version}}", `{"namespace":"default","values":{"version":"2.0"}}`)
+
+	assert.Eventually(t, func() bool {
+		def, _ := r.GetDefault()
+		return def.Values["version"] == "2.0"
+	}, 5*time.Second, 50*time.Millisecond)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "templates", "app.yml.tpl"), []byte("version: {{"), 0644))
+
+	assert.Eventually(t, func() bool {
+		status, _ := r.Status()
+		return status.Error != ""
+	}, 5*time.Second, 50*time.Millisecond)
+
+	def, _ := r.GetDefault()
+	assert.Equal(t, "2.0", def.Values["version"])
+
+	cancel()
+	assert.Nil(t, <-done)
+}
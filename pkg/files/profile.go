@@ -0,0 +1,91 @@
+package files
+
+import (
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+
+	"github.com/Meetic/blackbeard/pkg/errors"
+	"github.com/Meetic/blackbeard/pkg/resource"
+)
+
+// namespaceFile describes the namespace.yaml file of a playbook.
+// Default is the name of the profile used when an inventory does not choose one.
+// Labels and annotations are set on every namespace created using the playbook.
+type namespaceFile struct {
+	resource.NamespaceMetadata
+	Default  string                               `json:"default"`
+	Profiles map[string]resource.NamespaceProfile `json:"profiles"`
+}
+
+type profiles struct {
+	path string
+}
+
+// NewProfileRepository returns a ProfileRepository reading the profiles of the given namespace.yaml file.
+// A playbook without namespace.yaml file has no profile.
+func NewProfileRepository(path string) resource.ProfileRepository {
+	return &profiles{path: path}
+}
+
+// Get returns a profile of the namespace.yaml file, or its default profile if name is empty
+func (p *profiles) Get(name string) (*resource.NamespaceProfile, error) {
+	file, err := p.read()
+	if err != nil {
+		return nil, err
+	}
+
+	if name == "" {
+		name = file.Default
+	}
+
+	if name == "" {
+		return nil, nil
+	}
+
+	profile, ok := file.Profiles[name]
+	if !ok {
+		var names []string
+		for n := range file.Profiles {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+
+		return nil, errors.New(errors.Invalid, "unknown profile %s, available profiles : %s", name, strings.Join(names, ", "))
+	}
+
+	profile.Name = name
+
+	return &profile, nil
+}
+
+// Metadata returns the labels and annotations of the namespace.yaml file
+func (p *profiles) Metadata() (resource.NamespaceMetadata, error) {
+	file, err := p.read()
+	if err != nil {
+		return resource.NamespaceMetadata{}, err
+	}
+
+	return file.NamespaceMetadata, nil
+}
+
+func (p *profiles) read() (namespaceFile, error) {
+	var file namespaceFile
+
+	data, err := ioutil.ReadFile(p.path)
+	if os.IsNotExist(err) {
+		return file, nil
+	}
+	if err != nil {
+		return file, errors.Wrap(err, errors.Internal, "unable to read %s", p.path)
+	}
+
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return file, errors.Wrap(err, errors.Invalid, "invalid namespace profiles in %s", p.path)
+	}
+
+	return file, nil
+}
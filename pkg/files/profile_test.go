@@ -0,0 +1,90 @@
+package files_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Meetic/blackbeard/pkg/errors"
+	"github.com/Meetic/blackbeard/pkg/files"
+	"github.com/Meetic/blackbeard/pkg/resource"
+)
+
+const namespaceYaml = `
+labels:
+  team: dating
+annotations:
+  blackbeard.io/owner: qa
+default: small
+profiles:
+  small:
+    quota:
+      pods: "20"
+      requests.cpu: "2"
+    limits:
+      default:
+        memory: 512Mi
+  large:
+    quota:
+      pods: "100"
+    networkPolicies:
+      - name: same-namespace
+        spec:
+          podSelector: {}
+`
+
+func newProfileRepository(t *testing.T) resource.ProfileRepository {
+	path := filepath.Join(t.TempDir(), "namespace.yaml")
+	assert.Nil(t, os.WriteFile(path, []byte(namespaceYaml), 0644))
+
+	return files.NewProfileRepository(path)
+}
+
+func TestGetProfile(t *testing.T) {
+	profiles := newProfileRepository(t)
+
+	small, err := profiles.Get("")
+	assert.Nil(t, err)
+	assert.Equal(t, &resource.NamespaceProfile{
+		Name:   "small",
+		Quota:  map[string]string{"pods": "20", "requests.cpu": "2"},
+		Limits: &resource.ContainerLimits{Default: map[string]string{"memory": "512Mi"}},
+	}, small)
+
+	large, err := profiles.Get("large")
+	assert.Nil(t, err)
+	assert.Equal(t, "large", large.Name)
+	assert.Equal(t, []resource.NetworkPolicy{
+		{Name: "same-namespace", Spec: map[string]interface{}{"podSelector": map[string]interface{}{}}},
+	}, large.NetworkPolicies)
+}
+
+func TestGetProfileUnknown(t *testing.T) {
+	_, err := newProfileRepository(t).Get("huge")
+
+	assert.True(t, errors.Is(err, errors.Invalid))
+	assert.Contains(t, err.Error(), "available profiles : large, small")
+}
+
+func TestGetProfileWithoutFile(t *testing.T) {
+	profiles := files.NewProfileRepository(filepath.Join(t.TempDir(), "namespace.yaml"))
+
+	profile, err := profiles.Get("")
+	assert.Nil(t, err)
+	assert.Nil(t, profile)
+
+	_, err = profiles.Get("small")
+	assert.True(t, errors.Is(err, errors.Invalid))
+}
+
+func TestGetMetadata(t *testing.T) {
+	metadata, err := newProfileRepository(t).Metadata()
+
+	assert.Nil(t, err)
+	assert.Equal(t, resource.NamespaceMetadata{
+		Labels:      map[string]string{"team": "dating"},
+		Annotations: map[string]string{resource.AnnotationOwner: "qa"},
+	}, metadata)
+}
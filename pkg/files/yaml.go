@@ -0,0 +1,167 @@
+package files
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+	kyaml "sigs.k8s.io/yaml"
+)
+
+const (
+	jsonExt = ".json"
+	yamlExt = ".yaml"
+	ymlExt  = ".yml"
+)
+
+// isYAML returns true if the file is a yaml file, according to its extension
+func isYAML(path string) bool {
+	ext := filepath.Ext(path)
+	return ext == yamlExt || ext == ymlExt
+}
+
+// unmarshal decodes a json or yaml file, according to its extension.
+// Yaml is converted to json first, so that the decoded values are the same whatever the format.
+func unmarshal(path string, data []byte, v interface{}) error {
+	if isYAML(path) {
+		return kyaml.Unmarshal(data, v)
+	}
+
+	return json.Unmarshal(data, v)
+}
+
+// marshal encodes v in json or yaml, according to the extension of the file.
+// previous is the current content of the file : when writing yaml, its comments and the order of its keys are
+// kept as much as possible.
+func marshal(path string, previous []byte, v interface{}) ([]byte, error) {
+	if !isYAML(path) {
+		return json.MarshalIndent(v, "", "    ")
+	}
+
+	return marshalYAML(previous, v)
+}
+
+// marshalYAML returns the yaml representation of v, updating the previous yaml document if any.
+// v is encoded using its json field names.
+func marshalYAML(previous []byte, v interface{}) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, err
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(previous, &doc); err == nil && doc.Kind == yaml.DocumentNode && len(doc.Content) == 1 {
+		updateNode(doc.Content[0], value)
+	} else if err := doc.Encode(value); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// updateNode sets value into a yaml node. Maps are updated key by key and lists of the same length element by
+// element, keeping the comments and the order of the existing keys. New keys are added in alphabetical order.
+func updateNode(node *yaml.Node, value interface{}) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if node.Kind != yaml.MappingNode {
+			break
+		}
+
+		content := make([]*yaml.Node, 0, len(node.Content))
+		seen := make(map[string]bool, len(v))
+
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, val := node.Content[i], node.Content[i+1]
+
+			child, ok := v[key.Value]
+			if !ok || seen[key.Value] {
+				continue
+			}
+
+			seen[key.Value] = true
+			updateNode(val, child)
+			content = append(content, key, val)
+		}
+
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			if !seen[k] {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			key, val := &yaml.Node{}, &yaml.Node{}
+			replaceNode(key, k)
+			replaceNode(val, v[k])
+			content = append(content, key, val)
+		}
+
+		node.Content = content
+		return
+	case []interface{}:
+		if node.Kind != yaml.SequenceNode || len(node.Content) != len(v) {
+			break
+		}
+
+		for i := range v {
+			updateNode(node.Content[i], v[i])
+		}
+		return
+	default:
+		if node.Kind == yaml.ScalarNode && sameScalar(node, value) {
+			return
+		}
+	}
+
+	replaceNode(node, value)
+}
+
+// replaceNode replaces a node by the encoding of value, keeping its comments
+func replaceNode(node *yaml.Node, value interface{}) {
+	var n yaml.Node
+	_ = n.Encode(value)
+
+	n.HeadComment, n.LineComment, n.FootComment = node.HeadComment, node.LineComment, node.FootComment
+	*node = n
+}
+
+// sameScalar returns true if the scalar node holds value, so that its style is kept
+func sameScalar(node *yaml.Node, value interface{}) bool {
+	var current interface{}
+	if err := node.Decode(&current); err != nil {
+		return false
+	}
+
+	c, err := json.Marshal(current)
+	if err != nil {
+		return false
+	}
+
+	v, err := json.Marshal(value)
+	if err != nil {
+		return false
+	}
+
+	return bytes.Equal(c, v)
+}
@@ -0,0 +1,39 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/Meetic/blackbeard/pkg/api"
+	"github.com/Meetic/blackbeard/pkg/errors"
+)
+
+// StartBulk starts an operation on the namespaces matching a selector and returns its report
+func (h *Handler) StartBulk(c *gin.Context) {
+	var opts api.BulkOptions
+
+	if err := c.ShouldBindJSON(&opts); err != nil {
+		c.Error(errors.Wrap(err, errors.Invalid, "invalid request body"))
+		return
+	}
+
+	report, err := h.api.StartBulk(c.Params.ByName("operation"), opts, h.configPath)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, report)
+}
+
+// GetBulk returns the progress of a bulk operation
+func (h *Handler) GetBulk(c *gin.Context) {
+	report, err := h.api.GetBulk(c.Params.ByName("id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
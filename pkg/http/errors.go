@@ -0,0 +1,46 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/Meetic/blackbeard/pkg/errors"
+)
+
+// statusCodes maps error kinds to http status codes.
+var statusCodes = map[errors.Kind]int{
+	errors.Internal:      http.StatusInternalServerError,
+	errors.NotFound:      http.StatusNotFound,
+	errors.AlreadyExists: http.StatusConflict,
+	errors.Invalid:       http.StatusBadRequest,
+	errors.Conflict:      http.StatusConflict,
+	errors.Forbidden:     http.StatusForbidden,
+	errors.Timeout:       http.StatusGatewayTimeout,
+	errors.Upstream:      http.StatusBadGateway,
+}
+
+// statusCode returns the http status code associated to the kind of err.
+func statusCode(err error) int {
+	if code, ok := statusCodes[errors.KindOf(err)]; ok {
+		return code
+	}
+
+	return http.StatusInternalServerError
+}
+
+// errorMiddleware renders the last error attached to the context by a handler as a problem document.
+// Handlers report errors using c.Error(err) and return without writing any response.
+func errorMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+
+		err := c.Errors.Last().Err
+
+		c.JSON(statusCode(err), errors.ProblemOf(err))
+	}
+}
@@ -0,0 +1,158 @@
+package http
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/net/websocket"
+
+	"github.com/Meetic/blackbeard/pkg/api"
+	"github.com/Meetic/blackbeard/pkg/errors"
+	"github.com/Meetic/blackbeard/pkg/resource"
+)
+
+// Exec runs a command in a pod of a namespace managed by blackbeard through a websocket.
+// Messages exchanged on the websocket are described by api.ExecMessage.
+// Errors happening once the websocket is open are sent as an error message.
+func (h *Handler) Exec(c *gin.Context) {
+	if !strings.EqualFold(c.GetHeader("Upgrade"), "websocket") {
+		if !h.exec {
+			c.Error(errExecDisabled())
+			return
+		}
+		c.Error(errors.New(errors.Invalid, "exec requires a websocket connection"))
+		return
+	}
+
+	server := websocket.Server{
+		Handshake: h.checkOrigin,
+		Handler: func(ws *websocket.Conn) {
+			h.serveExec(ws, c.Params.ByName("namespace"), c.Request.URL.Query())
+		},
+	}
+
+	server.ServeHTTP(c.Writer, c.Request)
+}
+
+func errExecDisabled() error {
+	return errors.New(errors.Forbidden, "exec is disabled on this server, start it using the --enable-exec flag")
+}
+
+// checkOrigin accepts websockets opened by non browser clients, by pages served from the same host,
+// or from one of the allowed origins. CORS do not apply to websockets, so enabling them does not allow any origin.
+func (h *Handler) checkOrigin(config *websocket.Config, req *http.Request) error {
+	origin := req.Header.Get("Origin")
+	if origin == "" {
+		return nil
+	}
+
+	u, err := url.Parse(origin)
+	if err == nil && u.Host == req.Host {
+		return nil
+	}
+
+	for _, allowed := range h.origins {
+		if strings.EqualFold(strings.TrimSuffix(allowed, "/"), origin) {
+			return nil
+		}
+	}
+
+	return errors.New(errors.Forbidden, "origin %s is not allowed", origin)
+}
+
+func (h *Handler) serveExec(ws *websocket.Conn, namespace string, query url.Values) {
+	defer ws.Close()
+
+	if !h.exec {
+		sendExecError(ws, errExecDisabled())
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	tty, _ := strconv.ParseBool(query.Get("tty"))
+	stdin, _ := strconv.ParseBool(query.Get("stdin"))
+
+	resize := make(chan resource.TerminalSize, 1)
+	stdinReader, stdinWriter := io.Pipe()
+	defer stdinReader.Close()
+
+	opts := resource.ExecOptions{
+		Target:    query.Get("target"),
+		Container: query.Get("container"),
+		Command:   query["command"],
+		TTY:       tty,
+		Stdout:    &execWriter{ws: ws, kind: api.ExecStdout},
+		Stderr:    &execWriter{ws: ws, kind: api.ExecStderr},
+		Resize:    resize,
+	}
+
+	if stdin {
+		opts.Stdin = stdinReader
+	}
+
+	go func() {
+		defer cancel()
+		defer stdinWriter.Close()
+
+		for {
+			var msg api.ExecMessage
+			if err := websocket.JSON.Receive(ws, &msg); err != nil {
+				return
+			}
+
+			switch msg.Type {
+			case api.ExecStdin:
+				if _, err := stdinWriter.Write([]byte(msg.Data)); err != nil {
+					return
+				}
+			case api.ExecEOF:
+				stdinWriter.Close()
+			case api.ExecResize:
+				select {
+				case resize <- resource.TerminalSize{Width: msg.Width, Height: msg.Height}:
+				default:
+				}
+			}
+		}
+	}()
+
+	err := h.api.Exec(ctx, namespace, opts)
+
+	if code, ok := resource.ExitCode(err); ok {
+		websocket.JSON.Send(ws, api.ExecMessage{Type: api.ExecExit, Code: code})
+		return
+	}
+
+	if err != nil {
+		sendExecError(ws, err)
+		return
+	}
+
+	websocket.JSON.Send(ws, api.ExecMessage{Type: api.ExecExit})
+}
+
+func sendExecError(ws *websocket.Conn, err error) {
+	p := errors.ProblemOf(err)
+	websocket.JSON.Send(ws, api.ExecMessage{Type: api.ExecError, Error: &p})
+}
+
+// execWriter sends the output of a command as websocket messages
+type execWriter struct {
+	ws   *websocket.Conn
+	kind string
+}
+
+func (w *execWriter) Write(p []byte) (int, error) {
+	if err := websocket.JSON.Send(w.ws, api.ExecMessage{Type: w.kind, Data: string(p)}); err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
@@ -1,6 +1,7 @@
 package http
 
 import (
+	"fmt"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -10,10 +11,8 @@ func (h *Handler) Version(c *gin.Context) {
 	version, err := h.api.GetVersion()
 
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "Unable to get blackbeard version",
-			"message": err.Error(),
-		})
+		c.Error(fmt.Errorf("Unable to get blackbeard version: %w", err))
+		return
 	}
 
 	c.JSON(http.StatusOK, version)
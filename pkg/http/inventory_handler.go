@@ -2,15 +2,26 @@ package http
 
 import (
 	"net/http"
+	"strconv"
 
+	"github.com/gin-gonic/gin"
+
+	"github.com/Meetic/blackbeard/pkg/api"
+	"github.com/Meetic/blackbeard/pkg/errors"
 	"github.com/Meetic/blackbeard/pkg/playbook"
 	"github.com/Meetic/blackbeard/pkg/resource"
-	"github.com/gin-gonic/gin"
 )
 
 // createQuery represents the POST payload send to the create handler
 type createQuery struct {
 	Namespace string `json:"namespace" binding:"required"`
+	api.CreateOptions
+}
+
+// inventoryResponse is an inventory with the usage of the resource quotas of its namespace
+type inventoryResponse struct {
+	playbook.Inventory
+	Quota []resource.QuotaUsage `json:"quota,omitempty"`
 }
 
 // Create handle the namespace creation.
@@ -18,47 +29,42 @@ func (h *Handler) Create(c *gin.Context) {
 
 	var createQ createQuery
 
-	if err := c.BindJSON(&createQ); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if err := c.ShouldBindJSON(&createQ); err != nil {
+		c.Error(errors.Wrap(err, errors.Invalid, "invalid request body"))
 		return
 	}
 
 	// Create inventory
-	inv, err := h.api.Create(createQ.Namespace)
+	inv, err := h.api.Create(createQ.Namespace, createQ.CreateOptions)
 
 	if err != nil {
-		if alreadyExist, ok := err.(playbook.ErrorInventoryAlreadyExist); ok {
-			c.JSON(http.StatusBadRequest, gin.H{"error": alreadyExist.Error()})
-			return
-		}
-
-		if namespaceError, ok := err.(resource.ErrorCreateNamespace); ok {
-			c.JSON(http.StatusBadRequest, gin.H{"error": namespaceError.Error()})
-			return
-		}
-
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
 	c.JSON(http.StatusCreated, inv)
 }
 
-// Get return an inventory for a given namespace passed has query parameters.
+// Get return an inventory for a given namespace passed has query parameters,
+// along with the current usage of the namespace against its quotas.
+// The inventory is returned in yaml if the Accept header asks for it.
 func (h *Handler) Get(c *gin.Context) {
+	namespace := c.Params.ByName("namespace")
 
-	inv, err := h.api.Inventories().Get(c.Params.ByName("namespace"))
+	inv, err := h.api.Inventories().Get(namespace)
 
 	if err != nil {
-		if notFound, ok := err.(playbook.ErrorInventoryNotFound); ok {
-			c.JSON(http.StatusNotFound, gin.H{"error": notFound.Error()})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusOK, inv)
+	quota, err := h.api.GetQuota(namespace)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	negotiate(c, http.StatusOK, inventoryResponse{Inventory: inv, Quota: quota})
 }
 
 // GetDefaults return default for an inventory
@@ -68,38 +74,72 @@ func (h *Handler) GetDefaults(c *gin.Context) {
 	inv, err := h.api.Playbooks().GetDefault()
 
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
 	c.JSON(http.StatusOK, inv)
 }
 
-// List returns the list of existing inventories.
+// GetPlaybook returns the version of the playbook used by default.
+func (h *Handler) GetPlaybook(c *gin.Context) {
+	v, err := h.api.Playbooks().Version()
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, v)
+}
+
+// GetPlaybookStatus returns the load status of the playbook used by default.
+func (h *Handler) GetPlaybookStatus(c *gin.Context) {
+	s, err := h.api.Playbooks().Status()
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, s)
+}
+
+// List returns the list of existing inventories, filtered by the label selector of their namespace if any.
 func (h *Handler) List(c *gin.Context) {
 
-	invList, err := h.api.Inventories().List()
+	invList, err := h.api.ListInventories(c.Query("selector"))
 
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
 	c.JSON(http.StatusOK, invList)
 }
 
-// Update will update inventory for a given namespace
+// Update will update inventory for a given namespace.
+// The inventory is read in yaml if the Content-Type header says so.
 func (h *Handler) Update(c *gin.Context) {
 
 	var uQ playbook.Inventory
 
-	if err := c.BindJSON(&uQ); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if err := bind(c, &uQ); err != nil {
+		c.Error(errors.Wrap(err, errors.Invalid, "invalid request body"))
 		return
 	}
 
 	if err := h.api.Update(c.Params.ByName("namespace"), uQ, h.configPath); err != nil {
-		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// Apply generates the configs of an inventory and apply them into kubernetes
+func (h *Handler) Apply(c *gin.Context) {
+
+	if err := h.api.Apply(c.Params.ByName("namespace"), h.configPath); err != nil {
+		c.Error(err)
 		return
 	}
 
@@ -112,7 +152,7 @@ func (h *Handler) Reset(c *gin.Context) {
 	n := c.Params.ByName("namespace")
 
 	if err := h.api.Reset(n, h.configPath); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
@@ -120,15 +160,99 @@ func (h *Handler) Reset(c *gin.Context) {
 
 }
 
+// Adopt brings an existing namespace under blackbeard management
+func (h *Handler) Adopt(c *gin.Context) {
+	var infer bool
+
+	if i := c.Query("infer"); i != "" {
+		var err error
+		if infer, err = strconv.ParseBool(i); err != nil {
+			c.Error(errors.Wrap(err, errors.Invalid, "invalid infer parameter %q", i))
+			return
+		}
+	}
+
+	inv, err := h.api.Adopt(c.Params.ByName("namespace"), infer)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, inv)
+}
+
+// Upgrade merges the new defaults of the playbook into an inventory
+func (h *Handler) Upgrade(c *gin.Context) {
+	var apply bool
+
+	if a := c.Query("apply"); a != "" {
+		var err error
+		if apply, err = strconv.ParseBool(a); err != nil {
+			c.Error(errors.Wrap(err, errors.Invalid, "invalid apply parameter %q", a))
+			return
+		}
+	}
+
+	report, err := h.api.Upgrade(c.Params.ByName("namespace"), apply, h.configPath)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// Diff returns the values differing between an inventory and the inventory of another namespace,
+// or the playbook defaults.
+func (h *Handler) Diff(c *gin.Context) {
+	diff, err := h.api.Inventories().Diff(c.Params.ByName("namespace"), c.Query("against"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, diff)
+}
+
+// Detach stops managing a namespace without deleting it
+func (h *Handler) Detach(c *gin.Context) {
+	if err := h.api.Detach(c.Params.ByName("namespace")); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
 // Delete handle the namespace deletion.
 func (h *Handler) Delete(c *gin.Context) {
 	namespace := c.Params.ByName("namespace")
 
 	//Delete inventory
 	if err := h.api.Delete(namespace, true); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
 	c.JSON(http.StatusNoContent, nil)
 }
+
+// Doctor reports the drift between inventories and namespaces without fixing it
+func (h *Handler) Doctor(c *gin.Context) {
+	h.reconcile(c, false)
+}
+
+// Reconcile reports and fixes the drift between inventories and namespaces
+func (h *Handler) Reconcile(c *gin.Context) {
+	h.reconcile(c, true)
+}
+
+func (h *Handler) reconcile(c *gin.Context, fix bool) {
+	report, err := h.api.Reconcile(fix)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,69 @@
+package http_test
+
+import (
+	nethttp "net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/yaml"
+
+	"github.com/Meetic/blackbeard/pkg/playbook"
+)
+
+func TestGetInventoryYAML(t *testing.T) {
+	h := newHandler()
+
+	req := httptest.NewRequest(nethttp.MethodGet, "/inventories/test", nil)
+	req.Header.Set("Accept", "application/yaml")
+
+	w := httptest.NewRecorder()
+	h.Engine().ServeHTTP(w, req)
+
+	assert.Equal(t, nethttp.StatusOK, w.Code)
+	assert.Equal(t, "application/yaml; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Contains(t, w.Body.String(), "namespace: test\n")
+
+	var inv playbook.Inventory
+	assert.Nil(t, yaml.Unmarshal(w.Body.Bytes(), &inv))
+	assert.Equal(t, "test", inv.Namespace)
+	assert.NotEmpty(t, inv.Values["microservices"])
+}
+
+func TestGetInventoryJSONByDefault(t *testing.T) {
+	h := newHandler()
+
+	w := httptest.NewRecorder()
+	h.Engine().ServeHTTP(w, httptest.NewRequest(nethttp.MethodGet, "/inventories/test", nil))
+
+	assert.Equal(t, nethttp.StatusOK, w.Code)
+	assert.Equal(t, "application/json; charset=utf-8", w.Header().Get("Content-Type"))
+}
+
+func TestUpdateInventoryYAML(t *testing.T) {
+	h := newHandler()
+
+	body := `namespace: test
+values:
+  # comments are allowed
+  front:
+    version: 1.2.0
+`
+
+	req := httptest.NewRequest(nethttp.MethodPut, "/inventories/test", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/yaml")
+
+	w := httptest.NewRecorder()
+	h.Engine().ServeHTTP(w, req)
+
+	assert.Equal(t, nethttp.StatusNoContent, w.Code)
+
+	req = httptest.NewRequest(nethttp.MethodPut, "/inventories/test", strings.NewReader("values: ["))
+	req.Header.Set("Content-Type", "application/yaml")
+
+	w = httptest.NewRecorder()
+	h.Engine().ServeHTTP(w, req)
+
+	assert.Equal(t, nethttp.StatusBadRequest, w.Code)
+}
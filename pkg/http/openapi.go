@@ -0,0 +1,338 @@
+package http
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+	"path"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/Meetic/blackbeard/pkg/errors"
+	"github.com/Meetic/blackbeard/pkg/version"
+)
+
+const (
+	openAPIVersion = "3.0.3"
+	schemaRefPath  = "#/components/schemas/"
+)
+
+// docs holds the Swagger UI page and the swagger-ui-dist assets it uses (see the swagger-ui target of the Makefile)
+//
+//go:embed docs
+var docs embed.FS
+
+// OpenAPI is an OpenAPI 3 document describing the blackbeard REST api.
+type OpenAPI struct {
+	OpenAPI    string                          `json:"openapi"`
+	Info       OpenAPIInfo                     `json:"info"`
+	Paths      map[string]map[string]Operation `json:"paths"`
+	Components Components                      `json:"components"`
+}
+
+// OpenAPIInfo contains the metadata of the api.
+type OpenAPIInfo struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Version     string `json:"version"`
+}
+
+// Components contains the schemas referenced by the operations.
+type Components struct {
+	Schemas map[string]*Schema `json:"schemas"`
+}
+
+// Operation describes a single api operation on a path.
+type Operation struct {
+	Tags        []string            `json:"tags,omitempty"`
+	Summary     string              `json:"summary,omitempty"`
+	Description string              `json:"description,omitempty"`
+	Parameters  []Parameter         `json:"parameters,omitempty"`
+	RequestBody *RequestBody        `json:"requestBody,omitempty"`
+	Responses   map[string]Response `json:"responses"`
+}
+
+// Parameter describes a path or query parameter.
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required"`
+	Schema      *Schema `json:"schema"`
+}
+
+// RequestBody describes the body sent with a request.
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+// Response describes a response of an operation.
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+// MediaType associates a schema to a content type.
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Schema is a JSON schema as defined by the OpenAPI specification.
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+}
+
+// query describes a query parameter accepted by a route.
+type query struct {
+	name        string
+	description string
+}
+
+// route describes an endpoint of the REST api.
+// Routes are used both to register handlers and to generate the OpenAPI document,
+// so the documentation can not drift from the actual api.
+// request and responses values are only used for their type. A nil response means the response has no body.
+type route struct {
+	method      string
+	path        string
+	handler     gin.HandlerFunc
+	tag         string
+	summary     string
+	description string
+	queries     []query
+	request     interface{}
+	responses   map[int]interface{}
+}
+
+// OpenAPI returns the OpenAPI document describing the routes of the handler.
+func (h *Handler) OpenAPI() OpenAPI {
+	return h.spec
+}
+
+// ServeOpenAPI returns the OpenAPI document of the api.
+func (h *Handler) ServeOpenAPI(c *gin.Context) {
+	c.JSON(http.StatusOK, h.spec)
+}
+
+// ServeDocs returns a Swagger UI page rendering the OpenAPI document of the api.
+func (h *Handler) ServeDocs(c *gin.Context) {
+	page, err := docs.ReadFile("docs/index.html")
+	if err != nil {
+		c.Error(errors.Wrap(err, errors.Internal, "unable to read the documentation page"))
+		return
+	}
+
+	c.Data(http.StatusOK, "text/html; charset=utf-8", page)
+}
+
+// docsAssets returns the file system of the swagger-ui assets used by the documentation page
+func docsAssets() http.FileSystem {
+	assets, err := fs.Sub(docs, "docs/swagger-ui")
+	if err != nil {
+		panic(err)
+	}
+
+	return http.FS(assets)
+}
+
+// newOpenAPI generates the OpenAPI document for the given routes.
+func newOpenAPI(routes []route) OpenAPI {
+	g := &schemaGenerator{schemas: make(map[string]*Schema)}
+
+	spec := OpenAPI{
+		OpenAPI: openAPIVersion,
+		Info: OpenAPIInfo{
+			Title:       "Blackbeard API",
+			Description: "A REST API served from Blackbeard. See https://github.com/Meetic/blackbeard",
+			Version:     version.GetVersion(),
+		},
+		Paths: make(map[string]map[string]Operation),
+	}
+
+	problem := g.schemaOf(reflect.TypeOf(errors.Problem{}))
+
+	for _, r := range routes {
+		p, params := openAPIPath(r.path)
+
+		for _, q := range r.queries {
+			params = append(params, Parameter{
+				Name:        q.name,
+				In:          "query",
+				Description: q.description,
+				Schema:      &Schema{Type: "string"},
+			})
+		}
+
+		op := Operation{
+			Tags:        []string{r.tag},
+			Summary:     r.summary,
+			Description: r.description,
+			Parameters:  params,
+			Responses: map[string]Response{
+				"default": {
+					Description: "Error",
+					Content:     map[string]MediaType{gin.MIMEJSON: {Schema: problem}},
+				},
+			},
+		}
+
+		if r.request != nil {
+			op.RequestBody = &RequestBody{
+				Required: true,
+				Content:  map[string]MediaType{gin.MIMEJSON: {Schema: g.schemaOf(reflect.TypeOf(r.request))}},
+			}
+		}
+
+		for code, body := range r.responses {
+			resp := Response{Description: http.StatusText(code)}
+
+			if body != nil {
+				resp.Content = map[string]MediaType{gin.MIMEJSON: {Schema: g.schemaOf(reflect.TypeOf(body))}}
+			}
+
+			op.Responses[strconv.Itoa(code)] = resp
+		}
+
+		if _, ok := spec.Paths[p]; !ok {
+			spec.Paths[p] = make(map[string]Operation)
+		}
+
+		spec.Paths[p][strings.ToLower(r.method)] = op
+	}
+
+	spec.Components.Schemas = g.schemas
+
+	return spec
+}
+
+// openAPIPath converts a gin path into an OpenAPI path and returns its path parameters.
+// Example : /inventories/:namespace becomes /inventories/{namespace}
+func openAPIPath(ginPath string) (string, []Parameter) {
+	var params []Parameter
+
+	segments := strings.Split(ginPath, "/")
+
+	for i, s := range segments {
+		if strings.HasPrefix(s, ":") || strings.HasPrefix(s, "*") {
+			name := s[1:]
+			segments[i] = "{" + name + "}"
+			params = append(params, Parameter{
+				Name:     name,
+				In:       "path",
+				Required: true,
+				Schema:   &Schema{Type: "string"},
+			})
+		}
+	}
+
+	return strings.Join(segments, "/"), params
+}
+
+// schemaGenerator generates JSON schemas from go types using reflection.
+// Named structs are registered as components and referenced.
+type schemaGenerator struct {
+	schemas map[string]*Schema
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+func (g *schemaGenerator) schemaOf(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t == timeType {
+		return &Schema{Type: "string", Format: "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: g.schemaOf(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.schemaOf(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.structSchema(t)
+		}
+
+		name := path.Base(t.PkgPath()) + "." + t.Name()
+		if _, ok := g.schemas[name]; !ok {
+			// register the name first to handle recursive types
+			g.schemas[name] = &Schema{}
+			*g.schemas[name] = *g.structSchema(t)
+		}
+
+		return &Schema{Ref: schemaRefPath + name}
+	default:
+		// interface{} and any other type accept any value
+		return &Schema{}
+	}
+}
+
+func (g *schemaGenerator) structSchema(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+
+		if f.PkgPath != "" && !f.Anonymous {
+			continue
+		}
+
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			for ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				embedded := g.structSchema(ft)
+				for k, v := range embedded.Properties {
+					s.Properties[k] = v
+				}
+				s.Required = append(s.Required, embedded.Required...)
+				continue
+			}
+		}
+
+		if name == "" {
+			name = f.Name
+		}
+
+		s.Properties[name] = g.schemaOf(f.Type)
+
+		if strings.Contains(f.Tag.Get("binding"), "required") {
+			s.Required = append(s.Required, name)
+		}
+	}
+
+	return s
+}
@@ -0,0 +1,184 @@
+package http_test
+
+import (
+	"encoding/json"
+	nethttp "net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/Meetic/blackbeard/pkg/api"
+	"github.com/Meetic/blackbeard/pkg/errors"
+	"github.com/Meetic/blackbeard/pkg/http"
+	"github.com/Meetic/blackbeard/pkg/mock"
+)
+
+// undocumentedRoutes are the routes serving the documentation itself
+var undocumentedRoutes = map[string]bool{
+	"GET /openapi.json":           true,
+	"GET /docs":                   true,
+	"GET /docs/assets/*filepath":  true,
+	"HEAD /docs/assets/*filepath": true,
+}
+
+func newHandler() *http.Handler {
+	gin.SetMode(gin.TestMode)
+
+	kube := fake.NewSimpleClientset()
+
+	return http.NewHandler(
+		api.NewApi(mock.NewRepositories(kube)),
+		"configs",
+		false,
+	)
+}
+
+// openAPIPath converts a gin path into an OpenAPI path and returns the names of its path parameters
+func openAPIPath(ginPath string) (string, []string) {
+	var params []string
+
+	segments := strings.Split(ginPath, "/")
+	for i, s := range segments {
+		if strings.HasPrefix(s, ":") || strings.HasPrefix(s, "*") {
+			segments[i] = "{" + s[1:] + "}"
+			params = append(params, s[1:])
+		}
+	}
+
+	return strings.Join(segments, "/"), params
+}
+
+// TestOpenAPIMatchesRoutes compares the document served on /openapi.json with the routes actually registered in gin :
+// every route must be documented along with its path parameters and a successful response, and every documented
+// operation must be registered.
+func TestOpenAPIMatchesRoutes(t *testing.T) {
+	h := newHandler()
+
+	w := httptest.NewRecorder()
+	h.Engine().ServeHTTP(w, httptest.NewRequest(nethttp.MethodGet, "/openapi.json", nil))
+	assert.Equal(t, nethttp.StatusOK, w.Code)
+
+	var spec http.OpenAPI
+	assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &spec))
+
+	registered := make(map[string]bool)
+
+	for _, r := range h.Engine().Routes() {
+		key := r.Method + " " + r.Path
+		if undocumentedRoutes[key] {
+			continue
+		}
+
+		p, params := openAPIPath(r.Path)
+		registered[r.Method+" "+p] = true
+
+		op, ok := spec.Paths[p][strings.ToLower(r.Method)]
+		if !assert.True(t, ok, "route %s is not documented", key) {
+			continue
+		}
+
+		assert.NotEmpty(t, op.Tags, "route %s has no tag", key)
+		assert.NotEmpty(t, op.Summary, "route %s has no summary", key)
+
+		for _, name := range params {
+			found := false
+			for _, param := range op.Parameters {
+				if param.In == "path" && param.Name == name && param.Required {
+					found = true
+				}
+			}
+			assert.True(t, found, "path parameter %s of route %s is not documented", name, key)
+		}
+
+		success := false
+		for code, resp := range op.Responses {
+			// websocket routes succeed by switching protocols
+			if strings.HasPrefix(code, "2") || code == "101" {
+				success = true
+			}
+			for _, media := range resp.Content {
+				if ref := media.Schema.Ref; ref != "" {
+					assert.Contains(t, spec.Components.Schemas, strings.TrimPrefix(ref, "#/components/schemas/"),
+						"response %s of route %s references an unknown schema", code, key)
+				}
+			}
+		}
+		assert.True(t, success, "route %s documents no successful response", key)
+	}
+
+	for p, ops := range spec.Paths {
+		for method := range ops {
+			key := strings.ToUpper(method) + " " + p
+			assert.True(t, registered[key], "documented operation %s is not registered", key)
+		}
+	}
+}
+
+func TestServeOpenAPI(t *testing.T) {
+	h := newHandler()
+
+	w := httptest.NewRecorder()
+	h.Engine().ServeHTTP(w, httptest.NewRequest(nethttp.MethodGet, "/openapi.json", nil))
+
+	assert.Equal(t, nethttp.StatusOK, w.Code)
+
+	var spec http.OpenAPI
+	assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &spec))
+
+	inventory := spec.Components.Schemas["playbook.Inventory"]
+	assert.NotNil(t, inventory)
+	assert.Contains(t, inventory.Properties, "namespace")
+	assert.Contains(t, inventory.Properties, "values")
+
+	create := spec.Paths["/inventories"]["post"]
+	assert.Equal(t, "#/components/schemas/playbook.Inventory", create.Responses["201"].Content["application/json"].Schema.Ref)
+	assert.Equal(t, []string{"namespace"}, spec.Components.Schemas["http.createQuery"].Required)
+
+	version := spec.Components.Schemas["api.Version"]
+	assert.Contains(t, version.Properties, "blackbeard")
+	assert.Contains(t, version.Properties, "kubernetes")
+	assert.Contains(t, version.Properties, "kubectl")
+}
+
+func TestServeDocs(t *testing.T) {
+	h := newHandler()
+
+	w := httptest.NewRecorder()
+	h.Engine().ServeHTTP(w, httptest.NewRequest(nethttp.MethodGet, "/docs", nil))
+
+	assert.Equal(t, nethttp.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "openapi.json")
+	assert.NotContains(t, w.Body.String(), "https://", "the documentation page must not depend on a remote host")
+	assert.Contains(t, w.Body.String(), "docs/assets/swagger-ui-bundle.js")
+}
+
+func TestServeDocsAssets(t *testing.T) {
+	h := newHandler()
+
+	w := httptest.NewRecorder()
+	h.Engine().ServeHTTP(w, httptest.NewRequest(nethttp.MethodGet, "/docs/assets/README.md", nil))
+	assert.Equal(t, nethttp.StatusOK, w.Code)
+
+	for _, asset := range []string{"swagger-ui.css", "swagger-ui-bundle.js"} {
+		w = httptest.NewRecorder()
+		h.Engine().ServeHTTP(w, httptest.NewRequest(nethttp.MethodGet, "/docs/assets/"+asset, nil))
+		assert.Equal(t, nethttp.StatusOK, w.Code, "%s is not vendored, run make swagger-ui", asset)
+	}
+}
+
+func TestErrorMiddleware(t *testing.T) {
+	h := newHandler()
+
+	w := httptest.NewRecorder()
+	h.Engine().ServeHTTP(w, httptest.NewRequest(nethttp.MethodPost, "/inventories", strings.NewReader(`{}`)))
+
+	assert.Equal(t, nethttp.StatusBadRequest, w.Code)
+
+	var p errors.Problem
+	assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &p))
+	assert.Equal(t, errors.Invalid, p.Code)
+}
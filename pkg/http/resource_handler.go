@@ -1,11 +1,17 @@
 package http
 
 import (
+	"encoding/json"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
 
-	"github.com/Meetic/blackbeard/pkg/playbook"
+	"github.com/Meetic/blackbeard/pkg/errors"
+	"github.com/Meetic/blackbeard/pkg/resource"
 )
 
 // ListServices returns the list of exposed services (NodePort and ingress configuration) of a given inventory
@@ -14,35 +20,69 @@ func (h *Handler) ListServices(c *gin.Context) {
 	services, err := h.api.ListExposedServices(c.Params.ByName("namespace"))
 
 	if err != nil {
-		if notFound, ok := err.(playbook.ErrorInventoryNotFound); ok {
-			c.JSON(http.StatusNotFound, gin.H{"error": notFound.Error()})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
 	c.JSON(http.StatusOK, services)
 }
 
-// GetStatus returns the namespace status (ready or not) for a given namespace
-func (h *Handler) GetStatus(c *gin.Context) {
+// Check probes the services exposed by a namespace and returns a pass/fail report.
+// The report is returned with a 200 status even if checks failed.
+func (h *Handler) Check(c *gin.Context) {
+	namespace := c.Params.ByName("namespace")
 
-	_, err := h.api.Inventories().Get(c.Params.ByName("namespace"))
+	if _, err := h.api.Inventories().Get(namespace); err != nil {
+		c.Error(err)
+		return
+	}
 
+	report, err := h.api.Check(c.Request.Context(), namespace)
 	if err != nil {
-		if notFound, ok := err.(playbook.ErrorInventoryNotFound); ok {
-			c.JSON(http.StatusNotFound, gin.H{"error": notFound.Error()})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// Drift compares the live objects of a namespace with its rendered configs
+func (h *Handler) Drift(c *gin.Context) {
+	report, err := h.api.Drift(c.Params.ByName("namespace"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// ListNamespaces returns the list of namespaces with their status and whether they are managed by the playbook,
+// filtered by a label selector if any.
+func (h *Handler) ListNamespaces(c *gin.Context) {
+
+	namespaces, err := h.api.ListNamespaces(c.Query("selector"))
+
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, namespaces)
+}
+
+// GetStatus returns the namespace status (ready or not) for a given namespace
+func (h *Handler) GetStatus(c *gin.Context) {
+
+	if _, err := h.api.Inventories().Get(c.Params.ByName("namespace")); err != nil {
+		c.Error(err)
 		return
 	}
 
 	status, err := h.api.Namespaces().GetStatus(c.Params.ByName("namespace"))
 
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
@@ -63,7 +103,7 @@ func (h *Handler) GetStatuses(c *gin.Context) {
 	for _, i := range invs {
 		s, err := h.api.Namespaces().GetStatus(i.Namespace)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			c.Error(err)
 			return
 		}
 
@@ -81,16 +121,140 @@ func (h *Handler) GetStatuses(c *gin.Context) {
 	c.JSON(http.StatusOK, statuses)
 }
 
-// Delete handle the namespace deletion.
+// ListResources returns the objects of the given kind in a namespace
+func (h *Handler) ListResources(c *gin.Context) {
+
+	objects, err := h.api.ListResources(c.Params.ByName("namespace"), c.Params.ByName("kind"))
+
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, objects)
+}
+
+// GetResource returns an object of a namespace
+func (h *Handler) GetResource(c *gin.Context) {
+
+	object, err := h.api.GetResource(c.Params.ByName("namespace"), c.Params.ByName("kind"), c.Params.ByName("name"))
+
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, object)
+}
+
+// DeleteResource deletes an object from a namespace managed by blackbeard
 func (h *Handler) DeleteResource(c *gin.Context) {
+
+	if err := h.api.DeleteResource(c.Params.ByName("namespace"), c.Params.ByName("kind"), c.Params.ByName("name")); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// Restart recreates the pods of a deployment or a statefulset
+func (h *Handler) Restart(c *gin.Context) {
 	namespace := c.Params.ByName("namespace")
-	resource := c.Params.ByName("resource")
 
-	//Delete inventory
-	if err := h.api.DeleteResource(namespace, resource); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := h.api.Restart(namespace, c.Params.ByName("kind"), c.Params.ByName("name")); err != nil {
+		c.Error(err)
 		return
 	}
 
 	c.JSON(http.StatusNoContent, nil)
 }
+
+// Logs streams the logs of the pods of a namespace.
+// Lines are written as newline delimited json objects, or as server sent events
+// if the client accepts text/event-stream.
+// Headers are flushed before the first line so that clients following a quiet workload get a response.
+// Errors happening once the response started are written to the stream : as an error event, or as a
+// {"error": problem} object.
+func (h *Handler) Logs(c *gin.Context) {
+	namespace := c.Params.ByName("namespace")
+
+	opts, err := logOptions(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	if _, err := h.api.Inventories().Get(namespace); err != nil {
+		c.Error(err)
+		return
+	}
+
+	sse := strings.Contains(c.GetHeader("Accept"), "text/event-stream")
+	if sse {
+		c.Header("Content-Type", "text/event-stream")
+		c.Header("Cache-Control", "no-cache")
+	} else {
+		c.Header("Content-Type", "application/x-ndjson")
+	}
+	c.Status(http.StatusOK)
+	c.Writer.WriteHeaderNow()
+	c.Writer.Flush()
+
+	lines := make(chan resource.LogLine)
+	errs := make(chan error, 1)
+
+	go func() {
+		errs <- h.api.Pods().Logs(c.Request.Context(), namespace, opts, lines)
+		close(lines)
+	}()
+
+	for line := range lines {
+		if sse {
+			c.SSEvent("log", line)
+		} else {
+			json.NewEncoder(c.Writer).Encode(line)
+		}
+		c.Writer.Flush()
+	}
+
+	if err := <-errs; err != nil {
+		logrus.WithField("namespace", namespace).Errorf("log streaming stopped : %v", err)
+		if sse {
+			c.SSEvent("error", errors.ProblemOf(err))
+		} else {
+			json.NewEncoder(c.Writer).Encode(logError{Error: errors.ProblemOf(err)})
+		}
+		c.Writer.Flush()
+	}
+}
+
+// logError is the object written to a newline delimited json log stream when streaming fails
+type logError struct {
+	Error errors.Problem `json:"error"`
+}
+
+func logOptions(c *gin.Context) (resource.LogOptions, error) {
+	opts := resource.LogOptions{
+		Workload:  c.Query("workload"),
+		Container: c.Query("container"),
+	}
+
+	if f := c.Query("follow"); f != "" {
+		follow, err := strconv.ParseBool(f)
+		if err != nil {
+			return opts, errors.Wrap(err, errors.Invalid, "invalid follow parameter %q", f)
+		}
+		opts.Follow = follow
+	}
+
+	if s := c.Query("since"); s != "" {
+		since, err := time.ParseDuration(s)
+		if err != nil {
+			return opts, errors.Wrap(err, errors.Invalid, "invalid since parameter %q", s)
+		}
+		opts.Since = since
+	}
+
+	return opts, nil
+}
@@ -0,0 +1,219 @@
+package http_test
+
+import (
+	"context"
+	"encoding/json"
+	nethttp "net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/Meetic/blackbeard/pkg/api"
+	"github.com/Meetic/blackbeard/pkg/errors"
+	"github.com/Meetic/blackbeard/pkg/http"
+	"github.com/Meetic/blackbeard/pkg/mock"
+	"github.com/Meetic/blackbeard/pkg/resource"
+)
+
+func newLogsHandler() *http.Handler {
+	return newLogsHandlerFor(newLogsClientset(), false)
+}
+
+func newLogsClientset() *fake.Clientset {
+	labels := map[string]string{"app": "api"}
+
+	return fake.NewSimpleClientset(
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "test"},
+			Spec:       appsv1.DeploymentSpec{Selector: &metav1.LabelSelector{MatchLabels: labels}},
+		},
+		&v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "api-1", Namespace: "test", Labels: labels},
+			Spec:       v1.PodSpec{Containers: []v1.Container{{Name: "php"}}},
+			Status:     v1.PodStatus{Phase: v1.PodRunning},
+		},
+		&v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "front-1", Namespace: "test", Labels: map[string]string{"app": "front"}},
+			Spec:       v1.PodSpec{Containers: []v1.Container{{Name: "nginx"}}},
+			Status:     v1.PodStatus{Phase: v1.PodRunning},
+		},
+	)
+}
+
+func newLogsHandlerFor(kube *fake.Clientset, cors bool, opts ...http.HandlerOption) *http.Handler {
+	gin.SetMode(gin.TestMode)
+
+	return http.NewHandler(
+		api.NewApi(mock.NewRepositories(kube)),
+		"configs",
+		cors,
+		opts...,
+	)
+}
+
+func TestLogs(t *testing.T) {
+	h := newLogsHandler()
+
+	w := httptest.NewRecorder()
+	h.Engine().ServeHTTP(w, httptest.NewRequest(nethttp.MethodGet, "/inventories/test/logs?workload=api", nil))
+
+	assert.Equal(t, nethttp.StatusOK, w.Code)
+	assert.Equal(t, "application/x-ndjson", w.Header().Get("Content-Type"))
+
+	var line resource.LogLine
+	assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &line))
+	assert.Equal(t, resource.LogLine{Pod: "api-1", Container: "php", Message: "fake logs"}, line)
+}
+
+func TestLogsServerSentEvents(t *testing.T) {
+	h := newLogsHandler()
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(nethttp.MethodGet, "/inventories/test/logs", nil)
+	req.Header.Set("Accept", "text/event-stream")
+	h.Engine().ServeHTTP(w, req)
+
+	assert.Equal(t, nethttp.StatusOK, w.Code)
+	assert.Equal(t, "text/event-stream", w.Header().Get("Content-Type"))
+	assert.Equal(t, 2, strings.Count(w.Body.String(), "event:log"))
+}
+
+func TestLogsFollowNewPods(t *testing.T) {
+	kube := newLogsClientset()
+	server := httptest.NewServer(newLogsHandlerFor(kube, false).Engine())
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	req, _ := nethttp.NewRequestWithContext(ctx, nethttp.MethodGet, server.URL+"/inventories/test/logs?workload=api&follow=true", nil)
+	resp, err := nethttp.DefaultClient.Do(req)
+	assert.Nil(t, err)
+	defer resp.Body.Close()
+
+	// headers are received before any log line
+	assert.Equal(t, nethttp.StatusOK, resp.StatusCode)
+
+	decoder := json.NewDecoder(resp.Body)
+
+	var line resource.LogLine
+	assert.Nil(t, decoder.Decode(&line))
+	assert.Equal(t, "api-1", line.Pod)
+
+	_, err = kube.CoreV1().Pods("test").Create(ctx, &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "api-2", Namespace: "test", Labels: map[string]string{"app": "api"}},
+		Spec:       v1.PodSpec{Containers: []v1.Container{{Name: "php"}}},
+		Status: v1.PodStatus{
+			Phase:             v1.PodRunning,
+			ContainerStatuses: []v1.ContainerStatus{{Name: "php", ContainerID: "php-1", State: v1.ContainerState{Running: &v1.ContainerStateRunning{}}}},
+		},
+	}, metav1.CreateOptions{})
+	assert.Nil(t, err)
+
+	assert.Nil(t, decoder.Decode(&line))
+	assert.Equal(t, resource.LogLine{Pod: "api-2", Container: "php", Message: "fake logs"}, line)
+}
+
+func TestLogsErrors(t *testing.T) {
+	h := newLogsHandler()
+
+	w := httptest.NewRecorder()
+	h.Engine().ServeHTTP(w, httptest.NewRequest(nethttp.MethodGet, "/inventories/test/logs?since=yesterday", nil))
+
+	var p errors.Problem
+	assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &p))
+	assert.Equal(t, nethttp.StatusBadRequest, w.Code)
+	assert.Equal(t, errors.Invalid, p.Code)
+
+	// the response has started when the workload is resolved, so its errors are written to the stream
+	tests := []struct {
+		url  string
+		code errors.Kind
+	}{
+		{"/inventories/test/logs?workload=unknown", errors.NotFound},
+		{"/inventories/test/logs?workload=cronjob/api", errors.Invalid},
+	}
+
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+		h.Engine().ServeHTTP(w, httptest.NewRequest(nethttp.MethodGet, test.url, nil))
+
+		var record struct {
+			Error errors.Problem `json:"error"`
+		}
+		assert.Equal(t, nethttp.StatusOK, w.Code, test.url)
+		assert.Equal(t, "application/x-ndjson", w.Header().Get("Content-Type"), test.url)
+		assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &record), test.url)
+		assert.Equal(t, test.code, record.Error.Code, test.url)
+	}
+}
+
+func TestRestart(t *testing.T) {
+	h := newLogsHandler()
+
+	w := httptest.NewRecorder()
+	h.Engine().ServeHTTP(w, httptest.NewRequest(nethttp.MethodPost, "/resources/test/deployments/api/restart", nil))
+	assert.Equal(t, nethttp.StatusNoContent, w.Code)
+
+	w = httptest.NewRecorder()
+	h.Engine().ServeHTTP(w, httptest.NewRequest(nethttp.MethodPost, "/resources/test/jobs/api/restart", nil))
+	assert.Equal(t, nethttp.StatusBadRequest, w.Code)
+}
+
+func TestExecDisabled(t *testing.T) {
+	h := newLogsHandler()
+
+	w := httptest.NewRecorder()
+	h.Engine().ServeHTTP(w, httptest.NewRequest(nethttp.MethodGet, "/inventories/test/exec?target=deploy/api&command=ls", nil))
+
+	var p errors.Problem
+	assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &p))
+	assert.Equal(t, nethttp.StatusForbidden, w.Code)
+	assert.Equal(t, errors.Forbidden, p.Code)
+}
+
+func TestExecOrigin(t *testing.T) {
+	tests := []struct {
+		origin string
+		code   int
+	}{
+		{"", nethttp.StatusSwitchingProtocols},
+		{"same-host", nethttp.StatusSwitchingProtocols},
+		{"https://dashboard.example.com", nethttp.StatusSwitchingProtocols},
+		{"https://evil.example.com", nethttp.StatusForbidden},
+	}
+
+	// CORS do not apply to websockets and must not allow any origin
+	h := newLogsHandlerFor(newLogsClientset(), true, http.WithExec(), http.WithExecAllowedOrigins("https://dashboard.example.com"))
+	server := httptest.NewServer(h.Engine())
+	defer server.Close()
+
+	for _, test := range tests {
+		req, _ := nethttp.NewRequest(nethttp.MethodGet, server.URL+"/inventories/test/exec?target=deploy/api&command=ls", nil)
+		req.Header.Set("Connection", "Upgrade")
+		req.Header.Set("Upgrade", "websocket")
+		req.Header.Set("Sec-WebSocket-Version", "13")
+		req.Header.Set("Sec-WebSocket-Key", "dGhlIHNhbXBsZSBub25jZQ==")
+
+		origin := test.origin
+		if origin == "same-host" {
+			origin = server.URL
+		}
+		if origin != "" {
+			req.Header.Set("Origin", origin)
+		}
+
+		resp, err := nethttp.DefaultClient.Do(req)
+		assert.Nil(t, err, test.origin)
+		resp.Body.Close()
+		assert.Equal(t, test.code, resp.StatusCode, test.origin)
+	}
+}
@@ -0,0 +1,334 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/Meetic/blackbeard/pkg/api"
+	"github.com/Meetic/blackbeard/pkg/playbook"
+	"github.com/Meetic/blackbeard/pkg/resource"
+)
+
+// newRoutes returns the routes of the REST api.
+func (h *Handler) newRoutes() []route {
+	return []route{
+		{
+			method:      http.MethodGet,
+			path:        "/ready",
+			handler:     h.HealthCheck,
+			tag:         "Monitoring",
+			summary:     "Check if http server is ready",
+			description: "Check if http server is ready to handle traffic",
+			responses:   map[int]interface{}{http.StatusOK: nil},
+		},
+		{
+			method:      http.MethodGet,
+			path:        "/alive",
+			handler:     h.HealthCheck,
+			tag:         "Monitoring",
+			summary:     "Check if http server is alive",
+			description: "Check if http server is alive and can handle traffic",
+			responses:   map[int]interface{}{http.StatusOK: nil},
+		},
+		{
+			method:      http.MethodPost,
+			path:        "/inventories",
+			handler:     h.Create,
+			tag:         "Namespaces",
+			summary:     "Create an inventory",
+			description: "Create an inventory for the given namespace. This will also create the inventory file and the associated namespace, labelled and annotated with the given metadata and the playbook defaults, and limited by the quota, limits and network policies of the chosen profile.",
+			request:     createQuery{},
+			responses:   map[int]interface{}{http.StatusCreated: playbook.Inventory{}},
+		},
+		{
+			method:      http.MethodGet,
+			path:        "/inventories/:namespace",
+			handler:     h.Get,
+			tag:         "Namespaces",
+			summary:     "Return inventory for the given namespace",
+			description: "Read inventory file for a given namespace and return it as a json object, along with the usage of the namespace resource quotas. The inventory is returned in yaml when the Accept header is application/yaml.",
+			responses:   map[int]interface{}{http.StatusOK: inventoryResponse{}},
+		},
+		{
+			method:      http.MethodGet,
+			path:        "/inventories/:namespace/status",
+			handler:     h.GetStatus,
+			tag:         "Namespaces",
+			summary:     "Return the namespace status",
+			description: "Read the namespace status : the percentage of ready workloads and the namespace phase",
+			responses:   map[int]interface{}{http.StatusOK: resource.NamespaceStatus{}},
+		},
+		{
+			method:      http.MethodPost,
+			path:        "/inventories/:namespace/reset",
+			handler:     h.Reset,
+			tag:         "Namespaces",
+			summary:     "Reset a namespace to defaults",
+			description: "Reset a namespace to defaults. This will reset the inventory and apply the changes to kubernetes.",
+			responses:   map[int]interface{}{http.StatusNoContent: nil},
+		},
+		{
+			method:      http.MethodPost,
+			path:        "/inventories/:namespace/adopt",
+			handler:     h.Adopt,
+			tag:         "Namespaces",
+			summary:     "Adopt an existing namespace",
+			description: "Label an existing namespace manager=blackbeard and generate its inventory from the playbook defaults. Nothing is applied to the namespace.",
+			queries: []query{
+				{name: "infer", description: "Read the versions of the inventory from the image tags of the namespace deployments if true"},
+			},
+			responses: map[int]interface{}{http.StatusCreated: playbook.Inventory{}},
+		},
+		{
+			method:      http.MethodPost,
+			path:        "/inventories/:namespace/detach",
+			handler:     h.Detach,
+			tag:         "Namespaces",
+			summary:     "Detach a namespace",
+			description: "Remove the manager=blackbeard label of a namespace and delete its inventory. The namespace and its workloads are left running.",
+			responses:   map[int]interface{}{http.StatusNoContent: nil},
+		},
+		{
+			method:      http.MethodPost,
+			path:        "/inventories/:namespace/upgrade",
+			handler:     h.Upgrade,
+			tag:         "Namespaces",
+			summary:     "Upgrade an inventory to the playbook defaults",
+			description: "Merge the changes made to the playbook defaults since the inventory was generated into the inventory : new values are added, values changed in the inventory are kept, and values changed in both are reported as conflicts.",
+			queries: []query{
+				{name: "apply", description: "Apply the inventory to the namespace if true and if the upgrade changed it"},
+			},
+			responses: map[int]interface{}{http.StatusOK: playbook.UpgradeReport{}},
+		},
+		{
+			method:      http.MethodGet,
+			path:        "/inventories/:namespace/diff",
+			handler:     h.Diff,
+			tag:         "Namespaces",
+			summary:     "Compare an inventory",
+			description: "Return the values differing between the inventory of a namespace and the inventory of another namespace, or the defaults of the playbook. Values of the namespace missing from the other inventory are added, values missing from the namespace are removed.",
+			queries: []query{
+				{name: "against", description: "The namespace to compare the inventory against. The defaults of the playbook are used if empty"},
+			},
+			responses: map[int]interface{}{http.StatusOK: playbook.InventoryDiff{}},
+		},
+		{
+			method:      http.MethodPost,
+			path:        "/inventories/:namespace/apply",
+			handler:     h.Apply,
+			tag:         "Namespaces",
+			summary:     "Apply the inventory",
+			description: "Generate the configs of the inventory and apply them to the namespace.",
+			responses:   map[int]interface{}{http.StatusNoContent: nil},
+		},
+		{
+			method:      http.MethodGet,
+			path:        "/inventories/:namespace/services",
+			handler:     h.ListServices,
+			tag:         "Namespaces",
+			summary:     "Returns the list of exposed services",
+			description: "Returns the list of exposed services (NodePort and ingress configuration) of a given inventory",
+			responses:   map[int]interface{}{http.StatusOK: []resource.Service{}},
+		},
+		{
+			method:      http.MethodGet,
+			path:        "/inventories/:namespace/check",
+			handler:     h.Check,
+			tag:         "Namespaces",
+			summary:     "Check the exposed services of a namespace",
+			description: "Request the ingress and route urls, dial the NodePort and LoadBalancer services and run the smoke checks declared in the blackbeard.io/checks annotation. The report is returned even if some checks failed.",
+			responses:   map[int]interface{}{http.StatusOK: resource.CheckReport{}},
+		},
+		{
+			method:      http.MethodGet,
+			path:        "/inventories/:namespace/drift",
+			handler:     h.Drift,
+			tag:         "Namespaces",
+			summary:     "Compare the namespace with its configs",
+			description: "Compare the live objects of the namespace with the configs rendered by its last apply. Only the fields set in the configs are compared.",
+			responses:   map[int]interface{}{http.StatusOK: resource.DriftReport{}},
+		},
+		{
+			method:      http.MethodGet,
+			path:        "/inventories/:namespace/logs",
+			handler:     h.Logs,
+			tag:         "Namespaces",
+			summary:     "Stream the logs of a namespace",
+			description: "Stream the logs of the pods of a namespace as newline delimited json objects, or as server sent events if the request accepts text/event-stream. When following, new pods and restarted containers are streamed as they start. Errors happening once the stream started are sent as an {\"error\": problem} object, or as an error event.",
+			queries: []query{
+				{name: "workload", description: "Name of a deployment, statefulset or job, optionally prefixed by its kind (ex: job/migration). Default to all pods."},
+				{name: "container", description: "Only stream the logs of this container"},
+				{name: "follow", description: "Keep streaming new logs if true"},
+				{name: "since", description: "Only return logs newer than this duration (ex: 10m)"},
+			},
+			responses: map[int]interface{}{http.StatusOK: resource.LogLine{}},
+		},
+		{
+			method:      http.MethodGet,
+			path:        "/inventories/:namespace/exec",
+			handler:     h.Exec,
+			tag:         "Namespaces",
+			summary:     "Run a command in a pod through a websocket",
+			description: "Upgrade the connection to a websocket and run a command in a pod of a namespace managed by blackbeard. Clients send stdin, eof and resize json messages. The server sends stdout and stderr messages, then an exit or error message. The server must be started using --enable-exec.",
+			queries: []query{
+				{name: "target", description: "Pod given as pod/NAME, or workload given as deployment/NAME, statefulset/NAME or job/NAME"},
+				{name: "container", description: "Container of the pod. Default to the first container."},
+				{name: "command", description: "Command to run. Repeat the parameter for each argument."},
+				{name: "tty", description: "Allocate a TTY if true"},
+				{name: "stdin", description: "Forward stdin messages to the command if true"},
+			},
+			responses: map[int]interface{}{http.StatusSwitchingProtocols: api.ExecMessage{}},
+		},
+		{
+			method:      http.MethodGet,
+			path:        "/inventories",
+			handler:     h.List,
+			tag:         "Namespaces",
+			summary:     "Return the list of existing inventories",
+			description: "Read all inventory files and return them as an array",
+			queries: []query{
+				{name: "selector", description: "Label selector of the namespaces of the returned inventories (ex: team=dating,env!=prod)"},
+			},
+			responses: map[int]interface{}{http.StatusOK: []playbook.Inventory{}},
+		},
+		{
+			method:      http.MethodGet,
+			path:        "/defaults",
+			handler:     h.GetDefaults,
+			tag:         "Namespaces",
+			summary:     "Get default value for an inventory",
+			description: "Return the content of the defaults.json file in the used playbook.",
+			responses:   map[int]interface{}{http.StatusOK: playbook.Inventory{}},
+		},
+		{
+			method:      http.MethodGet,
+			path:        "/playbook",
+			handler:     h.GetPlaybook,
+			tag:         "Namespaces",
+			summary:     "Get the version of the playbook",
+			description: "Return the source of the playbook used by default and, for a git playbook, the ref and the commit in use.",
+			responses:   map[int]interface{}{http.StatusOK: playbook.Version{}},
+		},
+		{
+			method:      http.MethodGet,
+			path:        "/playbook/status",
+			handler:     h.GetPlaybookStatus,
+			tag:         "Namespaces",
+			summary:     "Get the load status of the playbook",
+			description: "Return whether a valid version of the playbook is in use and when it was loaded, along with the error of the last load if it failed. The previous valid version is kept when a reload fails.",
+			responses:   map[int]interface{}{http.StatusOK: playbook.Status{}},
+		},
+		{
+			method:      http.MethodPut,
+			path:        "/inventories/:namespace",
+			handler:     h.Update,
+			tag:         "Namespaces",
+			summary:     "Update the inventory",
+			description: "Update the inventory for the given namespace and apply it. If the namespace field is different from the namespace passed as path param, it will also rename the inventory. The inventory may be sent in yaml with the application/yaml Content-Type.",
+			request:     playbook.Inventory{},
+			responses:   map[int]interface{}{http.StatusNoContent: nil},
+		},
+		{
+			method:      http.MethodDelete,
+			path:        "/inventories/:namespace",
+			handler:     h.Delete,
+			tag:         "Namespaces",
+			summary:     "Delete inventory file and associated namespace",
+			description: "Delete inventory file, configs and namespace for the given namespace",
+			responses:   map[int]interface{}{http.StatusNoContent: nil},
+		},
+		{
+			method:      http.MethodGet,
+			path:        "/namespaces",
+			handler:     h.ListNamespaces,
+			tag:         "Namespaces",
+			summary:     "Return the list of namespaces",
+			description: "Return the namespaces managed by blackbeard with their status, their metadata and whether an inventory exists for them.",
+			queries: []query{
+				{name: "selector", description: "Label selector of the returned namespaces (ex: team=dating,env!=prod)"},
+			},
+			responses: map[int]interface{}{http.StatusOK: []api.Namespace{}},
+		},
+		{
+			method:      http.MethodGet,
+			path:        "/doctor",
+			handler:     h.Doctor,
+			tag:         "Namespaces",
+			summary:     "Report the drift between inventories and namespaces",
+			description: "Report the inventories without namespace, the configs without inventory, the namespaces having an inventory but no manager=blackbeard label and the labelled namespaces without inventory. Nothing is fixed.",
+			responses:   map[int]interface{}{http.StatusOK: api.ReconcileReport{}},
+		},
+		{
+			method:      http.MethodPost,
+			path:        "/doctor",
+			handler:     h.Reconcile,
+			tag:         "Namespaces",
+			summary:     "Fix the drift between inventories and namespaces",
+			description: "Report and fix the drift between inventories and namespaces : orphan inventories and configs are deleted, namespaces having an inventory are labelled and labelled namespaces get an inventory generated from the defaults.",
+			responses:   map[int]interface{}{http.StatusOK: api.ReconcileReport{}},
+		},
+		{
+			method:      http.MethodPost,
+			path:        "/bulk/:operation",
+			handler:     h.StartBulk,
+			tag:         "Namespaces",
+			summary:     "Run an operation on many namespaces",
+			description: "Run apply, reset, upgrade or delete on the namespaces matching a label selector, or on all the namespaces, in the background. At most concurrency namespaces are processed at the same time. Once a namespace failed, the namespaces not started yet are skipped unless continueOnError is true. The returned id gives the progress of the operation.",
+			request:     api.BulkOptions{},
+			responses:   map[int]interface{}{http.StatusAccepted: api.BulkReport{}},
+		},
+		{
+			method:      http.MethodGet,
+			path:        "/bulk/:id",
+			handler:     h.GetBulk,
+			tag:         "Namespaces",
+			summary:     "Return the progress of a bulk operation",
+			description: "Return the status of a bulk operation, running until every namespace has been processed, and the result of each namespace. Finished operations are kept for an hour.",
+			responses:   map[int]interface{}{http.StatusOK: api.BulkReport{}},
+		},
+		{
+			method:      http.MethodGet,
+			path:        "/resources/:namespace/:kind",
+			handler:     h.ListResources,
+			tag:         "Resources",
+			summary:     "List the objects of a kind in a namespace",
+			description: "List the pods, deployments, statefulsets, jobs, cronjobs, configmaps, secrets or pvcs of a namespace. Secret values are never returned.",
+			responses:   map[int]interface{}{http.StatusOK: []resource.Object{}},
+		},
+		{
+			method:      http.MethodGet,
+			path:        "/resources/:namespace/:kind/:name",
+			handler:     h.GetResource,
+			tag:         "Resources",
+			summary:     "Return an object of a namespace",
+			description: "Return a pod, deployment, statefulset, job, cronjob, configmap, secret or pvc of a namespace. Secret values are never returned.",
+			responses:   map[int]interface{}{http.StatusOK: resource.Object{}},
+		},
+		{
+			method:      http.MethodDelete,
+			path:        "/resources/:namespace/:kind/:name",
+			handler:     h.DeleteResource,
+			tag:         "Resources",
+			summary:     "Delete an object of a namespace",
+			description: "Delete an object and its dependents, such as the pods of a job. Only namespaces labelled manager=blackbeard are allowed. Applying the inventory again will recreate it.",
+			responses:   map[int]interface{}{http.StatusNoContent: nil},
+		},
+		{
+			method:      http.MethodPost,
+			path:        "/resources/:namespace/:kind/:name/restart",
+			handler:     h.Restart,
+			tag:         "Resources",
+			summary:     "Restart a workload",
+			description: "Recreate the pods of a deployment or a statefulset using a rolling update, like kubectl rollout restart.",
+			responses:   map[int]interface{}{http.StatusNoContent: nil},
+		},
+		{
+			method:      http.MethodGet,
+			path:        "/version",
+			handler:     h.Version,
+			tag:         "Monitoring",
+			summary:     "Return blackbeard, kubernetes and kubectl version",
+			description: "Return blackbeard, kubernetes and kubectl version",
+			responses:   map[int]interface{}{http.StatusOK: api.Version{}},
+		},
+	}
+}
@@ -15,21 +15,48 @@ import (
 type Handler struct {
 	api        api.Api
 	configPath string
+	exec       bool
+	// origins are the origins allowed to open the exec websocket, besides the server host
+	origins []string
 
 	engine *gin.Engine
+	routes []route
+	spec   OpenAPI
+}
+
+// HandlerOption configures optional features of a Handler.
+type HandlerOption func(*Handler)
+
+// WithExec enables the exec websocket, letting clients run commands in the pods of managed namespaces.
+func WithExec() HandlerOption {
+	return func(h *Handler) {
+		h.exec = true
+	}
+}
+
+// WithExecAllowedOrigins allows the pages served from the given origins, such as https://dashboard.example.com,
+// to open the exec websocket. Pages served from the server host are always allowed.
+func WithExecAllowedOrigins(origins ...string) HandlerOption {
+	return func(h *Handler) {
+		h.origins = append(h.origins, origins...)
+	}
 }
 
 // NewHandler create a Handler using defined routes.
 // It takes a client as argument in order to be pass to the handler and be accessible to the HandlerFunc
 // Typically in a CRUD API, the client manage connections to a storage system.
-func NewHandler(api api.Api, configPath string, corsEnable bool) *Handler {
+func NewHandler(api api.Api, configPath string, corsEnable bool, opts ...HandlerOption) *Handler {
 	h := &Handler{
 		api:        api,
 		configPath: configPath,
 	}
 
+	for _, opt := range opts {
+		opt(h)
+	}
+
 	h.engine = gin.New()
-	h.engine.Use(jsonLogMiddleware(), gin.Recovery())
+	h.engine.Use(jsonLogMiddleware(), gin.Recovery(), errorMiddleware())
 
 	if corsEnable == true {
 		config := cors.DefaultConfig()
@@ -39,20 +66,17 @@ func NewHandler(api api.Api, configPath string, corsEnable bool) *Handler {
 		logrus.Info("CORS are enabled")
 	}
 
-	h.engine.GET("/ready", h.HealthCheck)
-	h.engine.GET("/alive", h.HealthCheck)
-	h.engine.POST("/inventories", h.Create)
-	h.engine.GET("/inventories/:namespace", h.Get)
-	h.engine.GET("/inventories/:namespace/status", h.GetStatus)
-	h.engine.POST("/inventories/:namespace/reset", h.Reset)
-	h.engine.GET("/inventories/:namespace/services", h.ListServices)
-	h.engine.GET("/inventories", h.List)
-	//h.engine.GET("/inventories/status", h.GetStatuses)
-	h.engine.GET("/defaults", h.GetDefaults)
-	h.engine.PUT("/inventories/:namespace", h.Update)
-	h.engine.DELETE("/inventories/:namespace", h.Delete)
-	h.engine.DELETE("/resources/:namespace/jobs/:resource", h.DeleteResource)
-	h.engine.GET("/version", h.Version)
+	h.routes = h.newRoutes()
+
+	for _, r := range h.routes {
+		h.engine.Handle(r.method, r.path, r.handler)
+	}
+
+	h.spec = newOpenAPI(h.routes)
+
+	h.engine.GET("/openapi.json", h.ServeOpenAPI)
+	h.engine.GET("/docs", h.ServeDocs)
+	h.engine.StaticFS("/docs/assets", docsAssets())
 
 	return h
 }
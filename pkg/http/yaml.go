@@ -0,0 +1,73 @@
+package http
+
+import (
+	"io"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"sigs.k8s.io/yaml"
+
+	"github.com/Meetic/blackbeard/pkg/errors"
+)
+
+const mimeYAML = "application/yaml"
+
+// yamlMIMEs are the media types accepted for yaml requests and responses
+var yamlMIMEs = []string{mimeYAML, "application/x-yaml", "text/yaml"}
+
+// acceptsYAML returns true if the Accept header of the request prefers yaml over json
+func acceptsYAML(c *gin.Context) bool {
+	format := c.NegotiateFormat(append([]string{gin.MIMEJSON}, yamlMIMEs...)...)
+
+	for _, mime := range yamlMIMEs {
+		if format == mime {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isYAML returns true if the body of the request is yaml, according to its Content-Type header
+func isYAML(c *gin.Context) bool {
+	contentType := strings.ToLower(c.ContentType())
+
+	for _, mime := range yamlMIMEs {
+		if contentType == mime {
+			return true
+		}
+	}
+
+	return false
+}
+
+// negotiate writes obj in yaml if the client accepts it, in json otherwise.
+// obj is encoded using its json field names in both cases.
+func negotiate(c *gin.Context, code int, obj interface{}) {
+	if !acceptsYAML(c) {
+		c.JSON(code, obj)
+		return
+	}
+
+	data, err := yaml.Marshal(obj)
+	if err != nil {
+		c.Error(errors.Wrap(err, errors.Internal, "unable to encode the response in yaml"))
+		return
+	}
+
+	c.Data(code, mimeYAML+"; charset=utf-8", data)
+}
+
+// bind decodes the body of the request in obj, from yaml or json according to its Content-Type header
+func bind(c *gin.Context, obj interface{}) error {
+	if !isYAML(c) {
+		return c.ShouldBindJSON(obj)
+	}
+
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		return err
+	}
+
+	return yaml.Unmarshal(body, obj)
+}
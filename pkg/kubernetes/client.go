@@ -8,8 +8,10 @@ import (
 	"strings"
 
 	"github.com/sirupsen/logrus"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/oidc"
+	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 
 	"github.com/Meetic/blackbeard/pkg/resource"
@@ -22,6 +24,7 @@ const (
 
 type Client struct {
 	kubernetes   kubernetes.Interface
+	config       *rest.Config
 	namespaces   resource.NamespaceRepository
 	pods         resource.PodRepository
 	deployments  resource.DeploymentRepository
@@ -29,6 +32,8 @@ type Client struct {
 	services     resource.ServiceRepository
 	cluster      resource.ClusterRepository
 	jobs         resource.JobRepository
+	objects      resource.ObjectRepository
+	drifts       resource.DriftRepository
 }
 
 // NewClient return a new kubernetes client
@@ -47,15 +52,23 @@ func NewClient(configFilePath string) (*Client, error) {
 		return &Client{}, fmt.Errorf("kubernetes new client for config : %s", err.Error())
 	}
 
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return &Client{}, fmt.Errorf("kubernetes new dynamic client for config : %s", err.Error())
+	}
+
 	return &Client{
 		kubernetes:   clientSet,
+		config:       config,
 		namespaces:   NewNamespaceRepository(clientSet),
 		pods:         NewPodRepository(clientSet),
 		deployments:  NewDeploymentRepository(clientSet),
 		statefulsets: NewStatefulsetRepository(clientSet),
-		services:     NewServiceRepository(clientSet, GetKubernetesHost(configFilePath)),
+		services:     NewServiceRepository(clientSet, dynamicClient, GetKubernetesHost(configFilePath)),
 		cluster:      NewClusterRepository(),
 		jobs:         NewJobRepository(clientSet),
+		objects:      NewObjectRepository(clientSet),
+		drifts:       NewDriftRepository(dynamicClient),
 	}, nil
 }
 
@@ -63,6 +76,29 @@ func (c *Client) Jobs() resource.JobRepository {
 	return c.jobs
 }
 
+func (c *Client) Objects() resource.ObjectRepository {
+	return c.objects
+}
+
+func (c *Client) Drifts() resource.DriftRepository {
+	return c.drifts
+}
+
+// ReadinessRules returns the readiness rules of cronjobs, daemonsets and persistent volume claims
+func (c *Client) ReadinessRules() []resource.ReadinessRule {
+	return NewReadinessRules(c.kubernetes)
+}
+
+// Execs returns an ExecRepository using the client config
+func (c *Client) Execs() resource.ExecRepository {
+	return NewExecRepository(c.kubernetes, c.config)
+}
+
+// PortForwards returns a PortForwardRepository using the client config
+func (c *Client) PortForwards() resource.PortForwardRepository {
+	return NewPortForwardRepository(c.kubernetes, c.config)
+}
+
 func (c *Client) Namespaces() resource.NamespaceRepository {
 	return c.namespaces
 }
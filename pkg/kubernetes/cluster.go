@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"os/exec"
 
+	"github.com/Meetic/blackbeard/pkg/errors"
 	"github.com/Meetic/blackbeard/pkg/resource"
 )
 
@@ -18,7 +19,7 @@ func (r ClusterRepository) GetVersion() (*resource.Version, error) {
 	result, err := cmd.Output()
 
 	if err != nil {
-		return nil, err
+		return nil, errors.Wrap(err, errors.Upstream, "unable to get kubectl version")
 	}
 
 	var v resource.Version
@@ -26,7 +27,7 @@ func (r ClusterRepository) GetVersion() (*resource.Version, error) {
 	err = json.Unmarshal(result, &v)
 
 	if err != nil {
-		return nil, err
+		return nil, errors.Wrap(err, errors.Upstream, "unable to read kubectl version")
 	}
 
 	return &v, nil
@@ -2,9 +2,12 @@ package kubernetes
 
 import (
 	"context"
-	"fmt"
+	"encoding/json"
+	"time"
 
+	appsv1 "k8s.io/api/apps/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes"
 
 	"github.com/Meetic/blackbeard/pkg/resource"
@@ -20,12 +23,14 @@ func NewDeploymentRepository(kubernetes kubernetes.Interface) resource.Deploymen
 	}
 }
 
-// List return a list of deployment with their status Ready or NotReady
+// List return a list of deployment with their status Ready or NotReady.
+// A deployment is Ready once its rollout is complete and all its replicas are ready, so that
+// waiting for a namespace after a restart or an update waits for the new pods.
 func (r *deploymentRepository) List(namespace string) (resource.Deployments, error) {
 	dl, err := r.AppsV1().Deployments(namespace).List(context.Background(), v1.ListOptions{})
 
 	if err != nil {
-		return nil, fmt.Errorf("unable to list deployments: %v", err)
+		return nil, wrapError(err, "unable to list deployments")
 	}
 
 	dps := make(resource.Deployments, 0)
@@ -33,15 +38,60 @@ func (r *deploymentRepository) List(namespace string) (resource.Deployments, err
 	for _, dp := range dl.Items {
 		status := resource.DeploymentNotReady
 
-		if dp.Status.ReadyReplicas == dp.Status.Replicas {
+		if deploymentRolledOut(dp) {
 			status = resource.DeploymentReady
 		}
 
+		images := make(map[string]string, len(dp.Spec.Template.Spec.Containers))
+		for _, c := range dp.Spec.Template.Spec.Containers {
+			images[c.Name] = c.Image
+		}
+
 		dps = append(dps, resource.Deployment{
-			Name:   dp.Name,
-			Status: status,
+			Name:        dp.Name,
+			Status:      status,
+			Annotations: dp.Annotations,
+			Images:      images,
 		})
 	}
 
 	return dps, nil
 }
+
+// deploymentRolledOut returns true if the controller observed the last spec of the deployment,
+// and every replica is up to date and ready
+func deploymentRolledOut(dp appsv1.Deployment) bool {
+	return dp.Status.ObservedGeneration >= dp.Generation &&
+		dp.Status.UpdatedReplicas == dp.Status.Replicas &&
+		dp.Status.ReadyReplicas == dp.Status.Replicas
+}
+
+// Restart triggers a rollout of the deployment, like kubectl rollout restart does
+func (r *deploymentRepository) Restart(namespace, name string) error {
+	_, err := r.AppsV1().Deployments(namespace).Patch(
+		context.Background(),
+		name,
+		types.StrategicMergePatchType,
+		restartPatch(),
+		v1.PatchOptions{},
+	)
+
+	return wrapError(err, "unable to restart deployment %s", name)
+}
+
+// restartPatch returns a patch changing the pod template annotations so pods are recreated
+func restartPatch() []byte {
+	patch, _ := json.Marshal(map[string]interface{}{
+		"spec": map[string]interface{}{
+			"template": map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"annotations": map[string]string{
+						"kubectl.kubernetes.io/restartedAt": time.Now().Format(time.RFC3339),
+					},
+				},
+			},
+		},
+	})
+
+	return patch
+}
@@ -0,0 +1,245 @@
+package kubernetes
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+
+	kerr "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	kresource "k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/dynamic"
+	"sigs.k8s.io/yaml"
+
+	"github.com/Meetic/blackbeard/pkg/errors"
+	"github.com/Meetic/blackbeard/pkg/resource"
+)
+
+type driftRepository struct {
+	dynamic dynamic.Interface
+}
+
+// NewDriftRepository returns a DriftRepository reading the live objects using the given dynamic client.
+// The resource of each object is guessed from its kind, as kubectl does for the built-in kinds.
+func NewDriftRepository(dynamic dynamic.Interface) resource.DriftRepository {
+	return &driftRepository{
+		dynamic: dynamic,
+	}
+}
+
+// Compare compares the objects of the manifests with the live objects of the namespace.
+// The status of the objects is ignored.
+func (r *driftRepository) Compare(namespace string, manifests []string) ([]resource.ObjectDrift, error) {
+	objects, err := decodeManifests(manifests)
+	if err != nil {
+		return nil, err
+	}
+
+	var drifts []resource.ObjectDrift
+
+	for _, desired := range objects {
+		gvr, _ := meta.UnsafeGuessKindToResource(desired.GroupVersionKind())
+
+		drift := resource.ObjectDrift{Kind: desired.GetKind(), Name: desired.GetName()}
+
+		live, err := r.dynamic.Resource(gvr).Namespace(namespace).Get(context.Background(), desired.GetName(), metav1.GetOptions{})
+		if kerr.IsNotFound(err) {
+			drift.Missing = true
+			drifts = append(drifts, drift)
+			continue
+		}
+		if err != nil {
+			return nil, wrapError(err, "unable to get %s %s", desired.GetKind(), desired.GetName())
+		}
+
+		delete(desired.Object, "status")
+		if m, ok := desired.Object["metadata"].(map[string]interface{}); ok {
+			delete(m, "namespace")
+		}
+		if desired.GetKind() == "Secret" {
+			normalizeSecret(desired.Object)
+		}
+
+		drift.Fields = compareFields("", desired.Object, live.Object)
+		drifts = append(drifts, drift)
+	}
+
+	return drifts, nil
+}
+
+// decodeManifests returns the objects of yaml manifests, each manifest possibly containing several documents
+func decodeManifests(manifests []string) ([]*unstructured.Unstructured, error) {
+	var objects []*unstructured.Unstructured
+
+	for _, manifest := range manifests {
+		reader := utilyaml.NewYAMLReader(bufio.NewReader(strings.NewReader(manifest)))
+
+		for {
+			doc, err := reader.Read()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return nil, errors.Wrap(err, errors.Invalid, "invalid config")
+			}
+
+			data, err := yaml.YAMLToJSON(doc)
+			if err != nil {
+				return nil, errors.Wrap(err, errors.Invalid, "invalid config")
+			}
+
+			if strings.TrimSpace(string(data)) == "null" {
+				continue
+			}
+
+			obj, _, err := unstructured.UnstructuredJSONScheme.Decode(data, nil, nil)
+			if err != nil {
+				return nil, errors.Wrap(err, errors.Invalid, "invalid config")
+			}
+
+			switch o := obj.(type) {
+			case *unstructured.Unstructured:
+				objects = append(objects, o)
+			case *unstructured.UnstructuredList:
+				for i := range o.Items {
+					objects = append(objects, &o.Items[i])
+				}
+			}
+		}
+	}
+
+	return objects, nil
+}
+
+// normalizeSecret moves the stringData of a secret into its data, base64 encoded, as kubernetes does on write.
+// The stringData values take precedence over the data ones.
+func normalizeSecret(secret map[string]interface{}) {
+	stringData, ok := secret["stringData"].(map[string]interface{})
+	delete(secret, "stringData")
+	if !ok || len(stringData) == 0 {
+		return
+	}
+
+	data, ok := secret["data"].(map[string]interface{})
+	if !ok {
+		data = make(map[string]interface{}, len(stringData))
+		secret["data"] = data
+	}
+
+	for k, v := range stringData {
+		data[k] = base64.StdEncoding.EncodeToString([]byte(fmt.Sprint(v)))
+	}
+}
+
+// compareFields returns the fields of desired whose value differs in live
+func compareFields(path string, desired, live interface{}) []resource.FieldDrift {
+	switch d := desired.(type) {
+	case nil:
+		return nil
+	case map[string]interface{}:
+		l, ok := live.(map[string]interface{})
+		if !ok {
+			return []resource.FieldDrift{{Path: path, Expected: desired, Actual: live}}
+		}
+
+		keys := make([]string, 0, len(d))
+		for k := range d {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		var drifts []resource.FieldDrift
+		for _, k := range keys {
+			p := k
+			if path != "" {
+				p = path + "." + k
+			}
+
+			if _, ok := l[k]; !ok {
+				if !isEmpty(d[k]) {
+					drifts = append(drifts, resource.FieldDrift{Path: p, Expected: d[k]})
+				}
+				continue
+			}
+
+			drifts = append(drifts, compareFields(p, d[k], l[k])...)
+		}
+
+		return drifts
+	case []interface{}:
+		l, ok := live.([]interface{})
+		if !ok || len(l) != len(d) {
+			return []resource.FieldDrift{{Path: path, Expected: desired, Actual: live}}
+		}
+
+		var drifts []resource.FieldDrift
+		for i := range d {
+			drifts = append(drifts, compareFields(fmt.Sprintf("%s[%d]", path, i), d[i], l[i])...)
+		}
+
+		return drifts
+	}
+
+	if equalValues(path, desired, live) {
+		return nil
+	}
+
+	return []resource.FieldDrift{{Path: path, Expected: desired, Actual: live}}
+}
+
+// isQuantity returns true if the field at path holds a quantity, normalized by kubernetes
+func isQuantity(path string) bool {
+	return strings.Contains(path, ".resources.") ||
+		strings.HasPrefix(path, "spec.hard.") ||
+		strings.HasPrefix(path, "spec.limits[")
+}
+
+// isEmpty returns true for the values omitted by kubernetes : null, empty maps and empty lists
+func isEmpty(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case map[string]interface{}:
+		return len(v) == 0
+	case []interface{}:
+		return len(v) == 0
+	}
+
+	return false
+}
+
+// equalValues compares scalar values. Numbers are compared whatever their type, and the quantities of
+// compute resources, quotas and limit ranges whatever their format ("0.5" and "500m" are equal).
+func equalValues(path string, desired, live interface{}) bool {
+	if reflect.DeepEqual(desired, live) {
+		return true
+	}
+
+	if fmt.Sprint(desired) == fmt.Sprint(live) {
+		return true
+	}
+
+	if !isQuantity(path) {
+		return false
+	}
+
+	d, err := kresource.ParseQuantity(fmt.Sprint(desired))
+	if err != nil {
+		return false
+	}
+
+	l, err := kresource.ParseQuantity(fmt.Sprint(live))
+	if err != nil {
+		return false
+	}
+
+	return d.Cmp(l) == 0
+}
@@ -0,0 +1,36 @@
+package kubernetes
+
+import (
+	kerr "k8s.io/apimachinery/pkg/api/errors"
+
+	"github.com/Meetic/blackbeard/pkg/errors"
+)
+
+// wrapError converts an error returned by the kubernetes api into a typed error.
+// The kind is inferred from the api status reason. Errors without a known reason are Upstream errors.
+func wrapError(err error, format string, args ...interface{}) error {
+	if err == nil {
+		return nil
+	}
+
+	var kind errors.Kind
+
+	switch {
+	case kerr.IsNotFound(err):
+		kind = errors.NotFound
+	case kerr.IsAlreadyExists(err):
+		kind = errors.AlreadyExists
+	case kerr.IsConflict(err):
+		kind = errors.Conflict
+	case kerr.IsForbidden(err), kerr.IsUnauthorized(err):
+		kind = errors.Forbidden
+	case kerr.IsInvalid(err), kerr.IsBadRequest(err):
+		kind = errors.Invalid
+	case kerr.IsTimeout(err), kerr.IsServerTimeout(err):
+		kind = errors.Timeout
+	default:
+		kind = errors.Upstream
+	}
+
+	return errors.Wrap(err, kind, format, args...)
+}
@@ -0,0 +1,125 @@
+package kubernetes
+
+import (
+	"context"
+	goerrors "errors"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/remotecommand"
+	utilexec "k8s.io/client-go/util/exec"
+
+	"github.com/Meetic/blackbeard/pkg/errors"
+	"github.com/Meetic/blackbeard/pkg/resource"
+)
+
+type execRepository struct {
+	kubernetes kubernetes.Interface
+	config     *rest.Config
+}
+
+// NewExecRepository returns a new ExecRepository.
+// The rest config is used to open SPDY connections to the pods.
+func NewExecRepository(kubernetes kubernetes.Interface, config *rest.Config) resource.ExecRepository {
+	return &execRepository{
+		kubernetes: kubernetes,
+		config:     config,
+	}
+}
+
+// ResolvePod returns the pod given as pod/NAME, or a ready pod of a workload
+func (r *execRepository) ResolvePod(namespace, target string) (string, error) {
+	if kind, name, ok := strings.Cut(target, "/"); ok && (kind == "pod" || kind == "pods" || kind == "po") {
+		return name, nil
+	}
+
+	selector, err := workloadSelector(context.Background(), r.kubernetes, namespace, target)
+	if err != nil {
+		return "", err
+	}
+
+	pods, err := r.kubernetes.CoreV1().Pods(namespace).List(context.Background(), metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return "", wrapError(err, "unable to list pods of %s", target)
+	}
+
+	for _, pod := range pods.Items {
+		if podReady(pod) {
+			return pod.Name, nil
+		}
+	}
+
+	return "", errors.New(errors.NotFound, "no ready pod found for %s", target)
+}
+
+// Exec runs a command in a pod using the remotecommand protocol
+func (r *execRepository) Exec(ctx context.Context, namespace, pod string, opts resource.ExecOptions) error {
+	req := r.kubernetes.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Namespace(namespace).
+		Name(pod).
+		SubResource("exec").
+		VersionedParams(&v1.PodExecOptions{
+			Container: opts.Container,
+			Command:   opts.Command,
+			Stdin:     opts.Stdin != nil,
+			Stdout:    opts.Stdout != nil,
+			Stderr:    opts.Stderr != nil && !opts.TTY,
+			TTY:       opts.TTY,
+		}, scheme.ParameterCodec)
+
+	executor, err := remotecommand.NewSPDYExecutor(r.config, "POST", req.URL())
+	if err != nil {
+		return errors.Wrap(err, errors.Internal, "unable to create the exec transport")
+	}
+
+	streamOpts := remotecommand.StreamOptions{
+		Stdin:  opts.Stdin,
+		Stdout: opts.Stdout,
+		Tty:    opts.TTY,
+	}
+
+	if !opts.TTY {
+		streamOpts.Stderr = opts.Stderr
+	}
+
+	if opts.Resize != nil {
+		streamOpts.TerminalSizeQueue = &sizeQueue{ctx: ctx, sizes: opts.Resize}
+	}
+
+	err = executor.StreamWithContext(ctx, streamOpts)
+
+	var exitErr utilexec.ExitError
+	if goerrors.As(err, &exitErr) && exitErr.Exited() {
+		return resource.NewExitError(exitErr.ExitStatus())
+	}
+
+	if err != nil && ctx.Err() == nil {
+		return errors.Wrap(err, errors.Upstream, "unable to run command in pod %s", pod)
+	}
+
+	return nil
+}
+
+// sizeQueue adapts a channel of terminal sizes to a remotecommand.TerminalSizeQueue
+type sizeQueue struct {
+	ctx   context.Context
+	sizes <-chan resource.TerminalSize
+}
+
+// Next returns the next terminal size, or nil once there are no more sizes
+func (q *sizeQueue) Next() *remotecommand.TerminalSize {
+	select {
+	case size, ok := <-q.sizes:
+		if !ok {
+			return nil
+		}
+		return &remotecommand.TerminalSize{Width: size.Width, Height: size.Height}
+	case <-q.ctx.Done():
+		return nil
+	}
+}
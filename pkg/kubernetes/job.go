@@ -2,9 +2,9 @@ package kubernetes
 
 import (
 	"context"
-	"fmt"
 
 	"k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
 
 	"github.com/Meetic/blackbeard/pkg/resource"
 
@@ -24,39 +24,65 @@ func NewJobRepository(kubernetes kubernetes.Interface) resource.JobRepository {
 	}
 }
 
+// List returns the jobs of a namespace with their status.
+// A job is Ready once complete and Failed once it reached its backoff limit or deadline.
+// Jobs created by a cronjob are not returned, cronjobs are evaluated on their own.
 func (c *jobRepository) List(namespace string) (resource.Jobs, error) {
 	jl, err := c.kubernetes.BatchV1().Jobs(namespace).List(context.Background(), metav1.ListOptions{})
 
 	if err != nil {
-		return nil, fmt.Errorf("unable to list jobs: %v", err)
+		return nil, wrapError(err, "unable to list jobs")
 	}
 
 	jobs := make(resource.Jobs, 0)
 
 	for _, job := range jl.Items {
-		status := resource.JobNotReady
-
-		if len(job.Status.Conditions) == 0 {
+		if ownedByCronJob(job.OwnerReferences) {
 			continue
 		}
 
-		if job.Status.Conditions[len(job.Status.Conditions)-1].Type == v1.JobComplete {
-			status = resource.JobReady
-		}
-
 		jobs = append(jobs, resource.Job{
-			Name:   job.Name,
-			Status: status,
+			Name:        job.Name,
+			Status:      jobStatus(job),
+			Annotations: job.Annotations,
 		})
 	}
 
 	return jobs, nil
 }
 
+// jobStatus returns the status of a job from its conditions
+func jobStatus(job v1.Job) resource.JobStatus {
+	for _, c := range job.Status.Conditions {
+		if c.Status != corev1.ConditionTrue {
+			continue
+		}
+
+		switch c.Type {
+		case v1.JobComplete:
+			return resource.JobReady
+		case v1.JobFailed:
+			return resource.JobFailed
+		}
+	}
+
+	return resource.JobNotReady
+}
+
+func ownedByCronJob(owners []metav1.OwnerReference) bool {
+	for _, o := range owners {
+		if o.Kind == "CronJob" {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (c *jobRepository) Delete(namespace, resourceName string) error {
 	pp := metav1.DeletePropagationBackground
 	if err := c.kubernetes.BatchV1().Jobs(namespace).Delete(context.Background(), resourceName, metav1.DeleteOptions{PropagationPolicy: &pp}); err != nil {
-		return err
+		return wrapError(err, "unable to delete job %s", resourceName)
 	}
 	return nil
 }
@@ -3,19 +3,25 @@ package kubernetes
 import (
 	"bufio"
 	"context"
-	"errors"
+	"encoding/json"
 	"fmt"
 	"os/exec"
 	"path/filepath"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/sirupsen/logrus"
 	"k8s.io/api/core/v1"
 	kerr "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/kubernetes"
 
+	"github.com/Meetic/blackbeard/pkg/errors"
 	"github.com/Meetic/blackbeard/pkg/resource"
 )
 
@@ -25,6 +31,8 @@ const (
 
 type namespaceRepository struct {
 	kubernetes kubernetes.Interface
+	// resourceVersion is the last resource version received by Watch
+	resourceVersion string
 }
 
 // NewNamespaceRepository returns a new NamespaceRepository.
@@ -35,19 +43,82 @@ func NewNamespaceRepository(kubernetes kubernetes.Interface) resource.NamespaceR
 	}
 }
 
-// Create creates a namespace
-func (ns *namespaceRepository) Create(namespace string) error {
+// Create creates a namespace with the given labels and annotations, and attaches the objects of the given profile to it.
+// The manager=blackbeard label is always set, whatever the given labels.
+func (ns *namespaceRepository) Create(namespace string, metadata resource.NamespaceMetadata, profile *resource.NamespaceProfile) error {
+	if err := validateMetadata(metadata); err != nil {
+		return err
+	}
+
+	labels := make(map[string]string, len(metadata.Labels)+1)
+	for k, v := range metadata.Labels {
+		labels[k] = v
+	}
+	labels[resource.LabelManager] = resource.ManagerBlackbeard
+
 	_, err := ns.kubernetes.CoreV1().Namespaces().Create(
 		context.Background(),
 		&v1.Namespace{
 			ObjectMeta: metav1.ObjectMeta{
-				Name:   namespace,
-				Labels: map[string]string{"manager": "blackbeard"},
+				Name:        namespace,
+				Labels:      labels,
+				Annotations: metadata.Annotations,
 			},
 		},
 		metav1.CreateOptions{},
 	)
-	return err
+
+	if err != nil {
+		return wrapError(err, "unable to create namespace %s", namespace)
+	}
+
+	if profile == nil {
+		return nil
+	}
+
+	return ns.ApplyProfile(namespace, profile)
+}
+
+// validateMetadata checks the syntax of labels and annotations before sending them to kubernetes
+func validateMetadata(metadata resource.NamespaceMetadata) error {
+	var invalid []string
+
+	for k, v := range metadata.Labels {
+		for _, msg := range append(validation.IsQualifiedName(k), validation.IsValidLabelValue(v)...) {
+			invalid = append(invalid, fmt.Sprintf("label %s=%s: %s", k, v, msg))
+		}
+	}
+
+	for k := range metadata.Annotations {
+		for _, msg := range validation.IsQualifiedName(strings.ToLower(k)) {
+			invalid = append(invalid, fmt.Sprintf("annotation %s: %s", k, msg))
+		}
+	}
+
+	if len(invalid) > 0 {
+		sort.Strings(invalid)
+		return errors.WithDetail(
+			errors.New(errors.Invalid, "invalid namespace metadata"),
+			"invalid", invalid,
+		)
+	}
+
+	return nil
+}
+
+// managedSelector returns the label selector of the namespaces managed by blackbeard, restricted by the given selector
+func managedSelector(selector string) (string, error) {
+	managed := resource.LabelManager + "=" + resource.ManagerBlackbeard
+
+	if selector == "" {
+		return managed, nil
+	}
+
+	if _, err := labels.Parse(selector); err != nil {
+		return "", errors.Wrap(err, errors.Invalid, "invalid label selector %q", selector)
+	}
+
+	return managed + "," + selector, nil
 }
 
 // Get namespace with status
@@ -55,84 +126,180 @@ func (ns *namespaceRepository) Get(namespace string) (*resource.Namespace, error
 	n, err := ns.kubernetes.CoreV1().Namespaces().Get(context.Background(), namespace, metav1.GetOptions{})
 
 	if err != nil {
-		return nil, err
+		return nil, wrapError(err, "unable to get namespace %s", namespace)
 	}
 
-	return &resource.Namespace{Name: n.GetName(), Phase: string(n.Status.Phase)}, nil
+	return &resource.Namespace{
+		Name:        n.GetName(),
+		Phase:       string(n.Status.Phase),
+		Labels:      n.GetLabels(),
+		Annotations: n.GetAnnotations(),
+	}, nil
 }
 
-// Delete deletes a given namespace
+// Delete deletes a given namespace.
+// Deleting a namespace that does not exist is not an error.
 func (ns *namespaceRepository) Delete(namespace string) error {
 	err := ns.kubernetes.CoreV1().Namespaces().Delete(context.Background(), namespace, metav1.DeleteOptions{})
 
-	switch t := err.(type) {
-	case *kerr.StatusError:
-		return nil
-	case *kerr.UnexpectedObjectError:
+	if err == nil || kerr.IsNotFound(err) {
 		return nil
-	default:
-		return t
 	}
+
+	return wrapError(err, "unable to delete namespace %s", namespace)
 }
 
 // List returns a slice of Namespace.
 // Name is the namespace name from Kubernetes.
 // Phase is the status phase.
 // List returns an error if the namespace list could not be get from Kubernetes cluster.
-func (ns *namespaceRepository) List() ([]resource.Namespace, error) {
+// Only the namespaces matching the given label selector are returned, if it is not empty.
+func (ns *namespaceRepository) List(selector string) ([]resource.Namespace, error) {
+	selector, err := managedSelector(selector)
+	if err != nil {
+		return nil, err
+	}
+
 	nsList, err := ns.kubernetes.CoreV1().Namespaces().List(
 		context.Background(),
-		metav1.ListOptions{LabelSelector: "manager=blackbeard"},
+		metav1.ListOptions{LabelSelector: selector},
 	)
 
 	if err != nil {
-		return nil, err
+		return nil, wrapError(err, "unable to list namespaces")
 	}
 
 	var namespaces []resource.Namespace
 	for _, ns := range nsList.Items {
 		namespaces = append(namespaces, resource.Namespace{
-			Name:  ns.GetName(),
-			Phase: string(ns.Status.Phase),
+			Name:        ns.GetName(),
+			Phase:       string(ns.Status.Phase),
+			Labels:      ns.GetLabels(),
+			Annotations: ns.GetAnnotations(),
 		})
 	}
 
 	return namespaces, nil
 }
 
+// Annotate adds annotations to a namespace using a merge patch
+func (ns *namespaceRepository) Annotate(namespace string, annotations map[string]string) error {
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{"annotations": annotations},
+	})
+	if err != nil {
+		return errors.Wrap(err, errors.Invalid, "invalid annotations")
+	}
+
+	_, err = ns.kubernetes.CoreV1().Namespaces().Patch(
+		context.Background(),
+		namespace,
+		types.MergePatchType,
+		patch,
+		metav1.PatchOptions{},
+	)
+
+	return wrapError(err, "unable to annotate namespace %s", namespace)
+}
+
+// Adopt adds the manager=blackbeard label to an existing namespace
+func (ns *namespaceRepository) Adopt(namespace string) error {
+	return wrapError(
+		ns.patchLabels(namespace, map[string]interface{}{resource.LabelManager: resource.ManagerBlackbeard}),
+		"unable to adopt namespace %s", namespace,
+	)
+}
+
+// Detach removes the manager=blackbeard label from a namespace. Its workloads are left untouched.
+func (ns *namespaceRepository) Detach(namespace string) error {
+	return wrapError(
+		ns.patchLabels(namespace, map[string]interface{}{resource.LabelManager: nil}),
+		"unable to detach namespace %s", namespace,
+	)
+}
+
+// patchLabels changes the labels of a namespace using a merge patch. A nil value removes a label.
+func (ns *namespaceRepository) patchLabels(namespace string, labels map[string]interface{}) error {
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{"labels": labels},
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = ns.kubernetes.CoreV1().Namespaces().Patch(
+		context.Background(),
+		namespace,
+		types.MergePatchType,
+		patch,
+		metav1.PatchOptions{},
+	)
+
+	return err
+}
+
 // ApplyConfig loads configuration files into kubernetes
 func (ns *namespaceRepository) ApplyConfig(namespace, configPath string) error {
 
 	err := execute(fmt.Sprintf("kubectl apply -f %s -n %s", filepath.Join(configPath, namespace), namespace), timeout)
 	if err != nil {
-		return fmt.Errorf("the namespace could not be configured : %v", err)
+		return errors.Wrap(err, errors.KindOf(err), "the namespace could not be configured")
 	}
 
 	return nil
 }
 
-// Watch namespace events and send it to events channel
+// Watch namespace events and send it to events channel.
+// The first call lists the managed namespaces and watches them from the resource version of this list, so no
+// event is published for the existing namespaces. Following calls resume watching from the last received
+// resource version, so no event is lost between two calls. If this version is too old, namespaces are listed again.
+// A NamespaceSynced event is published after each list, since the namespaces deleted before were not notified.
 func (ns *namespaceRepository) Watch(events chan<- resource.NamespaceEvent) error {
+	selector := resource.LabelManager + "=" + resource.ManagerBlackbeard
+
+	if ns.resourceVersion == "" {
+		list, err := ns.kubernetes.CoreV1().Namespaces().List(
+			context.Background(),
+			metav1.ListOptions{LabelSelector: selector},
+		)
+		if err != nil {
+			return wrapError(err, "unable to list namespaces")
+		}
+
+		ns.resourceVersion = list.ResourceVersion
+
+		events <- resource.NamespaceEvent{Type: resource.NamespaceSynced}
+	}
 
 	watcher, err := ns.kubernetes.CoreV1().Namespaces().Watch(
 		context.Background(),
-		metav1.ListOptions{LabelSelector: "manager=blackbeard"},
+		metav1.ListOptions{LabelSelector: selector, ResourceVersion: ns.resourceVersion},
 	)
 
 	if err != nil {
 		logrus.Errorf("error when watching namespace : %s", err.Error())
-		return err
+		return wrapError(err, "unable to watch namespaces")
 	}
+	defer watcher.Stop()
 
 	for event := range watcher.ResultChan() {
-		n := event.Object.(*v1.Namespace)
+		if event.Type == watch.Error {
+			err := kerr.FromObject(event.Object)
+			if kerr.IsResourceExpired(err) || kerr.IsGone(err) {
+				ns.resourceVersion = ""
+				return nil
+			}
+
+			return wrapError(err, "unable to watch namespaces")
+		}
 
-		// prevent publishing event ADDED for previous created namespaces
-		elapsedTime := time.Now().Sub(n.ObjectMeta.CreationTimestamp.Time)
-		if elapsedTime > 5*time.Minute && event.Type == watch.Added {
+		n, ok := event.Object.(*v1.Namespace)
+		if !ok {
 			continue
 		}
 
+		ns.resourceVersion = n.ResourceVersion
+
 		events <- resource.NamespaceEvent{
 			Namespace: n.Name,
 			Type:      string(event.Type),
@@ -142,6 +309,8 @@ func (ns *namespaceRepository) Watch(events chan<- resource.NamespaceEvent) erro
 	return nil
 }
 
+// execute runs a shell command, logging its output. The command is killed if it does not finish within t,
+// and a Timeout error is returned. Other failures are Upstream errors.
 func execute(c string, t time.Duration) error {
 
 	cmd := exec.Command("/bin/sh", "-c", c)
@@ -149,7 +318,7 @@ func execute(c string, t time.Duration) error {
 	cmdReader, err := cmd.StdoutPipe()
 	if err != nil {
 		logrus.Warn("Error creating StdoutPipe for Cmd")
-		return err
+		return errors.Wrap(err, errors.Upstream, "the command could not be started")
 	}
 
 	scanner := bufio.NewScanner(cmdReader)
@@ -162,33 +331,32 @@ func execute(c string, t time.Duration) error {
 	// Start process. Exit code 127 if process fail to start.
 	if err := cmd.Start(); err != nil {
 		logrus.Warn("Error stating Cmd")
-		return err
-	}
-
-	var timer *time.Timer
-	if t > 0 {
-		timer = time.NewTimer(t)
-		go func(timer *time.Timer, cmd *exec.Cmd) {
-			//TODO: use a chan and select pattern to output the error
-			for range timer.C {
-				e := cmd.Process.Kill()
-				if e != nil {
-					err = errors.New("the command has timeout but the process could not be killed")
-				} else {
-					err = errors.New("the command timed out")
-				}
-			}
-		}(timer, cmd)
+		return errors.Wrap(err, errors.Upstream, "the command could not be started")
 	}
 
-	err = cmd.Wait()
+	done := make(chan error, 1)
+	go func() {
+		done <- cmd.Wait()
+	}()
 
+	var expired <-chan time.Time
 	if t > 0 {
-		timer.Stop()
+		timer := time.NewTimer(t)
+		defer timer.Stop()
+		expired = timer.C
 	}
 
-	if err != nil {
-		return errors.New("the command did not succeed")
+	select {
+	case err := <-done:
+		if err != nil {
+			return errors.Wrap(err, errors.Upstream, "the command did not succeed")
+		}
+	case <-expired:
+		if err := cmd.Process.Kill(); err != nil {
+			return errors.Wrap(err, errors.Timeout, "the command has timeout but the process could not be killed")
+		}
+		<-done
+		return errors.New(errors.Timeout, "the command timed out")
 	}
 
 	return nil
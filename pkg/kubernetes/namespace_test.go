@@ -0,0 +1,22 @@
+package kubernetes
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Meetic/blackbeard/pkg/errors"
+)
+
+func TestExecute(t *testing.T) {
+	assert.Nil(t, execute("echo applied", time.Second))
+
+	err := execute("exit 3", time.Second)
+	assert.True(t, errors.Is(err, errors.Upstream))
+
+	start := time.Now()
+	err = execute("sleep 5", 50*time.Millisecond)
+	assert.True(t, errors.Is(err, errors.Timeout))
+	assert.Less(t, time.Since(start), time.Second)
+}
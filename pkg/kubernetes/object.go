@@ -0,0 +1,165 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/Meetic/blackbeard/pkg/errors"
+	"github.com/Meetic/blackbeard/pkg/resource"
+)
+
+type objectRepository struct {
+	kubernetes kubernetes.Interface
+}
+
+// NewObjectRepository returns a new ObjectRepository.
+// The parameter is a go-client kubernetes client.
+func NewObjectRepository(kubernetes kubernetes.Interface) resource.ObjectRepository {
+	return &objectRepository{
+		kubernetes: kubernetes,
+	}
+}
+
+// List returns the objects of the given kind in a namespace
+func (r *objectRepository) List(namespace, kind string) ([]resource.Object, error) {
+	return r.list(namespace, kind, metav1.ListOptions{})
+}
+
+func (r *objectRepository) list(namespace, kind string, opts metav1.ListOptions) ([]resource.Object, error) {
+	ctx := context.Background()
+	objects := make([]resource.Object, 0)
+
+	switch kind {
+	case resource.KindPods:
+		l, err := r.kubernetes.CoreV1().Pods(namespace).List(ctx, opts)
+		if err != nil {
+			return nil, wrapError(err, "unable to list pods")
+		}
+		for _, o := range l.Items {
+			objects = append(objects, newObject(kind, o.ObjectMeta, string(o.Status.Phase)))
+		}
+	case resource.KindDeployments:
+		l, err := r.kubernetes.AppsV1().Deployments(namespace).List(ctx, opts)
+		if err != nil {
+			return nil, wrapError(err, "unable to list deployments")
+		}
+		for _, o := range l.Items {
+			objects = append(objects, newObject(kind, o.ObjectMeta, fmt.Sprintf("%d/%d ready", o.Status.ReadyReplicas, o.Status.Replicas)))
+		}
+	case resource.KindStatefulsets:
+		l, err := r.kubernetes.AppsV1().StatefulSets(namespace).List(ctx, opts)
+		if err != nil {
+			return nil, wrapError(err, "unable to list statefulsets")
+		}
+		for _, o := range l.Items {
+			objects = append(objects, newObject(kind, o.ObjectMeta, fmt.Sprintf("%d/%d ready", o.Status.ReadyReplicas, o.Status.Replicas)))
+		}
+	case resource.KindJobs:
+		l, err := r.kubernetes.BatchV1().Jobs(namespace).List(ctx, opts)
+		if err != nil {
+			return nil, wrapError(err, "unable to list jobs")
+		}
+		for _, o := range l.Items {
+			objects = append(objects, newObject(kind, o.ObjectMeta, fmt.Sprintf("%d succeeded, %d failed", o.Status.Succeeded, o.Status.Failed)))
+		}
+	case resource.KindCronjobs:
+		l, err := r.kubernetes.BatchV1().CronJobs(namespace).List(ctx, opts)
+		if err != nil {
+			return nil, wrapError(err, "unable to list cronjobs")
+		}
+		for _, o := range l.Items {
+			objects = append(objects, newObject(kind, o.ObjectMeta, o.Spec.Schedule))
+		}
+	case resource.KindConfigmaps:
+		l, err := r.kubernetes.CoreV1().ConfigMaps(namespace).List(ctx, opts)
+		if err != nil {
+			return nil, wrapError(err, "unable to list configmaps")
+		}
+		for _, o := range l.Items {
+			objects = append(objects, newObject(kind, o.ObjectMeta, fmt.Sprintf("%d keys", len(o.Data)+len(o.BinaryData))))
+		}
+	case resource.KindSecrets:
+		l, err := r.kubernetes.CoreV1().Secrets(namespace).List(ctx, opts)
+		if err != nil {
+			return nil, wrapError(err, "unable to list secrets")
+		}
+		for _, o := range l.Items {
+			objects = append(objects, newObject(kind, o.ObjectMeta, fmt.Sprintf("%s, %d keys", o.Type, len(o.Data))))
+		}
+	case resource.KindPvcs:
+		l, err := r.kubernetes.CoreV1().PersistentVolumeClaims(namespace).List(ctx, opts)
+		if err != nil {
+			return nil, wrapError(err, "unable to list persistent volume claims")
+		}
+		for _, o := range l.Items {
+			objects = append(objects, newObject(kind, o.ObjectMeta, string(o.Status.Phase)))
+		}
+	default:
+		return nil, errors.New(errors.Invalid, "unsupported kind %s", kind)
+	}
+
+	return objects, nil
+}
+
+// Get returns an object of a namespace.
+// Objects are listed using a field selector on their name so the status is computed the same way as List.
+func (r *objectRepository) Get(namespace, kind, name string) (*resource.Object, error) {
+	objects, err := r.list(namespace, kind, metav1.ListOptions{FieldSelector: "metadata.name=" + name})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, o := range objects {
+		if o.Name == name {
+			return &o, nil
+		}
+	}
+
+	return nil, errors.WithDetail(errors.New(errors.NotFound, "%s %s not found in namespace %s", kind, name, namespace), "name", name)
+}
+
+// Delete deletes an object of a namespace. Dependent objects, such as the pods of a job, are deleted in background.
+func (r *objectRepository) Delete(namespace, kind, name string) error {
+	ctx := context.Background()
+	pp := metav1.DeletePropagationBackground
+	opts := metav1.DeleteOptions{PropagationPolicy: &pp}
+
+	var err error
+
+	switch kind {
+	case resource.KindPods:
+		err = r.kubernetes.CoreV1().Pods(namespace).Delete(ctx, name, opts)
+	case resource.KindDeployments:
+		err = r.kubernetes.AppsV1().Deployments(namespace).Delete(ctx, name, opts)
+	case resource.KindStatefulsets:
+		err = r.kubernetes.AppsV1().StatefulSets(namespace).Delete(ctx, name, opts)
+	case resource.KindJobs:
+		err = r.kubernetes.BatchV1().Jobs(namespace).Delete(ctx, name, opts)
+	case resource.KindCronjobs:
+		err = r.kubernetes.BatchV1().CronJobs(namespace).Delete(ctx, name, opts)
+	case resource.KindConfigmaps:
+		err = r.kubernetes.CoreV1().ConfigMaps(namespace).Delete(ctx, name, opts)
+	case resource.KindSecrets:
+		err = r.kubernetes.CoreV1().Secrets(namespace).Delete(ctx, name, opts)
+	case resource.KindPvcs:
+		err = r.kubernetes.CoreV1().PersistentVolumeClaims(namespace).Delete(ctx, name, opts)
+	default:
+		return errors.New(errors.Invalid, "unsupported kind %s", kind)
+	}
+
+	return wrapError(err, "unable to delete %s %s", kind, name)
+}
+
+func newObject(kind string, meta metav1.ObjectMeta, status string) resource.Object {
+	return resource.Object{
+		Kind:      kind,
+		Name:      meta.Name,
+		Namespace: meta.Namespace,
+		Status:    status,
+		CreatedAt: meta.CreationTimestamp.Time,
+		Labels:    meta.Labels,
+	}
+}
@@ -1,10 +1,20 @@
 package kubernetes
 
 import (
+	"bufio"
 	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/Meetic/blackbeard/pkg/errors"
 	"github.com/Meetic/blackbeard/pkg/resource"
 
+	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/kubernetes"
 )
 
@@ -22,6 +32,7 @@ func NewPodRepository(kubernetes kubernetes.Interface) resource.PodRepository {
 
 // GetPods of all the pods in a given namespace.
 // This method returns a Pods slice containing the pod name and the pod status (pod status phase).
+// The owners of the pods having problems are resolved, so that problems of ignored workloads can be left out.
 func (pr *podRepository) List(n string) (resource.Pods, error) {
 	// get all pods except job or cron jobs in a succeeded state
 	podsList, err := pr.kubernetes.CoreV1().Pods(n).List(
@@ -30,18 +41,443 @@ func (pr *podRepository) List(n string) (resource.Pods, error) {
 	)
 
 	if err != nil {
-		return nil, err
+		return nil, wrapError(err, "unable to list pods")
 	}
 
 	var pods resource.Pods
+	owners := make(map[string][]string)
 
 	for _, pod := range podsList.Items {
+		p := resource.Pod{
+			Name:        pod.ObjectMeta.Name,
+			Status:      pod.Status.Phase,
+			Annotations: pod.Annotations,
+			Problems:    podProblems(pod, time.Now()),
+		}
 
-		pods = append(pods, resource.Pod{
-			Name:   pod.ObjectMeta.Name,
-			Status: pod.Status.Phase,
-		})
+		if len(p.Problems) > 0 {
+			p.Owners = pr.owners(n, pod.OwnerReferences, owners)
+		}
+
+		pods = append(pods, p)
 	}
 
 	return pods, nil
 }
+
+// owners returns the workloads owning an object as kind/name, following the owners of replicasets and jobs
+// up to their deployment or cronjob. Owners which can not be read are ignored.
+// Resolved owners are cached in cache, indexed by kind/name.
+func (pr *podRepository) owners(namespace string, refs []metav1.OwnerReference, cache map[string][]string) []string {
+	var owners []string
+
+	for _, ref := range refs {
+		if ref.Controller == nil || !*ref.Controller {
+			continue
+		}
+
+		var (
+			kind   string
+			parent func() (metav1.Object, error)
+		)
+
+		switch ref.Kind {
+		case "ReplicaSet":
+			parent = func() (metav1.Object, error) {
+				return pr.kubernetes.AppsV1().ReplicaSets(namespace).Get(context.Background(), ref.Name, metav1.GetOptions{})
+			}
+		case "Job":
+			kind = resource.KindJobs
+			parent = func() (metav1.Object, error) {
+				return pr.kubernetes.BatchV1().Jobs(namespace).Get(context.Background(), ref.Name, metav1.GetOptions{})
+			}
+		case "Deployment":
+			kind = resource.KindDeployments
+		case "StatefulSet":
+			kind = resource.KindStatefulsets
+		case "CronJob":
+			kind = resource.KindCronjobs
+		case "DaemonSet":
+			kind = kindDaemonsets
+		default:
+			continue
+		}
+
+		if kind != "" {
+			owners = append(owners, kind+"/"+ref.Name)
+		}
+
+		if parent == nil {
+			continue
+		}
+
+		key := ref.Kind + "/" + ref.Name
+		parents, ok := cache[key]
+		if !ok {
+			if o, err := parent(); err == nil {
+				parents = pr.owners(namespace, o.GetOwnerReferences(), cache)
+			}
+			cache[key] = parents
+		}
+
+		owners = append(owners, parents...)
+	}
+
+	return owners
+}
+
+// Thresholds after which a pod condition is considered terminal
+const (
+	crashLoopRestarts  = 3
+	unschedulableGrace = time.Minute
+)
+
+// podProblems returns the terminal conditions of a pod :
+// images which can not be pulled, invalid container configurations, containers crashing in loop
+// after crashLoopRestarts restarts and pods unschedulable for more than unschedulableGrace.
+func podProblems(pod v1.Pod, now time.Time) []resource.PodProblem {
+	var problems []resource.PodProblem
+
+	statuses := append(append([]v1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...)
+
+	for _, cs := range statuses {
+		if cs.State.Waiting == nil {
+			continue
+		}
+
+		switch reason := cs.State.Waiting.Reason; reason {
+		case "ImagePullBackOff", "ErrImageNeverPull", "InvalidImageName", "CreateContainerConfigError":
+		case "CrashLoopBackOff":
+			if cs.RestartCount < crashLoopRestarts {
+				continue
+			}
+		default:
+			continue
+		}
+
+		problems = append(problems, resource.PodProblem{
+			Pod:       pod.Name,
+			Container: cs.Name,
+			Reason:    cs.State.Waiting.Reason,
+			Message:   cs.State.Waiting.Message,
+		})
+	}
+
+	for _, c := range pod.Status.Conditions {
+		if c.Type == v1.PodScheduled && c.Status == v1.ConditionFalse && c.Reason == v1.PodReasonUnschedulable &&
+			now.Sub(c.LastTransitionTime.Time) > unschedulableGrace {
+			problems = append(problems, resource.PodProblem{
+				Pod:     pod.Name,
+				Reason:  c.Reason,
+				Message: c.Message,
+			})
+		}
+	}
+
+	return problems
+}
+
+// Logs streams the logs of every container of the pods selected by opts.
+// Pending pods are skipped since their containers have not written any log yet.
+// When following, pods are watched so that logs of new pods and of restarted containers are streamed too.
+func (pr *podRepository) Logs(ctx context.Context, namespace string, opts resource.LogOptions, lines chan<- resource.LogLine) error {
+	selector, err := workloadSelector(ctx, pr.kubernetes, namespace, opts.Workload)
+	if err != nil {
+		return err
+	}
+
+	podsList, err := pr.kubernetes.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return wrapError(err, "unable to list pods")
+	}
+
+	if opts.Follow {
+		return pr.followLogs(ctx, namespace, selector, podsList, opts, lines)
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, len(podsList.Items)*2)
+
+	for _, pod := range podsList.Items {
+		if pod.Status.Phase == v1.PodPending {
+			continue
+		}
+
+		for _, container := range pod.Spec.Containers {
+			if opts.Container != "" && container.Name != opts.Container {
+				continue
+			}
+
+			wg.Add(1)
+			go func(pod, container string) {
+				defer wg.Done()
+				if err := pr.streamLogs(ctx, namespace, pod, container, opts, time.Time{}, lines); err != nil {
+					errs <- err
+				}
+			}(pod.Name, container.Name)
+		}
+	}
+
+	wg.Wait()
+	close(errs)
+
+	return <-errs
+}
+
+// followLogs streams the logs of the listed pods, then watches the pods matching selector to attach to new pods
+// and restarted containers, until ctx is done.
+func (pr *podRepository) followLogs(ctx context.Context, namespace, selector string, podsList *v1.PodList, opts resource.LogOptions, lines chan<- resource.LogLine) error {
+	f := &logFollower{
+		pr:        pr,
+		namespace: namespace,
+		opts:      opts,
+		lines:     lines,
+		streams:   make(map[string]*containerStream),
+	}
+	defer f.wg.Wait()
+
+	for i := range podsList.Items {
+		f.attach(ctx, &podsList.Items[i], true)
+	}
+
+	pods := pr.kubernetes.CoreV1().Pods(namespace)
+	resourceVersion := podsList.ResourceVersion
+
+	for {
+		w, err := pods.Watch(ctx, metav1.ListOptions{LabelSelector: selector, ResourceVersion: resourceVersion})
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return wrapError(err, "unable to watch pods")
+		}
+
+		resourceVersion = f.watch(ctx, w, resourceVersion)
+		w.Stop()
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+// logFollower attaches to the containers of the watched pods, streaming at most once each container instance
+type logFollower struct {
+	pr        *podRepository
+	namespace string
+	opts      resource.LogOptions
+	lines     chan<- resource.LogLine
+
+	mu      sync.Mutex
+	streams map[string]*containerStream
+	wg      sync.WaitGroup
+}
+
+// containerStream is the log stream of a container.
+// id is the id of the streamed container instance and latest the id of the last running instance seen.
+type containerStream struct {
+	id, latest string
+	running    bool
+	stoppedAt  time.Time
+}
+
+// watch attaches to the pods reported by w until it is closed, and returns the last resource version seen.
+// An empty resource version is returned if the watch expired, so that the next watch starts from the current state.
+func (f *logFollower) watch(ctx context.Context, w watch.Interface, resourceVersion string) string {
+	for {
+		select {
+		case <-ctx.Done():
+			return resourceVersion
+		case event, ok := <-w.ResultChan():
+			if !ok {
+				return resourceVersion
+			}
+
+			if event.Type == watch.Error {
+				return ""
+			}
+
+			pod, ok := event.Object.(*v1.Pod)
+			if !ok {
+				continue
+			}
+
+			resourceVersion = pod.ResourceVersion
+			if event.Type == watch.Added || event.Type == watch.Modified {
+				f.attach(ctx, pod, false)
+			}
+		}
+	}
+}
+
+// attach streams the logs of the containers of a pod which are not streamed yet.
+// Listed pods stream every container, as without following.
+// Watched pods only stream running containers, so that a container is streamed again once restarted.
+func (f *logFollower) attach(ctx context.Context, pod *v1.Pod, listed bool) {
+	if pod.Status.Phase == v1.PodPending {
+		return
+	}
+
+	for _, container := range pod.Spec.Containers {
+		if f.opts.Container != "" && container.Name != f.opts.Container {
+			continue
+		}
+
+		id, running := containerState(pod, container.Name)
+		if !listed && !running {
+			continue
+		}
+
+		f.start(ctx, pod.Name, container.Name, id)
+	}
+}
+
+// start streams the logs of a container instance, unless the container is already streamed or the instance
+// has already been streamed. Once the stream stops, the container is streamed again if a new instance started.
+func (f *logFollower) start(ctx context.Context, pod, container, id string) {
+	key := pod + "/" + container
+
+	f.mu.Lock()
+	s, ok := f.streams[key]
+	if !ok {
+		s = &containerStream{}
+		f.streams[key] = s
+	}
+	s.latest = id
+	if s.running || (ok && s.id == id) {
+		f.mu.Unlock()
+		return
+	}
+	s.id, s.running = id, true
+	since := s.stoppedAt
+	f.mu.Unlock()
+
+	f.wg.Add(1)
+	go func() {
+		defer f.wg.Done()
+
+		err := f.pr.streamLogs(ctx, f.namespace, pod, container, f.opts, since, f.lines)
+		if err != nil && ctx.Err() == nil {
+			logrus.WithFields(logrus.Fields{"namespace": f.namespace, "pod": pod, "container": container}).
+				Warnf("log streaming stopped : %v", err)
+		}
+
+		f.mu.Lock()
+		s.running, s.stoppedAt = false, time.Now()
+		latest, restarted := s.latest, s.latest != s.id
+		f.mu.Unlock()
+
+		if restarted && ctx.Err() == nil {
+			f.start(ctx, pod, container, latest)
+		}
+	}()
+}
+
+// containerState returns the id of a container of a pod, and whether it is running
+func containerState(pod *v1.Pod, container string) (string, bool) {
+	for _, cs := range pod.Status.ContainerStatuses {
+		if cs.Name == container {
+			return cs.ContainerID, cs.State.Running != nil
+		}
+	}
+
+	return "", false
+}
+
+// streamLogs sends the logs of a container to lines.
+// Logs written before since are skipped if it is set, otherwise opts.Since applies.
+func (pr *podRepository) streamLogs(ctx context.Context, namespace, pod, container string, opts resource.LogOptions, since time.Time, lines chan<- resource.LogLine) error {
+	logOpts := &v1.PodLogOptions{Container: container, Follow: opts.Follow}
+
+	switch {
+	case !since.IsZero():
+		logOpts.SinceTime = &metav1.Time{Time: since}
+	case opts.Since > 0:
+		seconds := int64(opts.Since.Seconds())
+		logOpts.SinceSeconds = &seconds
+	}
+
+	stream, err := pr.kubernetes.CoreV1().Pods(namespace).GetLogs(pod, logOpts).Stream(ctx)
+	if err != nil {
+		return wrapError(err, "unable to read logs of %s/%s", pod, container)
+	}
+	defer stream.Close()
+
+	scanner := bufio.NewScanner(stream)
+	for scanner.Scan() {
+		select {
+		case lines <- resource.LogLine{Pod: pod, Container: container, Message: scanner.Text()}:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+
+	if err := scanner.Err(); err != nil && ctx.Err() == nil {
+		return errors.Wrap(err, errors.Upstream, "unable to read logs of %s/%s", pod, container)
+	}
+
+	return nil
+}
+
+// workloadSelector returns the label selector of the pods managed by a workload.
+// The workload is a name, optionally prefixed by its kind : deployment, statefulset or job.
+// Without kind, deployments, statefulsets and jobs are searched in this order.
+func workloadSelector(ctx context.Context, kube kubernetes.Interface, namespace, workload string) (string, error) {
+	if workload == "" {
+		return "", nil
+	}
+
+	kinds, name := []string{"deployment", "statefulset", "job"}, workload
+	if i := strings.Index(workload, "/"); i >= 0 {
+		kinds, name = []string{strings.ToLower(workload[:i])}, workload[i+1:]
+	}
+
+	for _, kind := range kinds {
+		selector, err := selectorOf(ctx, kube, namespace, kind, name)
+		if err == nil {
+			return selector, nil
+		}
+
+		if len(kinds) == 1 || !errors.Is(err, errors.NotFound) {
+			return "", err
+		}
+	}
+
+	return "", errors.New(errors.NotFound, "no deployment, statefulset or job named %s", name)
+}
+
+func selectorOf(ctx context.Context, kube kubernetes.Interface, namespace, kind, name string) (string, error) {
+	var selector *metav1.LabelSelector
+
+	switch kind {
+	case "deployment", "deploy":
+		d, err := kube.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return "", wrapError(err, "unable to get deployment %s", name)
+		}
+		selector = d.Spec.Selector
+	case "statefulset", "sts":
+		s, err := kube.AppsV1().StatefulSets(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return "", wrapError(err, "unable to get statefulset %s", name)
+		}
+		selector = s.Spec.Selector
+	case "job":
+		j, err := kube.BatchV1().Jobs(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return "", wrapError(err, "unable to get job %s", name)
+		}
+		selector = j.Spec.Selector
+	default:
+		return "", errors.New(errors.Invalid, "unsupported workload kind %s, use deployment, statefulset or job", kind)
+	}
+
+	s, err := metav1.LabelSelectorAsSelector(selector)
+	if err != nil {
+		return "", errors.Wrap(err, errors.Invalid, "invalid selector for %s %s", kind, name)
+	}
+
+	return s.String(), nil
+}
@@ -0,0 +1,194 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/portforward"
+	"k8s.io/client-go/transport/spdy"
+
+	"github.com/Meetic/blackbeard/pkg/errors"
+	"github.com/Meetic/blackbeard/pkg/resource"
+)
+
+type portForwardRepository struct {
+	kubernetes kubernetes.Interface
+	config     *rest.Config
+}
+
+// NewPortForwardRepository returns a new PortForwardRepository.
+// The rest config is used to open SPDY connections to the pods.
+func NewPortForwardRepository(kubernetes kubernetes.Interface, config *rest.Config) resource.PortForwardRepository {
+	return &portForwardRepository{
+		kubernetes: kubernetes,
+		config:     config,
+	}
+}
+
+// ResolvePod returns a running and ready pod selected by the service and declaring the targeted port.
+// The service port may target a named container port, which is resolved using the pod spec.
+func (r *portForwardRepository) ResolvePod(namespace, service string, port int) (string, int, error) {
+	svc, err := r.kubernetes.CoreV1().Services(namespace).Get(context.Background(), service, metav1.GetOptions{})
+	if err != nil {
+		return "", 0, wrapError(err, "unable to get service %s", service)
+	}
+
+	if len(svc.Spec.Selector) == 0 {
+		return "", 0, errors.New(errors.Invalid, "service %s has no selector, it can not be forwarded", service)
+	}
+
+	targetPort := intstr.FromInt(port)
+	found := false
+	for _, p := range svc.Spec.Ports {
+		if int(p.Port) == port {
+			found = true
+			if p.TargetPort.Type == intstr.String || p.TargetPort.IntVal != 0 {
+				targetPort = p.TargetPort
+			}
+		}
+	}
+
+	if !found {
+		return "", 0, errors.New(errors.Invalid, "service %s does not expose port %d", service, port)
+	}
+
+	pods, err := r.kubernetes.CoreV1().Pods(namespace).List(
+		context.Background(),
+		metav1.ListOptions{LabelSelector: labels.SelectorFromSet(svc.Spec.Selector).String()},
+	)
+	if err != nil {
+		return "", 0, wrapError(err, "unable to list pods of service %s", service)
+	}
+
+	// pods of a rolling update may not declare the same ports, so the other ready pods are tried
+	var undeclared error
+	for _, pod := range pods.Items {
+		if !podReady(pod) {
+			continue
+		}
+
+		podPort, ok := containerPort(pod, targetPort)
+		if !ok {
+			undeclared = errors.New(errors.Invalid, "pod %s does not declare port %s", pod.Name, targetPort.String())
+			continue
+		}
+
+		return pod.Name, podPort, nil
+	}
+
+	if undeclared != nil {
+		return "", 0, undeclared
+	}
+
+	return "", 0, errors.New(errors.NotFound, "no ready pod found for service %s", service)
+}
+
+// Forward opens a SPDY connection to the pod and forwards the local port to the pod port
+func (r *portForwardRepository) Forward(ctx context.Context, namespace, pod string, localPort, podPort int, ready func(localPort int)) error {
+	transport, upgrader, err := spdy.RoundTripperFor(r.config)
+	if err != nil {
+		return errors.Wrap(err, errors.Internal, "unable to create the port forwarding transport")
+	}
+
+	url := r.kubernetes.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Namespace(namespace).
+		Name(pod).
+		SubResource("portforward").
+		URL()
+
+	dialer := spdy.NewDialer(upgrader, &http.Client{Transport: transport}, http.MethodPost, url)
+
+	stop := make(chan struct{})
+	readyCh := make(chan struct{})
+
+	fw, err := portforward.NewOnAddresses(
+		dialer,
+		[]string{"localhost"},
+		[]string{fmt.Sprintf("%d:%d", localPort, podPort)},
+		stop,
+		readyCh,
+		io.Discard,
+		io.Discard,
+	)
+	if err != nil {
+		return errors.Wrap(err, errors.Invalid, "unable to forward port %d to pod %s", podPort, pod)
+	}
+
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		select {
+		case <-ctx.Done():
+			close(stop)
+		case <-done:
+		}
+	}()
+
+	go func() {
+		defer wg.Done()
+		select {
+		case <-readyCh:
+			ports, err := fw.GetPorts()
+			if err == nil && len(ports) > 0 {
+				ready(int(ports[0].Local))
+			}
+		case <-done:
+		}
+	}()
+
+	err = fw.ForwardPorts()
+
+	// ready must not be called once Forward returned
+	close(done)
+	wg.Wait()
+
+	if err != nil {
+		return errors.Wrap(err, errors.Upstream, "port forwarding to pod %s", pod)
+	}
+
+	return nil
+}
+
+func podReady(pod v1.Pod) bool {
+	if pod.Status.Phase != v1.PodRunning || pod.DeletionTimestamp != nil {
+		return false
+	}
+
+	for _, c := range pod.Status.Conditions {
+		if c.Type == v1.PodReady {
+			return c.Status == v1.ConditionTrue
+		}
+	}
+
+	return false
+}
+
+// containerPort returns the container port targeted by a service target port
+func containerPort(pod v1.Pod, target intstr.IntOrString) (int, bool) {
+	if target.Type == intstr.Int {
+		return target.IntValue(), true
+	}
+
+	for _, c := range pod.Spec.Containers {
+		for _, p := range c.Ports {
+			if p.Name == target.StrVal {
+				return int(p.ContainerPort), true
+			}
+		}
+	}
+
+	return 0, false
+}
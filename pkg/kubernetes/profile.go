@@ -0,0 +1,225 @@
+package kubernetes
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+
+	v1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	kerr "k8s.io/apimachinery/pkg/api/errors"
+	kresource "k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/Meetic/blackbeard/pkg/errors"
+	"github.com/Meetic/blackbeard/pkg/resource"
+)
+
+// profileObject is the name of the resource quota and of the limit range created from a profile
+const profileObject = "blackbeard"
+
+// ApplyProfile creates or updates the resource quota, the limit range and the network policies of a profile.
+// The quota or the limit range are deleted if the profile does not define them anymore,
+// as well as the network policies created from a profile which are not part of this one.
+func (ns *namespaceRepository) ApplyProfile(namespace string, profile *resource.NamespaceProfile) error {
+	if err := ns.applyQuota(namespace, profile); err != nil {
+		return err
+	}
+
+	if err := ns.applyLimits(namespace, profile); err != nil {
+		return err
+	}
+
+	return ns.applyNetworkPolicies(namespace, profile)
+}
+
+func (ns *namespaceRepository) applyQuota(namespace string, profile *resource.NamespaceProfile) error {
+	quotas := ns.kubernetes.CoreV1().ResourceQuotas(namespace)
+
+	if len(profile.Quota) == 0 {
+		return ignoreNotFound(quotas.Delete(context.Background(), profileObject, metav1.DeleteOptions{}),
+			"unable to delete the resource quota of namespace %s", namespace)
+	}
+
+	hard, err := resourceList(profile.Quota)
+	if err != nil {
+		return err
+	}
+
+	quota := &v1.ResourceQuota{ObjectMeta: profileMeta(profileObject, profile), Spec: v1.ResourceQuotaSpec{Hard: hard}}
+
+	_, err = quotas.Create(context.Background(), quota, metav1.CreateOptions{})
+	if kerr.IsAlreadyExists(err) {
+		_, err = quotas.Update(context.Background(), quota, metav1.UpdateOptions{})
+	}
+
+	return wrapError(err, "unable to apply the resource quota of namespace %s", namespace)
+}
+
+func (ns *namespaceRepository) applyLimits(namespace string, profile *resource.NamespaceProfile) error {
+	limits := ns.kubernetes.CoreV1().LimitRanges(namespace)
+
+	if profile.Limits == nil {
+		return ignoreNotFound(limits.Delete(context.Background(), profileObject, metav1.DeleteOptions{}),
+			"unable to delete the limit range of namespace %s", namespace)
+	}
+
+	item := v1.LimitRangeItem{Type: v1.LimitTypeContainer}
+
+	for _, l := range []struct {
+		values map[string]string
+		list   *v1.ResourceList
+	}{
+		{profile.Limits.Default, &item.Default},
+		{profile.Limits.DefaultRequest, &item.DefaultRequest},
+		{profile.Limits.Max, &item.Max},
+		{profile.Limits.Min, &item.Min},
+	} {
+		if len(l.values) == 0 {
+			continue
+		}
+
+		list, err := resourceList(l.values)
+		if err != nil {
+			return err
+		}
+		*l.list = list
+	}
+
+	limitRange := &v1.LimitRange{
+		ObjectMeta: profileMeta(profileObject, profile),
+		Spec:       v1.LimitRangeSpec{Limits: []v1.LimitRangeItem{item}},
+	}
+
+	_, err := limits.Create(context.Background(), limitRange, metav1.CreateOptions{})
+	if kerr.IsAlreadyExists(err) {
+		_, err = limits.Update(context.Background(), limitRange, metav1.UpdateOptions{})
+	}
+
+	return wrapError(err, "unable to apply the limit range of namespace %s", namespace)
+}
+
+func (ns *namespaceRepository) applyNetworkPolicies(namespace string, profile *resource.NamespaceProfile) error {
+	policies := ns.kubernetes.NetworkingV1().NetworkPolicies(namespace)
+	wanted := make(map[string]bool)
+
+	for _, np := range profile.NetworkPolicies {
+		raw, err := json.Marshal(np.Spec)
+		if err != nil {
+			return errors.Wrap(err, errors.Invalid, "invalid network policy %s", np.Name)
+		}
+
+		var spec networkingv1.NetworkPolicySpec
+		if err := json.Unmarshal(raw, &spec); err != nil {
+			return errors.Wrap(err, errors.Invalid, "invalid network policy %s", np.Name)
+		}
+
+		policy := &networkingv1.NetworkPolicy{ObjectMeta: profileMeta(np.Name, profile), Spec: spec}
+
+		_, err = policies.Create(context.Background(), policy, metav1.CreateOptions{})
+		if kerr.IsAlreadyExists(err) {
+			_, err = policies.Update(context.Background(), policy, metav1.UpdateOptions{})
+		}
+		if err != nil {
+			return wrapError(err, "unable to apply the network policy %s of namespace %s", np.Name, namespace)
+		}
+
+		wanted[np.Name] = true
+	}
+
+	list, err := policies.List(context.Background(), metav1.ListOptions{LabelSelector: resource.LabelProfile})
+	if err != nil {
+		return wrapError(err, "unable to list the network policies of namespace %s", namespace)
+	}
+
+	for _, np := range list.Items {
+		if wanted[np.Name] {
+			continue
+		}
+
+		// a policy deleted meanwhile is already gone, the other stale policies must still be deleted
+		err := policies.Delete(context.Background(), np.Name, metav1.DeleteOptions{})
+		if err := ignoreNotFound(err, "unable to delete the network policy %s of namespace %s", np.Name, namespace); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GetQuota returns the usage of the resources limited by the resource quotas of a namespace,
+// sorted by quota and resource name. The hard limits of the spec are used until the quota status is computed.
+func (ns *namespaceRepository) GetQuota(namespace string) ([]resource.QuotaUsage, error) {
+	list, err := ns.kubernetes.CoreV1().ResourceQuotas(namespace).List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		return nil, wrapError(err, "unable to list the resource quotas of namespace %s", namespace)
+	}
+
+	var usages []resource.QuotaUsage
+
+	for _, q := range list.Items {
+		hard := q.Status.Hard
+		if len(hard) == 0 {
+			hard = q.Spec.Hard
+		}
+
+		for name, limit := range hard {
+			used := q.Status.Used[name]
+
+			usage := resource.QuotaUsage{
+				Quota:    q.Name,
+				Resource: string(name),
+				Hard:     limit.String(),
+				Used:     used.String(),
+			}
+
+			if limit.MilliValue() > 0 {
+				usage.Percent = int(used.MilliValue() * 100 / limit.MilliValue())
+			}
+
+			usages = append(usages, usage)
+		}
+	}
+
+	sort.Slice(usages, func(i, j int) bool {
+		if usages[i].Quota != usages[j].Quota {
+			return usages[i].Quota < usages[j].Quota
+		}
+		return usages[i].Resource < usages[j].Resource
+	})
+
+	return usages, nil
+}
+
+func profileMeta(name string, profile *resource.NamespaceProfile) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Name: name,
+		Labels: map[string]string{
+			resource.LabelManager: resource.ManagerBlackbeard,
+			resource.LabelProfile: profile.Name,
+		},
+	}
+}
+
+// resourceList parses the quantities of a profile
+func resourceList(values map[string]string) (v1.ResourceList, error) {
+	list := make(v1.ResourceList, len(values))
+
+	for name, value := range values {
+		q, err := kresource.ParseQuantity(value)
+		if err != nil {
+			return nil, errors.Wrap(err, errors.Invalid, "invalid quantity %q for %s", value, name)
+		}
+		list[v1.ResourceName(name)] = q
+	}
+
+	return list, nil
+}
+
+func ignoreNotFound(err error, format string, args ...interface{}) error {
+	if kerr.IsNotFound(err) {
+		return nil
+	}
+
+	return wrapError(err, format, args...)
+}
@@ -0,0 +1,126 @@
+package kubernetes
+
+import (
+	"context"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/Meetic/blackbeard/pkg/resource"
+)
+
+const kindDaemonsets = "daemonsets"
+
+// NewReadinessRules returns the readiness rules of the kinds which are not covered by a repository :
+// cronjobs, daemonsets and persistent volume claims.
+func NewReadinessRules(kubernetes kubernetes.Interface) []resource.ReadinessRule {
+	return []resource.ReadinessRule{
+		&cronjobRule{kubernetes},
+		&daemonsetRule{kubernetes},
+		&pvcRule{kubernetes},
+	}
+}
+
+type cronjobRule struct {
+	kubernetes kubernetes.Interface
+}
+
+func (r *cronjobRule) Kind() string { return resource.KindCronjobs }
+
+// Evaluate returns the readiness of cronjobs.
+// A cronjob is Failed when its last scheduled run did not succeed, and Ready otherwise, including when it never ran.
+func (r *cronjobRule) Evaluate(namespace string) ([]resource.WorkloadReadiness, error) {
+	cl, err := r.kubernetes.BatchV1().CronJobs(namespace).List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		return nil, wrapError(err, "unable to list cronjobs")
+	}
+
+	var workloads []resource.WorkloadReadiness
+
+	for _, cj := range cl.Items {
+		status := resource.WorkloadReady
+
+		scheduled, succeeded := cj.Status.LastScheduleTime, cj.Status.LastSuccessfulTime
+		if scheduled != nil && len(cj.Status.Active) == 0 && (succeeded == nil || succeeded.Before(scheduled)) {
+			status = resource.WorkloadFailed
+		}
+
+		workloads = append(workloads, resource.WorkloadReadiness{
+			Kind:        resource.KindCronjobs,
+			Name:        cj.Name,
+			Status:      status,
+			Annotations: cj.Annotations,
+		})
+	}
+
+	return workloads, nil
+}
+
+type daemonsetRule struct {
+	kubernetes kubernetes.Interface
+}
+
+func (r *daemonsetRule) Kind() string { return kindDaemonsets }
+
+// Evaluate returns the readiness of daemonsets. A daemonset is Ready when a ready pod runs on each scheduled node.
+func (r *daemonsetRule) Evaluate(namespace string) ([]resource.WorkloadReadiness, error) {
+	dl, err := r.kubernetes.AppsV1().DaemonSets(namespace).List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		return nil, wrapError(err, "unable to list daemonsets")
+	}
+
+	var workloads []resource.WorkloadReadiness
+
+	for _, ds := range dl.Items {
+		status := resource.WorkloadNotReady
+		if ds.Status.NumberReady == ds.Status.DesiredNumberScheduled {
+			status = resource.WorkloadReady
+		}
+
+		workloads = append(workloads, resource.WorkloadReadiness{
+			Kind:        kindDaemonsets,
+			Name:        ds.Name,
+			Status:      status,
+			Annotations: ds.Annotations,
+		})
+	}
+
+	return workloads, nil
+}
+
+type pvcRule struct {
+	kubernetes kubernetes.Interface
+}
+
+func (r *pvcRule) Kind() string { return resource.KindPvcs }
+
+// Evaluate returns the readiness of persistent volume claims. A claim is Ready once bound and Failed if its volume is lost.
+func (r *pvcRule) Evaluate(namespace string) ([]resource.WorkloadReadiness, error) {
+	pl, err := r.kubernetes.CoreV1().PersistentVolumeClaims(namespace).List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		return nil, wrapError(err, "unable to list persistent volume claims")
+	}
+
+	var workloads []resource.WorkloadReadiness
+
+	for _, pvc := range pl.Items {
+		status := resource.WorkloadNotReady
+
+		switch pvc.Status.Phase {
+		case v1.ClaimBound:
+			status = resource.WorkloadReady
+		case v1.ClaimLost:
+			status = resource.WorkloadFailed
+		}
+
+		workloads = append(workloads, resource.WorkloadReadiness{
+			Kind:        resource.KindPvcs,
+			Name:        pvc.Name,
+			Status:      status,
+			Annotations: pvc.Annotations,
+		})
+	}
+
+	return workloads, nil
+}
@@ -2,70 +2,106 @@ package kubernetes
 
 import (
 	"context"
-	"fmt"
+	"encoding/json"
 	"strings"
 
+	"github.com/sirupsen/logrus"
+
 	"github.com/Meetic/blackbeard/pkg/resource"
 
 	"k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 )
 
+// Gateway API resources, read using the dynamic client since their types are not part of client-go
+var (
+	httpRouteResource = schema.GroupVersionResource{Group: "gateway.networking.k8s.io", Version: "v1", Resource: "httproutes"}
+	gatewayResource   = schema.GroupVersionResource{Group: "gateway.networking.k8s.io", Version: "v1", Resource: "gateways"}
+)
+
 type serviceRepository struct {
 	kubernetes kubernetes.Interface
+	dynamic    dynamic.Interface
 	host       string
 }
 
 // NewServiceRepository returns a new ServiceRepository
-// It takes as parameter a go-client kubernetes client and the kubernetes cluster host (domain name or ip).
-func NewServiceRepository(kubernetes kubernetes.Interface, host string) resource.ServiceRepository {
+// It takes as parameter a go-client kubernetes client, a dynamic client used to read Gateway API routes
+// and the kubernetes cluster host (domain name or ip).
+// When the dynamic client is nil, HTTPRoutes are not listed.
+func NewServiceRepository(kubernetes kubernetes.Interface, dynamic dynamic.Interface, host string) resource.ServiceRepository {
 	return &serviceRepository{
 		kubernetes: kubernetes,
+		dynamic:    dynamic,
 		host:       host,
 	}
 }
 
 // ListExternal returns a list of kubernetes services exposed as NodePort or LoadBalancer.
+// The url of each exposed port is returned along with the port.
 func (sr *serviceRepository) ListExternal(n string) ([]resource.Service, error) {
 	// unfortunately, we cant filter service by type using field selector
 	svcs, err := sr.kubernetes.CoreV1().Services(n).List(context.Background(), metav1.ListOptions{})
 
 	if err != nil {
-		return nil, fmt.Errorf("kubernetes api list services : %s", err.Error())
+		return nil, wrapError(err, "kubernetes api list services")
 	}
 
 	var services []resource.Service
 
 	for _, svc := range svcs.Items {
-		if svc.Spec.Type == v1.ServiceTypeNodePort || svc.Spec.Type == v1.ServiceTypeLoadBalancer {
-			var ports []resource.Port
+		if svc.Spec.Type != v1.ServiceTypeNodePort && svc.Spec.Type != v1.ServiceTypeLoadBalancer {
+			continue
+		}
 
-			for _, p := range svc.Spec.Ports {
-				ports = append(ports, resource.Port{
-					Port:        p.Port,
-					ExposedPort: p.NodePort,
-				})
-			}
+		addrs := []string{sr.host}
+		source := resource.SourceNodePort
 
-			addr := sr.host
+		if svc.Spec.Type == v1.ServiceTypeLoadBalancer {
+			addrs = loadBalancerAddrs(svc.Status.LoadBalancer.Ingress)
+			source = resource.SourceLoadBalancer
+		}
 
-			if svc.Spec.Type == v1.ServiceTypeLoadBalancer {
-				var ips []string
-				for _, lbi := range svc.Status.LoadBalancer.Ingress {
-					ips = append(ips, lbi.IP)
-				}
+		var first string
+		if len(addrs) > 0 {
+			first = addrs[0]
+		}
+
+		service := resource.Service{
+			Name:   svc.Name,
+			Ports:  make([]resource.Port, 0, len(svc.Spec.Ports)),
+			Addr:   strings.Join(addrs, ","),
+			Source: source,
+			Checks: smokeChecks(svc.Annotations, "service", svc.Name),
+		}
 
-				addr = strings.Join(ips, ",")
+		for _, p := range svc.Spec.Ports {
+			exposed := p.NodePort
+			if svc.Spec.Type == v1.ServiceTypeLoadBalancer {
+				exposed = p.Port
 			}
 
-			services = append(services, resource.Service{
-				Name:  svc.Name,
-				Ports: ports,
-				Addr:  addr,
+			tls := p.Port == 443 || strings.HasPrefix(p.Name, "https")
+
+			service.Ports = append(service.Ports, resource.Port{
+				Port:        p.Port,
+				ExposedPort: exposed,
+				URL:         resource.ServiceURL(tls, first, exposed, ""),
+				TLS:         tls,
 			})
+		}
 
+		if len(service.Ports) > 0 {
+			service.URL, service.TLS = service.Ports[0].URL, service.Ports[0].TLS
 		}
+
+		services = append(services, service)
 	}
 
 	return services, nil
@@ -73,34 +109,334 @@ func (sr *serviceRepository) ListExternal(n string) ([]resource.Service, error)
 }
 
 // ListIngress returns a list of Kubernetes services exposed throw Ingress.
+// A service is returned for each path of each rule. Rules without http paths use the ingress default backend.
+// Hosts listed in the ingress tls section are reached using https.
 func (sr *serviceRepository) ListIngress(n string) ([]resource.Service, error) {
 	ingressList, err := sr.kubernetes.NetworkingV1().Ingresses(n).List(context.Background(), metav1.ListOptions{})
 
 	if err != nil {
-		return nil, err
+		return nil, wrapError(err, "kubernetes api list ingresses")
 	}
 
 	var services []resource.Service
 
 	for _, ing := range ingressList.Items {
+		defaultAddr := ingressAddr(ing.Status.LoadBalancer.Ingress)
+		if defaultAddr == "" {
+			defaultAddr = sr.host
+		}
+
+		rules := ing.Spec.Rules
+		if len(rules) == 0 && ing.Spec.DefaultBackend != nil {
+			rules = []networkingv1.IngressRule{{}}
+		}
+
+		for _, rule := range rules {
+			addr := rule.Host
+			if addr == "" {
+				addr = defaultAddr
+			}
+
+			secret, tls := ingressTLS(ing.Spec.TLS, rule.Host)
+
+			paths := []networkingv1.HTTPIngressPath{}
+			if rule.HTTP != nil {
+				paths = rule.HTTP.Paths
+			} else if ing.Spec.DefaultBackend != nil {
+				paths = []networkingv1.HTTPIngressPath{{Backend: *ing.Spec.DefaultBackend}}
+			}
+
+			for _, path := range paths {
+				name, port := ingressBackend(path.Backend)
+				exposed := int32(80)
+				if tls {
+					exposed = 443
+				}
+
+				services = append(services, resource.Service{
+					Name:      name,
+					Addr:      addr,
+					Ports:     []resource.Port{{Port: port, ExposedPort: exposed}},
+					URL:       resource.ServiceURL(tls, addr, exposed, path.Path),
+					Path:      path.Path,
+					Source:    resource.SourceIngress,
+					TLS:       tls,
+					TLSSecret: secret,
+					Checks:    smokeChecks(ing.Annotations, "ingress", ing.Name),
+				})
+			}
+		}
+	}
+
+	return services, nil
 
-		for _, rules := range ing.Spec.Rules {
-			for _, path := range rules.HTTP.Paths {
-				svc := resource.Service{
-					Name: path.Backend.Service.Name,
-					Addr: rules.Host,
-					Ports: []resource.Port{
-						{
-							Port:        path.Backend.Service.Port.Number,
-							ExposedPort: 80,
-						},
-					},
+}
+
+// ListRoutes returns a list of Kubernetes services exposed throw Gateway API HTTPRoutes.
+// The scheme and port of each route are read from the listeners of its parent gateways.
+// No route is returned when the Gateway API is not installed in the cluster.
+func (sr *serviceRepository) ListRoutes(n string) ([]resource.Service, error) {
+	if sr.dynamic == nil {
+		return nil, nil
+	}
+
+	routes, err := sr.dynamic.Resource(httpRouteResource).Namespace(n).List(context.Background(), metav1.ListOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, wrapError(err, "kubernetes api list http routes")
+	}
+
+	var services []resource.Service
+
+	for _, route := range routes.Items {
+		hostnames, _, _ := unstructured.NestedStringSlice(route.Object, "spec", "hostnames")
+		parents, _, _ := unstructured.NestedSlice(route.Object, "spec", "parentRefs")
+		rules, _, _ := unstructured.NestedSlice(route.Object, "spec", "rules")
+		checks := smokeChecks(route.GetAnnotations(), "httproute", route.GetName())
+
+		for _, parent := range parents {
+			ref, ok := parent.(map[string]interface{})
+			if !ok {
+				continue
+			}
+
+			for _, l := range sr.listeners(n, ref) {
+				addrs := hostnames
+				if len(addrs) == 0 {
+					addrs = []string{l.addr}
+				}
+
+				for _, addr := range addrs {
+					for _, b := range routeBackends(rules) {
+						services = append(services, resource.Service{
+							Name:      b.name,
+							Addr:      addr,
+							Ports:     []resource.Port{{Port: b.port, ExposedPort: l.port}},
+							URL:       resource.ServiceURL(l.tls, addr, l.port, b.path),
+							Path:      b.path,
+							Source:    resource.SourceHTTPRoute,
+							TLS:       l.tls,
+							TLSSecret: l.secret,
+							Checks:    checks,
+						})
+					}
 				}
-				services = append(services, svc)
 			}
 		}
 	}
 
 	return services, nil
+}
+
+// listener is an http listener of a gateway
+type listener struct {
+	addr   string
+	port   int32
+	tls    bool
+	secret string
+}
+
+// listeners returns the http listeners of the gateway referenced by a route parentRef.
+// When the parentRef has a sectionName, only the listener with this name is returned.
+func (sr *serviceRepository) listeners(n string, ref map[string]interface{}) []listener {
+	name, _, _ := unstructured.NestedString(ref, "name")
+	namespace, _, _ := unstructured.NestedString(ref, "namespace")
+	section, _, _ := unstructured.NestedString(ref, "sectionName")
+
+	if namespace == "" {
+		namespace = n
+	}
+
+	gw, err := sr.dynamic.Resource(gatewayResource).Namespace(namespace).Get(context.Background(), name, metav1.GetOptions{})
+	if err != nil {
+		return nil
+	}
+
+	var addr string
+	addresses, _, _ := unstructured.NestedSlice(gw.Object, "status", "addresses")
+	for _, a := range addresses {
+		if m, ok := a.(map[string]interface{}); ok {
+			if addr, _, _ = unstructured.NestedString(m, "value"); addr != "" {
+				break
+			}
+		}
+	}
+
+	if addr == "" {
+		addr = sr.host
+	}
+
+	var listeners []listener
+
+	items, _, _ := unstructured.NestedSlice(gw.Object, "spec", "listeners")
+	for _, item := range items {
+		m, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		lName, _, _ := unstructured.NestedString(m, "name")
+		protocol, _, _ := unstructured.NestedString(m, "protocol")
+		port, _, _ := unstructured.NestedInt64(m, "port")
+		hostname, _, _ := unstructured.NestedString(m, "hostname")
+
+		if section != "" && section != lName {
+			continue
+		}
+
+		if protocol != "HTTP" && protocol != "HTTPS" {
+			continue
+		}
+
+		l := listener{addr: addr, port: int32(port), tls: protocol == "HTTPS"}
+		if hostname != "" && !strings.HasPrefix(hostname, "*") {
+			l.addr = hostname
+		}
+
+		certs, _, _ := unstructured.NestedSlice(m, "tls", "certificateRefs")
+		if len(certs) > 0 {
+			if c, ok := certs[0].(map[string]interface{}); ok {
+				l.secret, _, _ = unstructured.NestedString(c, "name")
+			}
+		}
+
+		listeners = append(listeners, l)
+	}
+
+	return listeners
+}
+
+// backend is a service targeted by a route rule
+type backend struct {
+	name string
+	port int32
+	path string
+}
+
+// routeBackends returns the services targeted by the rules of an HTTPRoute, with the path prefix of the rule.
+// Backends which are not services are ignored.
+func routeBackends(rules []interface{}) []backend {
+	var backends []backend
+
+	for _, r := range rules {
+		rule, ok := r.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		var path string
+		matches, _, _ := unstructured.NestedSlice(rule, "matches")
+		if len(matches) > 0 {
+			if m, ok := matches[0].(map[string]interface{}); ok {
+				path, _, _ = unstructured.NestedString(m, "path", "value")
+			}
+		}
+
+		refs, _, _ := unstructured.NestedSlice(rule, "backendRefs")
+		for _, ref := range refs {
+			m, ok := ref.(map[string]interface{})
+			if !ok {
+				continue
+			}
+
+			if kind, _, _ := unstructured.NestedString(m, "kind"); kind != "" && kind != "Service" {
+				continue
+			}
+
+			name, _, _ := unstructured.NestedString(m, "name")
+			port, _, _ := unstructured.NestedInt64(m, "port")
+
+			backends = append(backends, backend{name: name, port: int32(port), path: path})
+		}
+	}
+
+	return backends
+}
+
+// smokeChecks returns the checks declared in the blackbeard.io/checks annotation of an object.
+// Invalid declarations are logged and ignored.
+func smokeChecks(annotations map[string]string, kind, name string) []resource.SmokeCheck {
+	value, ok := annotations[resource.AnnotationChecks]
+	if !ok {
+		return nil
+	}
+
+	var checks []resource.SmokeCheck
+	if err := json.Unmarshal([]byte(value), &checks); err != nil {
+		logrus.Warnf("invalid %s annotation on %s %s : %s", resource.AnnotationChecks, kind, name, err.Error())
+		return nil
+	}
+
+	return checks
+}
+
+// ingressBackend returns the name and the port of an ingress backend.
+// Resource backends have no port and are named after their kind and name.
+func ingressBackend(b networkingv1.IngressBackend) (string, int32) {
+	switch {
+	case b.Service != nil:
+		return b.Service.Name, b.Service.Port.Number
+	case b.Resource != nil:
+		return strings.ToLower(b.Resource.Kind) + "/" + b.Resource.Name, 0
+	}
+
+	return "", 0
+}
+
+// ingressTLS returns the secret name of the tls section covering host, and true if there is one.
+// Wildcard tls hosts match a single subdomain level.
+func ingressTLS(sections []networkingv1.IngressTLS, host string) (string, bool) {
+	for _, tls := range sections {
+		// a tls section without hosts applies to the default host of the ingress controller
+		if len(tls.Hosts) == 0 && host == "" {
+			return tls.SecretName, true
+		}
+
+		for _, h := range tls.Hosts {
+			if h == host {
+				return tls.SecretName, true
+			}
+
+			if strings.HasPrefix(h, "*.") && host != "" {
+				if _, domain, ok := strings.Cut(host, "."); ok && domain == h[2:] {
+					return tls.SecretName, true
+				}
+			}
+		}
+	}
+
+	return "", false
+}
+
+// loadBalancerAddrs returns the addresses of a load balancer, using its hostname when it has no ip
+func loadBalancerAddrs(ingress []v1.LoadBalancerIngress) []string {
+	var addrs []string
+
+	for _, lbi := range ingress {
+		if lbi.IP != "" {
+			addrs = append(addrs, lbi.IP)
+		} else if lbi.Hostname != "" {
+			addrs = append(addrs, lbi.Hostname)
+		}
+	}
+
+	return addrs
+}
+
+// ingressAddr returns the first address of an ingress load balancer
+func ingressAddr(ingress []networkingv1.IngressLoadBalancerIngress) string {
+	for _, lbi := range ingress {
+		if lbi.IP != "" {
+			return lbi.IP
+		}
+
+		if lbi.Hostname != "" {
+			return lbi.Hostname
+		}
+	}
 
+	return ""
 }
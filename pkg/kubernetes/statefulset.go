@@ -2,9 +2,10 @@ package kubernetes
 
 import (
 	"context"
-	"fmt"
 
+	appsv1 "k8s.io/api/apps/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes"
 
 	"github.com/Meetic/blackbeard/pkg/resource"
@@ -20,12 +21,13 @@ func NewStatefulsetRepository(kubernetes kubernetes.Interface) resource.Stateful
 	}
 }
 
-// List return a list of statefulset with their status Ready or NotReady
+// List return a list of statefulset with their status Ready or NotReady.
+// A statefulset is Ready once its rollout is complete and all its replicas are ready.
 func (r *statefulsetRepository) List(namespace string) (resource.Statefulsets, error) {
 	sfl, err := r.AppsV1().StatefulSets(namespace).List(context.Background(), v1.ListOptions{})
 
 	if err != nil {
-		return nil, fmt.Errorf("unable to list statefulsets: %v", err)
+		return nil, wrapError(err, "unable to list statefulsets")
 	}
 
 	sfs := make(resource.Statefulsets, 0)
@@ -33,15 +35,50 @@ func (r *statefulsetRepository) List(namespace string) (resource.Statefulsets, e
 	for _, dp := range sfl.Items {
 		status := resource.StatefulsetNotReady
 
-		if dp.Status.ReadyReplicas == dp.Status.Replicas {
+		if statefulsetRolledOut(dp) {
 			status = resource.StatefulsetReady
 		}
 
 		sfs = append(sfs, resource.Statefulset{
-			Name:   dp.Name,
-			Status: status,
+			Name:        dp.Name,
+			Status:      status,
+			Annotations: dp.Annotations,
 		})
 	}
 
 	return sfs, nil
 }
+
+// statefulsetRolledOut returns true if the controller observed the last spec of the statefulset,
+// and every replica is up to date and ready.
+// Pods of statefulsets updated OnDelete are never updated by the controller, so only their readiness is checked,
+// and pods below the partition of a rolling update are not expected to be updated.
+func statefulsetRolledOut(sts appsv1.StatefulSet) bool {
+	if sts.Status.ObservedGeneration < sts.Generation || sts.Status.ReadyReplicas != sts.Status.Replicas {
+		return false
+	}
+
+	if sts.Spec.UpdateStrategy.Type == appsv1.OnDeleteStatefulSetStrategyType {
+		return true
+	}
+
+	updated := sts.Status.Replicas
+	if ru := sts.Spec.UpdateStrategy.RollingUpdate; ru != nil && ru.Partition != nil {
+		updated -= *ru.Partition
+	}
+
+	return sts.Status.UpdatedReplicas >= updated
+}
+
+// Restart triggers a rollout of the statefulset, like kubectl rollout restart does
+func (r *statefulsetRepository) Restart(namespace, name string) error {
+	_, err := r.AppsV1().StatefulSets(namespace).Patch(
+		context.Background(),
+		name,
+		types.StrategicMergePatchType,
+		restartPatch(),
+		v1.PatchOptions{},
+	)
+
+	return wrapError(err, "unable to restart statefulset %s", name)
+}
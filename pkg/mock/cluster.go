@@ -0,0 +1,19 @@
+package mock
+
+import "github.com/Meetic/blackbeard/pkg/resource"
+
+type clusterRepository struct{}
+
+// NewClusterRepository returns a Mock ClusterRepository
+func NewClusterRepository() resource.ClusterRepository {
+	return &clusterRepository{}
+}
+
+func (clusterRepository) GetVersion() (*resource.Version, error) {
+	var v resource.Version
+
+	v.ServerVersion.Major, v.ServerVersion.Minor = "1", "2"
+	v.ClientVersion.Major, v.ClientVersion.Minor = "0", "9"
+
+	return &v, nil
+}
@@ -18,3 +18,11 @@ func (cr *configRepository) Save(namespace string, configs []playbook.Config) er
 func (cr *configRepository) Delete(namespace string) error {
 	return nil
 }
+
+func (cr *configRepository) List() ([]string, error) {
+	return []string{"test1", "test2"}, nil
+}
+
+func (cr *configRepository) Get(namespace string) ([]playbook.Config, error) {
+	return []playbook.Config{{Name: "01_template.yml", Values: "kind: Deployment\nmetadata:\n  name: api\n"}}, nil
+}
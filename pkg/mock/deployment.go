@@ -14,3 +14,8 @@ func (m *DeploymentRepository) List(namespace string) (resource.Deployments, err
 	args := m.Called(namespace)
 	return args.Get(0).(resource.Deployments), args.Error(1)
 }
+
+func (m *DeploymentRepository) Restart(namespace, name string) error {
+	args := m.Called(namespace, name)
+	return args.Error(0)
+}
@@ -0,0 +1,15 @@
+package mock
+
+import "github.com/Meetic/blackbeard/pkg/resource"
+
+type driftRepository struct{}
+
+// NewDriftRepository returns a Mock DriftRepository
+func NewDriftRepository() resource.DriftRepository {
+	return &driftRepository{}
+}
+
+// Compare reports every object as being in sync
+func (r *driftRepository) Compare(namespace string, manifests []string) ([]resource.ObjectDrift, error) {
+	return []resource.ObjectDrift{{Kind: "Deployment", Name: "api"}}, nil
+}
@@ -0,0 +1,41 @@
+package mock
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/Meetic/blackbeard/pkg/resource"
+)
+
+type execRepository struct{}
+
+// NewExecRepository returns an ExecRepository echoing the command and its stdin.
+// The "false" command exits with code 1.
+func NewExecRepository() resource.ExecRepository {
+	return &execRepository{}
+}
+
+// ResolvePod returns the name of the target
+func (r *execRepository) ResolvePod(namespace, target string) (string, error) {
+	_, name, _ := strings.Cut(target, "/")
+	return name, nil
+}
+
+// Exec writes the command, then copies stdin, to stdout
+func (r *execRepository) Exec(ctx context.Context, namespace, pod string, opts resource.ExecOptions) error {
+	if opts.Command[0] == "false" {
+		return resource.NewExitError(1)
+	}
+
+	fmt.Fprintf(opts.Stdout, "%s: %s\n", pod, strings.Join(opts.Command, " "))
+
+	if opts.Stdin != nil {
+		if _, err := io.Copy(opts.Stdout, opts.Stdin); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -1,6 +1,7 @@
 package mock
 
 import (
+	"github.com/Meetic/blackbeard/pkg/errors"
 	"github.com/Meetic/blackbeard/pkg/resource"
 	"k8s.io/client-go/kubernetes"
 )
@@ -20,16 +21,22 @@ func NewNamespaceRepository(kubernetes kubernetes.Interface, createFailure bool)
 }
 
 // Create creates a namespace
-func (ns *namespaceRepository) Create(namespace string) error {
+func (ns *namespaceRepository) Create(namespace string, metadata resource.NamespaceMetadata, profile *resource.NamespaceProfile) error {
 	if ns.createFailure {
-		return resource.ErrorCreateNamespace{Msg: "namespace " + namespace + " already exist"}
+		return errors.New(errors.AlreadyExists, "namespace %s already exist", namespace)
 	}
 
 	return nil
 }
 
 func (ns *namespaceRepository) Get(namespace string) (*resource.Namespace, error) {
-	return &resource.Namespace{Name: namespace, Phase: "Active", Status: 100}, nil
+	return &resource.Namespace{
+		Name:        namespace,
+		Phase:       "Active",
+		Status:      100,
+		Labels:      map[string]string{resource.LabelManager: resource.ManagerBlackbeard},
+		Annotations: map[string]string{resource.AnnotationLastApplied: "2020-01-01T00:00:00Z"},
+	}, nil
 }
 
 // Delete deletes a given namespace
@@ -41,7 +48,7 @@ func (ns *namespaceRepository) Delete(namespace string) error {
 // Name is the namespace name from Kubernetes.
 // Phase is the status phase.
 // List returns an error if the namespace list could not be get from Kubernetes cluster.
-func (ns *namespaceRepository) List() ([]resource.Namespace, error) {
+func (ns *namespaceRepository) List(selector string) ([]resource.Namespace, error) {
 	namespaces := []resource.Namespace{
 		{
 			Name:  "test",
@@ -56,3 +63,33 @@ func (ns *namespaceRepository) List() ([]resource.Namespace, error) {
 func (ns *namespaceRepository) ApplyConfig(namespace, configPath string) error {
 	return nil
 }
+
+// ApplyProfile does nothing
+func (ns *namespaceRepository) ApplyProfile(namespace string, profile *resource.NamespaceProfile) error {
+	return nil
+}
+
+// GetQuota returns the usage of a pods quota
+func (ns *namespaceRepository) GetQuota(namespace string) ([]resource.QuotaUsage, error) {
+	return []resource.QuotaUsage{{Quota: "blackbeard", Resource: "pods", Hard: "20", Used: "5", Percent: 25}}, nil
+}
+
+// Adopt does nothing
+func (ns *namespaceRepository) Adopt(namespace string) error {
+	return nil
+}
+
+// Detach does nothing
+func (ns *namespaceRepository) Detach(namespace string) error {
+	return nil
+}
+
+// Annotate does nothing
+func (ns *namespaceRepository) Annotate(namespace string, annotations map[string]string) error {
+	return nil
+}
+
+// Watch does not publish any event
+func (ns *namespaceRepository) Watch(events chan<- resource.NamespaceEvent) error {
+	return nil
+}
@@ -79,3 +79,18 @@ func (p *playbooks) GetDefault() (playbook.Inventory, error) {
 
 	return inventory, nil
 }
+
+func (p *playbooks) Version() (playbook.Version, error) {
+	return playbook.Version{Source: "mock", Ref: "main", Commit: "4f9c2a1e7b3d5f6a8c0e2b4d6f8a0c2e4b6d8f0a"}, nil
+}
+
+func (p *playbooks) Status() (playbook.Status, error) {
+	v, _ := p.Version()
+
+	return playbook.Status{Version: v, Loaded: true}, nil
+}
+
+// At returns the playbook itself, whatever the ref
+func (p *playbooks) At(ref string) (playbook.PlaybookRepository, error) {
+	return p, nil
+}
@@ -1,6 +1,8 @@
 package mock
 
 import (
+	"context"
+
 	"github.com/stretchr/testify/mock"
 
 	"github.com/Meetic/blackbeard/pkg/resource"
@@ -16,3 +18,12 @@ func (m *PodRepository) List(n string) (resource.Pods, error) {
 	args := m.Called(n)
 	return args.Get(0).(resource.Pods), args.Error(1)
 }
+
+// Logs sends the lines given to the mock
+func (m *PodRepository) Logs(ctx context.Context, namespace string, opts resource.LogOptions, lines chan<- resource.LogLine) error {
+	args := m.Called(namespace, opts)
+	for _, l := range args.Get(0).([]resource.LogLine) {
+		lines <- l
+	}
+	return args.Error(1)
+}
@@ -0,0 +1,25 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type PortForwardRepository struct {
+	mock.Mock
+}
+
+func (m *PortForwardRepository) ResolvePod(namespace, service string, port int) (string, int, error) {
+	args := m.Called(namespace, service, port)
+	return args.String(0), args.Int(1), args.Error(2)
+}
+
+// Forward calls ready with the local port returned by the mock, if any, then returns the mock error
+func (m *PortForwardRepository) Forward(ctx context.Context, namespace, pod string, localPort, podPort int, ready func(localPort int)) error {
+	args := m.Called(namespace, pod, localPort, podPort)
+	if p := args.Int(0); p > 0 {
+		ready(p)
+	}
+	return args.Error(1)
+}
@@ -0,0 +1,29 @@
+package mock
+
+import (
+	"github.com/Meetic/blackbeard/pkg/errors"
+	"github.com/Meetic/blackbeard/pkg/resource"
+)
+
+type profiles struct{}
+
+// NewProfileRepository returns a ProfileRepository with a small default profile and a large one
+func NewProfileRepository() resource.ProfileRepository {
+	return &profiles{}
+}
+
+func (p *profiles) Get(name string) (*resource.NamespaceProfile, error) {
+	switch name {
+	case "", "small":
+		return &resource.NamespaceProfile{Name: "small", Quota: map[string]string{"pods": "20"}}, nil
+	case "large":
+		return &resource.NamespaceProfile{Name: "large", Quota: map[string]string{"pods": "100"}}, nil
+	}
+
+	return nil, errors.New(errors.Invalid, "unknown profile %s, available profiles : large, small", name)
+}
+
+// Metadata returns a team label
+func (p *profiles) Metadata() (resource.NamespaceMetadata, error) {
+	return resource.NamespaceMetadata{Labels: map[string]string{"team": "dating"}}, nil
+}
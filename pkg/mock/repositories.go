@@ -0,0 +1,29 @@
+package mock
+
+import (
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/Meetic/blackbeard/pkg/api"
+	kube "github.com/Meetic/blackbeard/pkg/kubernetes"
+)
+
+// NewRepositories returns the repositories of an api for tests : the playbook, the namespaces, the cluster,
+// the execs and the drifts are mocked, the other kubernetes objects are stored by the given go-client Kubernetes client.
+func NewRepositories(kubernetes kubernetes.Interface) api.Repositories {
+	return api.Repositories{
+		Inventories:  NewInventoryRepository(),
+		Configs:      NewConfigRepository(),
+		Playbooks:    NewPlaybookRepository(),
+		Profiles:     NewProfileRepository(),
+		Namespaces:   NewNamespaceRepository(kubernetes, false),
+		Pods:         kube.NewPodRepository(kubernetes),
+		Deployments:  kube.NewDeploymentRepository(kubernetes),
+		Statefulsets: kube.NewStatefulsetRepository(kubernetes),
+		Services:     kube.NewServiceRepository(kubernetes, nil, "kube.test"),
+		Cluster:      NewClusterRepository(),
+		Jobs:         kube.NewJobRepository(kubernetes),
+		Objects:      kube.NewObjectRepository(kubernetes),
+		Execs:        NewExecRepository(),
+		Drifts:       NewDriftRepository(),
+	}
+}
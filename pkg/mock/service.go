@@ -40,3 +40,14 @@ func (sr *serviceRepository) ListIngress(n string) ([]resource.Service, error) {
 
 	return services, nil
 }
+
+// ListRoutes returns a list of Kubernetes services exposed throw Gateway API HTTPRoutes.
+func (sr *serviceRepository) ListRoutes(n string) ([]resource.Service, error) {
+	services := []resource.Service{
+		{
+			Name: "testRoute",
+		},
+	}
+
+	return services, nil
+}
@@ -14,3 +14,8 @@ func (m *StatefulsetRepository) List(namespace string) (resource.Statefulsets, e
 	args := m.Called(namespace)
 	return args.Get(0).(resource.Statefulsets), args.Error(1)
 }
+
+func (m *StatefulsetRepository) Restart(namespace, name string) error {
+	args := m.Called(namespace, name)
+	return args.Error(0)
+}
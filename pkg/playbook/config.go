@@ -2,8 +2,9 @@ package playbook
 
 import (
 	"bytes"
-	"errors"
 	"time"
+
+	"github.com/Meetic/blackbeard/pkg/errors"
 )
 
 // Config represents a set of kubernetes configuration.
@@ -31,12 +32,18 @@ type InventoryRelease struct {
 type ConfigService interface {
 	Generate(Inventory) error
 	Delete(namespace string) error
+	List() ([]string, error)
+	Get(namespace string) ([]Config, error)
 }
 
 // ConfigRepository represents a service that implements configs management
+// List returns the namespaces having generated configs.
+// Get returns the last generated configs of a namespace, or a NotFound error if none has been generated.
 type ConfigRepository interface {
 	Save(namespace string, configs []Config) error
 	Delete(namespace string) error
+	List() ([]string, error)
+	Get(namespace string) ([]Config, error)
 }
 
 type configService struct {
@@ -58,10 +65,15 @@ func NewConfigService(configs ConfigRepository, playbooks PlaybookService) Confi
 func (cs *configService) Generate(inv Inventory) error {
 
 	if inv.Namespace == "" {
-		return errors.New("an namespace must be specified in the inventory")
+		return errors.New(errors.Invalid, "an namespace must be specified in the inventory")
+	}
+
+	playbooks, err := cs.playbooks.At(inv.PlaybookRef)
+	if err != nil {
+		return err
 	}
 
-	tpls, err := cs.playbooks.GetTemplate()
+	tpls, err := playbooks.GetTemplate()
 	if err != nil {
 		return err
 	}
@@ -80,7 +92,9 @@ func (cs *configService) Generate(inv Inventory) error {
 
 		confVal := bytes.Buffer{}
 
-		tpl.Template.Execute(&confVal, invRelease)
+		if err := tpl.Template.Execute(&confVal, invRelease); err != nil {
+			return errors.Wrap(err, errors.Invalid, "template %s cannot be executed", tpl.Name)
+		}
 
 		conf := Config{
 			Name:   tpl.Name,
@@ -97,3 +111,13 @@ func (cs *configService) Generate(inv Inventory) error {
 func (cs *configService) Delete(namespace string) error {
 	return cs.configs.Delete(namespace)
 }
+
+// List returns the namespaces having generated configs
+func (cs *configService) List() ([]string, error) {
+	return cs.configs.List()
+}
+
+// Get returns the last generated configs of a namespace
+func (cs *configService) Get(namespace string) ([]Config, error) {
+	return cs.configs.Get(namespace)
+}
@@ -0,0 +1,96 @@
+package playbook
+
+import "fmt"
+
+const (
+	// ChangeAdded is a value of the inventory missing from the inventory it is compared against
+	ChangeAdded = "added"
+	// ChangeRemoved is a value missing from the inventory and present in the inventory it is compared against
+	ChangeRemoved = "removed"
+	// ChangeChanged is a value present in both inventories, with different values
+	ChangeChanged = "changed"
+)
+
+// Difference is a value differing between the values of two inventories.
+// Value is the value of the inventory and Against the value of the inventory it is compared against.
+type Difference struct {
+	Path    string      `json:"path"`
+	Change  string      `json:"change"`
+	Value   interface{} `json:"value"`
+	Against interface{} `json:"against"`
+}
+
+// InventoryDiff is the list of the values differing between the inventory of a namespace and the inventory of
+// another namespace, or the defaults of the playbook if Against is empty.
+type InventoryDiff struct {
+	Namespace   string       `json:"namespace"`
+	Against     string       `json:"against,omitempty"`
+	Differences []Difference `json:"differences"`
+}
+
+// DiffValues returns the values differing between the values of an inventory and the values it is compared against,
+// sorted by path. Paths are built the same way as the paths of MergeValues : maps are compared key by key, and lists
+// of maps having a "name" field element by element, matched by name. Other lists are compared as a whole.
+func DiffValues(values, against map[string]interface{}) []Difference {
+	diff := make([]Difference, 0)
+
+	return diffMaps(diff, "", values, against)
+}
+
+func diffValue(diff []Difference, path string, v, against value) []Difference {
+	if v.equal(against) {
+		return diff
+	}
+
+	if m, ok := v.v.(map[string]interface{}); ok {
+		if a, ok := against.v.(map[string]interface{}); ok {
+			return diffMaps(diff, path, m, a)
+		}
+	}
+
+	if l, ok := namedList(v.v); ok {
+		if a, ok := namedList(against.v); ok {
+			return diffLists(diff, path, l, a)
+		}
+	}
+
+	change := ChangeChanged
+	switch {
+	case !against.present:
+		change = ChangeAdded
+	case !v.present:
+		change = ChangeRemoved
+	}
+
+	return append(diff, Difference{Path: path, Change: change, Value: v.v, Against: against.v})
+}
+
+func diffMaps(diff []Difference, path string, values, against map[string]interface{}) []Difference {
+	for _, key := range sortedKeys(values, against) {
+		p := key
+		if path != "" {
+			p = path + "." + key
+		}
+
+		v, inValues := values[key]
+		a, inAgainst := against[key]
+
+		diff = diffValue(diff, p, value{v, inValues}, value{a, inAgainst})
+	}
+
+	return diff
+}
+
+// diffLists compares lists of maps matched by their name, ignoring the order of their elements
+func diffLists(diff []Difference, path string, values, against []interface{}) []Difference {
+	v, a := byName(values), byName(against)
+
+	for _, name := range sortedKeys(v, a) {
+		vv, inValues := v[name]
+		av, inAgainst := a[name]
+
+		diff = diffValue(diff, fmt.Sprintf("%s[%s]", path, name), value{vv, inValues}, value{av, inAgainst})
+	}
+
+	return diff
+}
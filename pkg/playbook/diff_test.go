@@ -0,0 +1,120 @@
+package playbook_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Meetic/blackbeard/pkg/playbook"
+)
+
+func TestDiffValues(t *testing.T) {
+	tests := []struct {
+		name     string
+		values   string
+		against  string
+		expected []playbook.Difference
+	}{
+		{
+			name:     "same values",
+			values:   `{"version":"1.0","front":{"replicas":1}}`,
+			against:  `{"front":{"replicas":1},"version":"1.0"}`,
+			expected: []playbook.Difference{},
+		},
+		{
+			name:    "added, removed and changed values",
+			values:  `{"version":"1.1","debug":true}`,
+			against: `{"version":"1.0","replicas":2}`,
+			expected: []playbook.Difference{
+				{Path: "debug", Change: playbook.ChangeAdded, Value: true},
+				{Path: "replicas", Change: playbook.ChangeRemoved, Against: float64(2)},
+				{Path: "version", Change: playbook.ChangeChanged, Value: "1.1", Against: "1.0"},
+			},
+		},
+		{
+			name:    "nested maps compared key by key",
+			values:  `{"front":{"version":"1.1","replicas":1}}`,
+			against: `{"front":{"version":"1.0","replicas":1}}`,
+			expected: []playbook.Difference{
+				{Path: "front.version", Change: playbook.ChangeChanged, Value: "1.1", Against: "1.0"},
+			},
+		},
+		{
+			name:    "map compared with a scalar",
+			values:  `{"front":{"version":"1.0"}}`,
+			against: `{"front":"disabled"}`,
+			expected: []playbook.Difference{
+				{Path: "front", Change: playbook.ChangeChanged, Value: map[string]interface{}{"version": "1.0"}, Against: "disabled"},
+			},
+		},
+		{
+			name:    "applications matched by name",
+			values:  `{"microservices":[{"name":"search","version":"1.0"},{"name":"api","version":"1.1"}]}`,
+			against: `{"microservices":[{"name":"api","version":"1.0"},{"name":"legacy","version":"1.0"}]}`,
+			expected: []playbook.Difference{
+				{Path: "microservices[api].version", Change: playbook.ChangeChanged, Value: "1.1", Against: "1.0"},
+				{Path: "microservices[legacy]", Change: playbook.ChangeRemoved, Against: map[string]interface{}{"name": "legacy", "version": "1.0"}},
+				{Path: "microservices[search]", Change: playbook.ChangeAdded, Value: map[string]interface{}{"name": "search", "version": "1.0"}},
+			},
+		},
+		{
+			name:     "order of the applications ignored",
+			values:   `{"microservices":[{"name":"b"},{"name":"a"}]}`,
+			against:  `{"microservices":[{"name":"a"},{"name":"b"}]}`,
+			expected: []playbook.Difference{},
+		},
+		{
+			name:    "lists of scalars compared as a whole",
+			values:  `{"urls":["a","b"]}`,
+			against: `{"urls":["a"]}`,
+			expected: []playbook.Difference{
+				{Path: "urls", Change: playbook.ChangeChanged, Value: []interface{}{"a", "b"}, Against: []interface{}{"a"}},
+			},
+		},
+		{
+			name:    "null value differs from a missing value",
+			values:  `{"debug":null}`,
+			against: `{}`,
+			expected: []playbook.Difference{
+				{Path: "debug", Change: playbook.ChangeAdded},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, playbook.DiffValues(values(t, test.values), values(t, test.against)))
+		})
+	}
+}
+
+func TestDiffOk(t *testing.T) {
+	def, _ := playbooks.GetDefault()
+
+	current := playbook.CopyValues(def.Values)
+	current["microservices"].([]interface{})[0].(map[string]interface{})["version"] = "1.2.0"
+
+	repo := &memoryInventories{inventories: map[string]playbook.Inventory{
+		"test":    {Namespace: "test", Values: current},
+		"staging": {Namespace: "staging", Values: def.Values},
+	}}
+
+	service := playbook.NewInventoryService(repo, playbooks)
+
+	expected := []playbook.Difference{
+		{Path: "microservices[api-advertising].version", Change: playbook.ChangeChanged, Value: "1.2.0", Against: "latest"},
+	}
+
+	diff, err := service.Diff("test", "staging")
+	assert.Nil(t, err)
+	assert.Equal(t, "staging", diff.Against)
+	assert.Equal(t, expected, diff.Differences)
+
+	diff, err = service.Diff("test", "")
+	assert.Nil(t, err)
+	assert.Equal(t, "test", diff.Namespace)
+	assert.Equal(t, expected, diff.Differences)
+
+	_, err = service.Diff("test", "unknown")
+	assert.Error(t, err)
+}
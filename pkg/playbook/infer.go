@@ -0,0 +1,53 @@
+package playbook
+
+import "sort"
+
+// versionKeys are the keys of the inventory values holding the version of an application
+var versionKeys = []string{"version", "tag", "imageTag"}
+
+// InferVersions sets the versions of the inventory values from the given image tags, indexed by application name.
+// A value is matched when its map key, or its "name" field for list elements, is the name of an application.
+// Its "version", "tag" or "imageTag" string field is then replaced by the tag of the application.
+// InferVersions returns the names of the applications whose version has been set.
+func InferVersions(values map[string]interface{}, tags map[string]string) []string {
+	updated := make(map[string]bool)
+
+	for key, value := range values {
+		inferVersions(key, value, tags, updated)
+	}
+
+	var names []string
+	for name := range updated {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+func inferVersions(name string, value interface{}, tags map[string]string, updated map[string]bool) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if n, ok := v["name"].(string); ok {
+			name = n
+		}
+
+		if tag, ok := tags[name]; ok {
+			for _, key := range versionKeys {
+				if _, ok := v[key].(string); ok {
+					v[key] = tag
+					updated[name] = true
+					break
+				}
+			}
+		}
+
+		for key, child := range v {
+			inferVersions(key, child, tags, updated)
+		}
+	case []interface{}:
+		for _, child := range v {
+			inferVersions("", child, tags, updated)
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package playbook_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Meetic/blackbeard/pkg/playbook"
+)
+
+func TestInferVersions(t *testing.T) {
+	values := map[string]interface{}{
+		"microservices": []interface{}{
+			map[string]interface{}{"name": "api", "version": "latest"},
+			map[string]interface{}{"name": "front", "tag": "latest"},
+			map[string]interface{}{"name": "worker", "version": "latest"},
+		},
+		"mysql": map[string]interface{}{"imageTag": "5.7", "replicas": float64(1)},
+		"redis": map[string]interface{}{"version": 6},
+	}
+
+	updated := playbook.InferVersions(values, map[string]string{"api": "v2", "front": "1.4.0", "mysql": "8.0", "redis": "7", "unknown": "v1"})
+
+	assert.Equal(t, []string{"api", "front", "mysql"}, updated)
+	assert.Equal(t, map[string]interface{}{
+		"microservices": []interface{}{
+			map[string]interface{}{"name": "api", "version": "v2"},
+			map[string]interface{}{"name": "front", "tag": "1.4.0"},
+			map[string]interface{}{"name": "worker", "version": "latest"},
+		},
+		"mysql": map[string]interface{}{"imageTag": "8.0", "replicas": float64(1)},
+		"redis": map[string]interface{}{"version": 6},
+	}, values)
+}
@@ -1,15 +1,40 @@
 package playbook
 
 import (
-	"fmt"
+	"os"
+
+	"github.com/Meetic/blackbeard/pkg/errors"
 )
 
 // Inventory represents a set of variable to apply to the templates (see config).
 // Namespace is the namespace dedicated files where to apply the variables contains into Values
 // Values is map of string that contains whatever the user set in the default inventory from a playbook
+// Profile is the size profile of the namespace, defined in the namespace.yaml file of the playbook.
+// The default profile of the playbook is used if it is empty.
+// SelfHeal is true if the inventory must be applied again when the namespace drifts from its configs.
+// PlaybookRef pins the git reference of the playbook used to generate the configs of the namespace.
+// Base is a copy of the default values the inventory was generated from, or last upgraded to. It is used to merge
+// the changes of the defaults into the inventory (see Upgrade) and should not be edited.
 type Inventory struct {
-	Namespace string                 `json:"namespace"`
-	Values    map[string]interface{} `json:"values"`
+	Namespace   string                 `json:"namespace"`
+	Profile     string                 `json:"profile,omitempty"`
+	SelfHeal    bool                   `json:"selfHeal,omitempty"`
+	PlaybookRef string                 `json:"playbookRef,omitempty"`
+	Values      map[string]interface{} `json:"values"`
+	Base        map[string]interface{} `json:"base,omitempty"`
+}
+
+// UpgradeReport describes the changes made to an inventory when merging the new defaults of the playbook.
+// Applied is true if the upgraded inventory has been applied to the namespace.
+type UpgradeReport struct {
+	Namespace string `json:"namespace"`
+	MergeResult
+	Applied bool `json:"applied"`
+}
+
+// Changed returns true if the upgrade changed some values of the inventory
+func (r UpgradeReport) Changed() bool {
+	return len(r.Added)+len(r.Updated)+len(r.Removed) > 0
 }
 
 // InventoryService define the way inventories are managed.
@@ -21,6 +46,8 @@ type InventoryService interface {
 	List() ([]Inventory, error)
 	Delete(namespace string) error
 	Reset(namespace string) (Inventory, error)
+	Upgrade(namespace string) (*UpgradeReport, error)
+	Diff(namespace, against string) (*InventoryDiff, error)
 }
 
 // InventoryRepository define the way inventories are actually managed
@@ -50,7 +77,7 @@ func NewInventoryService(inventories InventoryRepository, playbooks PlaybookServ
 func (is *inventoryService) Create(namespace string) (Inventory, error) {
 
 	if namespace == "" {
-		return Inventory{}, fmt.Errorf("A namespace cannot be empty")
+		return Inventory{}, errors.New(errors.Invalid, "A namespace cannot be empty")
 	}
 
 	def, err := is.playbooks.GetDefault()
@@ -61,6 +88,7 @@ func (is *inventoryService) Create(namespace string) (Inventory, error) {
 	inv := Inventory{
 		Namespace: namespace,
 		Values:    def.Values,
+		Base:      CopyValues(def.Values),
 	}
 
 	if err := is.inventories.Create(inv); err != nil {
@@ -73,7 +101,7 @@ func (is *inventoryService) Create(namespace string) (Inventory, error) {
 // Get returns the Inventory for a given namespace
 func (is *inventoryService) Get(namespace string) (Inventory, error) {
 	if namespace == "" {
-		return Inventory{}, fmt.Errorf("A namespace cannot be empty")
+		return Inventory{}, errors.New(errors.Invalid, "A namespace cannot be empty")
 	}
 
 	return is.inventories.Get(namespace)
@@ -101,8 +129,20 @@ func (is *inventoryService) Update(namespace string, inv Inventory) error {
 }
 
 // Reset override the inventory file for the given namespace base on the content of the default inventory.
+// The playbook ref pinned by the inventory, its profile and its self healing are kept, and the default inventory
+// of the ref is used.
 func (is *inventoryService) Reset(namespace string) (Inventory, error) {
-	def, err := is.playbooks.GetDefault()
+	var current Inventory
+	if inv, err := is.inventories.Get(namespace); err == nil {
+		current = inv
+	}
+
+	playbooks, err := is.playbooks.At(current.PlaybookRef)
+	if err != nil {
+		return Inventory{}, err
+	}
+
+	def, err := playbooks.GetDefault()
 	if err != nil {
 		return Inventory{}, err
 	}
@@ -110,7 +150,11 @@ func (is *inventoryService) Reset(namespace string) (Inventory, error) {
 	var inv Inventory
 
 	inv.Namespace = namespace
+	inv.Profile = current.Profile
+	inv.SelfHeal = current.SelfHeal
+	inv.PlaybookRef = current.PlaybookRef
 	inv.Values = def.Values
+	inv.Base = CopyValues(def.Values)
 
 	if err := is.inventories.Update(namespace, inv); err != nil {
 		return Inventory{}, err
@@ -119,47 +163,101 @@ func (is *inventoryService) Reset(namespace string) (Inventory, error) {
 	return inv, nil
 }
 
-// ErrorReadingDefaultsFile represents an error due to unreadable default inventory
-type ErrorReadingDefaultsFile struct {
-	msg string
-}
+// Upgrade merges the changes made to the defaults of the playbook since the inventory was generated, or last
+// upgraded, into the inventory (see MergeValues) and saves it. The defaults of the playbook ref pinned by the
+// inventory are used. The values changed both in the inventory and in the defaults are reported as conflicts
+// and keep their inventory value.
+// Inventories generated before the defaults were recorded in their base are merged as if every value differing
+// from the defaults had been changed in both.
+func (is *inventoryService) Upgrade(namespace string) (*UpgradeReport, error) {
+	inv, err := is.Get(namespace)
+	if err != nil {
+		return nil, err
+	}
 
-// Error returns the error message
-func (err ErrorReadingDefaultsFile) Error() string {
-	return err.msg
-}
+	playbooks, err := is.playbooks.At(inv.PlaybookRef)
+	if err != nil {
+		return nil, err
+	}
 
-// NewErrorReadingDefaultsFile creates an ErrorReadingDefaultsFile error
-func NewErrorReadingDefaultsFile(err error) ErrorReadingDefaultsFile {
-	return ErrorReadingDefaultsFile{fmt.Sprintf("Error when reading defaults file : %s", err.Error())}
-}
+	def, err := playbooks.GetDefault()
+	if err != nil {
+		return nil, err
+	}
 
-// ErrorInventoryAlreadyExist represents an error due to an already existing inventory for a given namespace
-type ErrorInventoryAlreadyExist struct {
-	msg string
-}
+	result := MergeValues(inv.Base, inv.Values, def.Values)
 
-// Error returns the error message
-func (err ErrorInventoryAlreadyExist) Error() string {
-	return err.msg
+	inv.Values = result.Values
+	inv.Base = CopyValues(def.Values)
+
+	if err := is.inventories.Update(namespace, inv); err != nil {
+		return nil, err
+	}
+
+	return &UpgradeReport{Namespace: namespace, MergeResult: result}, nil
 }
 
-// NewErrorInventoryAlreadyExist creates a new ErrorInventoryAlreadyExist error
-func NewErrorInventoryAlreadyExist(namespace string) ErrorInventoryAlreadyExist {
-	return ErrorInventoryAlreadyExist{fmt.Sprintf("An inventory for the namespace %s already exist", namespace)}
+// Diff returns the values differing between the inventory of a namespace and the inventory of the against
+// namespace (see DiffValues). If against is empty, the inventory is compared against the defaults of the playbook
+// ref it pins.
+func (is *inventoryService) Diff(namespace, against string) (*InventoryDiff, error) {
+	inv, err := is.Get(namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	var values map[string]interface{}
+
+	if against == "" {
+		playbooks, err := is.playbooks.At(inv.PlaybookRef)
+		if err != nil {
+			return nil, err
+		}
+
+		def, err := playbooks.GetDefault()
+		if err != nil {
+			return nil, err
+		}
+
+		values = def.Values
+	} else {
+		other, err := is.Get(against)
+		if err != nil {
+			return nil, err
+		}
+
+		values = other.Values
+	}
+
+	return &InventoryDiff{
+		Namespace:   namespace,
+		Against:     against,
+		Differences: DiffValues(inv.Values, values),
+	}, nil
 }
 
-// ErrorInventoryNotFound represents an error due to a missing inventory for the given namespace
-type ErrorInventoryNotFound struct {
-	msg string
+// NewErrorReadingDefaultsFile creates an error due to unreadable default inventory
+func NewErrorReadingDefaultsFile(err error) error {
+	kind := errors.Internal
+	if os.IsNotExist(err) {
+		kind = errors.NotFound
+	}
+
+	return errors.Wrap(err, kind, "Error when reading defaults file")
 }
 
-// Error returns the error message
-func (err ErrorInventoryNotFound) Error() string {
-	return err.msg
+// NewErrorInventoryAlreadyExist creates an error due to an already existing inventory for a given namespace
+func NewErrorInventoryAlreadyExist(namespace string) error {
+	return errors.WithDetail(
+		errors.New(errors.AlreadyExists, "An inventory for the namespace %s already exist", namespace),
+		"namespace", namespace,
+	)
 }
 
-// NewErrorInventoryNotFound creates a new ErrorInventoryNotFound error
-func NewErrorInventoryNotFound(namespace string) ErrorInventoryNotFound {
-	return ErrorInventoryNotFound{fmt.Sprintf("The inventory for %s does not exist.", namespace)}
+// NewErrorInventoryNotFound creates an error due to a missing inventory for the given namespace
+func NewErrorInventoryNotFound(namespace string) error {
+	return errors.WithDetail(
+		errors.New(errors.NotFound, "The inventory for %s does not exist.", namespace),
+		"namespace", namespace,
+	)
 }
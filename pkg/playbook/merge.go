@@ -0,0 +1,235 @@
+package playbook
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// Conflict is a value changed both in the inventory and in the defaults of the playbook since the inventory
+// was generated. The value of the inventory is kept.
+type Conflict struct {
+	Path    string      `json:"path"`
+	Base    interface{} `json:"base"`
+	Current interface{} `json:"current"`
+	Default interface{} `json:"default"`
+}
+
+// MergeResult is the result of the merge of the changes of the defaults into inventory values.
+// Added, Updated and Removed are the paths of the values changed by the merge.
+type MergeResult struct {
+	Values    map[string]interface{} `json:"-"`
+	Added     []string               `json:"added"`
+	Updated   []string               `json:"updated"`
+	Removed   []string               `json:"removed"`
+	Conflicts []Conflict             `json:"conflicts"`
+}
+
+// MergeValues merges the changes made to the default values since base into the current values of an inventory.
+// base is the copy of the defaults the inventory was generated from, current the values of the inventory and
+// defaults the new default values :
+//
+//   - a value only changed in the defaults is set in the inventory, a value only removed from the defaults is
+//     removed from the inventory, and a value only added to the defaults is added to the inventory;
+//   - a value changed in the inventory and unchanged in the defaults is kept;
+//   - a value changed in both is a conflict, unless both changes are the same. The inventory value is kept.
+//
+// Maps are merged key by key. Lists whose elements are maps having a "name" field, like the list of the
+// applications of a playbook, are merged element by element, matched by name. Other lists are merged as a whole.
+// The values given as parameters are not modified.
+func MergeValues(base, current, defaults map[string]interface{}) MergeResult {
+	result := MergeResult{
+		Added:     make([]string, 0),
+		Updated:   make([]string, 0),
+		Removed:   make([]string, 0),
+		Conflicts: make([]Conflict, 0),
+	}
+
+	result.Values = mergeMaps(&result, "", base, current, defaults)
+
+	return result
+}
+
+// value is a value which may be missing, which is different from a null value
+type value struct {
+	v       interface{}
+	present bool
+}
+
+func (v value) equal(o value) bool {
+	return v.present == o.present && reflect.DeepEqual(v.v, o.v)
+}
+
+// merge returns the merged value and whether it is present
+func merge(result *MergeResult, path string, base, current, defaults value) (interface{}, bool) {
+	if current.equal(defaults) || base.equal(defaults) {
+		return CopyValue(current.v), current.present
+	}
+
+	// maps and named lists are merged element by element, reporting the paths of the changed values
+	if c, ok := current.v.(map[string]interface{}); ok {
+		if d, ok := defaults.v.(map[string]interface{}); ok {
+			b, _ := base.v.(map[string]interface{})
+			return mergeMaps(result, path, b, c, d), true
+		}
+	}
+
+	if c, ok := namedList(current.v); ok {
+		if d, ok := namedList(defaults.v); ok {
+			b, _ := namedList(base.v)
+			return mergeLists(result, path, b, c, d), true
+		}
+	}
+
+	if base.equal(current) {
+		switch {
+		case !current.present:
+			result.Added = append(result.Added, path)
+		case !defaults.present:
+			result.Removed = append(result.Removed, path)
+		default:
+			result.Updated = append(result.Updated, path)
+		}
+		return CopyValue(defaults.v), defaults.present
+	}
+
+	result.Conflicts = append(result.Conflicts, Conflict{Path: path, Base: base.v, Current: current.v, Default: defaults.v})
+
+	return CopyValue(current.v), current.present
+}
+
+func mergeMaps(result *MergeResult, path string, base, current, defaults map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{})
+
+	for _, key := range sortedKeys(current, defaults) {
+		p := key
+		if path != "" {
+			p = path + "." + key
+		}
+
+		b, inBase := base[key]
+		c, inCurrent := current[key]
+		d, inDefaults := defaults[key]
+
+		if v, ok := merge(result, p, value{b, inBase}, value{c, inCurrent}, value{d, inDefaults}); ok {
+			merged[key] = v
+		}
+	}
+
+	return merged
+}
+
+// mergeLists merges lists of maps matched by their name. The merged list follows the order of the current list,
+// followed by the elements added to the defaults.
+func mergeLists(result *MergeResult, path string, base, current, defaults []interface{}) []interface{} {
+	b, c, d := byName(base), byName(current), byName(defaults)
+
+	var names []string
+	seen := make(map[string]bool)
+	for _, list := range [][]interface{}{current, defaults} {
+		for _, elem := range list {
+			name := elem.(map[string]interface{})["name"].(string)
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+
+	merged := make([]interface{}, 0, len(names))
+
+	for _, name := range names {
+		p := fmt.Sprintf("%s[%s]", path, name)
+
+		bv, inBase := b[name]
+		cv, inCurrent := c[name]
+		dv, inDefaults := d[name]
+
+		if v, ok := merge(result, p, value{bv, inBase}, value{cv, inCurrent}, value{dv, inDefaults}); ok {
+			merged = append(merged, v)
+		}
+	}
+
+	return merged
+}
+
+// namedList returns the list if all its elements are maps having a unique "name" string field
+func namedList(v interface{}) ([]interface{}, bool) {
+	list, ok := v.([]interface{})
+	if !ok || len(list) == 0 {
+		return nil, false
+	}
+
+	names := make(map[string]bool, len(list))
+	for _, elem := range list {
+		m, ok := elem.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		name, ok := m["name"].(string)
+		if !ok || names[name] {
+			return nil, false
+		}
+		names[name] = true
+	}
+
+	return list, true
+}
+
+func byName(list []interface{}) map[string]interface{} {
+	elems := make(map[string]interface{}, len(list))
+	for _, elem := range list {
+		m := elem.(map[string]interface{})
+		elems[m["name"].(string)] = m
+	}
+
+	return elems
+}
+
+func sortedKeys(maps ...map[string]interface{}) []string {
+	var keys []string
+	seen := make(map[string]bool)
+
+	for _, m := range maps {
+		for k := range m {
+			if !seen[k] {
+				seen[k] = true
+				keys = append(keys, k)
+			}
+		}
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+// CopyValues returns a deep copy of inventory values decoded from json
+func CopyValues(values map[string]interface{}) map[string]interface{} {
+	if values == nil {
+		return nil
+	}
+
+	c := make(map[string]interface{}, len(values))
+	for k, v := range values {
+		c[k] = CopyValue(v)
+	}
+
+	return c
+}
+
+// CopyValue returns a deep copy of a value decoded from json
+func CopyValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return CopyValues(v)
+	case []interface{}:
+		c := make([]interface{}, len(v))
+		for i := range v {
+			c[i] = CopyValue(v[i])
+		}
+		return c
+	}
+
+	return value
+}
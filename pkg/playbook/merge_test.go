@@ -0,0 +1,289 @@
+package playbook_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Meetic/blackbeard/pkg/mock"
+	"github.com/Meetic/blackbeard/pkg/playbook"
+)
+
+func values(t *testing.T, s string) map[string]interface{} {
+	if s == "" {
+		return nil
+	}
+
+	var v map[string]interface{}
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		t.Fatalf("invalid values %s : %v", s, err)
+	}
+
+	return v
+}
+
+func TestMergeValues(t *testing.T) {
+	tests := []struct {
+		name      string
+		base      string
+		current   string
+		defaults  string
+		expected  string
+		added     []string
+		updated   []string
+		removed   []string
+		conflicts []string
+	}{
+		{
+			name:     "unchanged",
+			base:     `{"version":"1.0"}`,
+			current:  `{"version":"1.0"}`,
+			defaults: `{"version":"1.0"}`,
+			expected: `{"version":"1.0"}`,
+		},
+		{
+			name:     "key added to the defaults",
+			base:     `{"version":"1.0"}`,
+			current:  `{"version":"1.0"}`,
+			defaults: `{"version":"1.0","replicas":2}`,
+			expected: `{"version":"1.0","replicas":2}`,
+			added:    []string{"replicas"},
+		},
+		{
+			name:     "value changed in the defaults",
+			base:     `{"version":"1.0"}`,
+			current:  `{"version":"1.0"}`,
+			defaults: `{"version":"2.0"}`,
+			expected: `{"version":"2.0"}`,
+			updated:  []string{"version"},
+		},
+		{
+			name:     "key removed from the defaults",
+			base:     `{"version":"1.0","debug":true}`,
+			current:  `{"version":"1.0","debug":true}`,
+			defaults: `{"version":"1.0"}`,
+			expected: `{"version":"1.0"}`,
+			removed:  []string{"debug"},
+		},
+		{
+			name:     "value changed in the inventory",
+			base:     `{"version":"1.0"}`,
+			current:  `{"version":"1.1"}`,
+			defaults: `{"version":"1.0"}`,
+			expected: `{"version":"1.1"}`,
+		},
+		{
+			name:     "key added to the inventory",
+			base:     `{"version":"1.0"}`,
+			current:  `{"version":"1.0","debug":true}`,
+			defaults: `{"version":"1.0"}`,
+			expected: `{"version":"1.0","debug":true}`,
+		},
+		{
+			name:     "key removed from the inventory",
+			base:     `{"version":"1.0","debug":true}`,
+			current:  `{"version":"1.0"}`,
+			defaults: `{"version":"1.0","debug":true}`,
+			expected: `{"version":"1.0"}`,
+		},
+		{
+			name:     "same change on both sides",
+			base:     `{"version":"1.0"}`,
+			current:  `{"version":"2.0"}`,
+			defaults: `{"version":"2.0"}`,
+			expected: `{"version":"2.0"}`,
+		},
+		{
+			name:      "value changed on both sides",
+			base:      `{"version":"1.0"}`,
+			current:   `{"version":"1.1"}`,
+			defaults:  `{"version":"2.0"}`,
+			expected:  `{"version":"1.1"}`,
+			conflicts: []string{"version"},
+		},
+		{
+			name:      "value removed from the defaults and changed in the inventory",
+			base:      `{"debug":false}`,
+			current:   `{"debug":true}`,
+			defaults:  `{}`,
+			expected:  `{"debug":true}`,
+			conflicts: []string{"debug"},
+		},
+		{
+			name:      "key added on both sides with different values",
+			base:      `{}`,
+			current:   `{"replicas":3}`,
+			defaults:  `{"replicas":2}`,
+			expected:  `{"replicas":3}`,
+			conflicts: []string{"replicas"},
+		},
+		{
+			name:     "nested maps merged key by key",
+			base:     `{"front":{"version":"1.0","replicas":1}}`,
+			current:  `{"front":{"version":"1.1","replicas":1}}`,
+			defaults: `{"front":{"version":"1.0","replicas":2,"cdn":true}}`,
+			expected: `{"front":{"version":"1.1","replicas":2,"cdn":true}}`,
+			added:    []string{"front.cdn"},
+			updated:  []string{"front.replicas"},
+		},
+		{
+			name:      "map replaced by a scalar",
+			base:      `{"front":{"version":"1.0"}}`,
+			current:   `{"front":{"version":"1.1"}}`,
+			defaults:  `{"front":"disabled"}`,
+			expected:  `{"front":{"version":"1.1"}}`,
+			conflicts: []string{"front"},
+		},
+		{
+			name:     "application added to a list of named applications",
+			base:     `{"microservices":[{"name":"api","version":"1.0"}]}`,
+			current:  `{"microservices":[{"name":"api","version":"1.1"}]}`,
+			defaults: `{"microservices":[{"name":"api","version":"1.0"},{"name":"search","version":"1.0"}]}`,
+			expected: `{"microservices":[{"name":"api","version":"1.1"},{"name":"search","version":"1.0"}]}`,
+			added:    []string{"microservices[search]"},
+		},
+		{
+			name:     "application removed from a list of named applications",
+			base:     `{"microservices":[{"name":"api","version":"1.0"},{"name":"legacy","version":"1.0"}]}`,
+			current:  `{"microservices":[{"name":"api","version":"1.1"},{"name":"legacy","version":"1.0"}]}`,
+			defaults: `{"microservices":[{"name":"api","version":"1.0"}]}`,
+			expected: `{"microservices":[{"name":"api","version":"1.1"}]}`,
+			removed:  []string{"microservices[legacy]"},
+		},
+		{
+			name:     "application added to the inventory is kept",
+			base:     `{"microservices":[{"name":"api","version":"1.0"}]}`,
+			current:  `{"microservices":[{"name":"mock","version":"1.0"},{"name":"api","version":"1.0"}]}`,
+			defaults: `{"microservices":[{"name":"api","version":"2.0"}]}`,
+			expected: `{"microservices":[{"name":"mock","version":"1.0"},{"name":"api","version":"2.0"}]}`,
+			updated:  []string{"microservices[api].version"},
+		},
+		{
+			name:      "application changed on both sides",
+			base:      `{"microservices":[{"name":"api","version":"1.0"}]}`,
+			current:   `{"microservices":[{"name":"api","version":"1.1"}]}`,
+			defaults:  `{"microservices":[{"name":"api","version":"2.0"}]}`,
+			expected:  `{"microservices":[{"name":"api","version":"1.1"}]}`,
+			conflicts: []string{"microservices[api].version"},
+		},
+		{
+			name:     "list of scalars changed in the defaults",
+			base:     `{"urls":["a"]}`,
+			current:  `{"urls":["a"]}`,
+			defaults: `{"urls":["a","b"]}`,
+			expected: `{"urls":["a","b"]}`,
+			updated:  []string{"urls"},
+		},
+		{
+			name:      "list of scalars changed on both sides",
+			base:      `{"urls":["a"]}`,
+			current:   `{"urls":["a","c"]}`,
+			defaults:  `{"urls":["a","b"]}`,
+			expected:  `{"urls":["a","c"]}`,
+			conflicts: []string{"urls"},
+		},
+		{
+			name:     "inventory without base gets the new keys",
+			current:  `{"version":"1.0"}`,
+			defaults: `{"version":"1.0","replicas":2}`,
+			expected: `{"version":"1.0","replicas":2}`,
+			added:    []string{"replicas"},
+		},
+		{
+			name:      "inventory without base reports every difference as a conflict",
+			current:   `{"version":"1.1","debug":true}`,
+			defaults:  `{"version":"2.0"}`,
+			expected:  `{"version":"1.1","debug":true}`,
+			conflicts: []string{"version"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			base, current, defaults := values(t, test.base), values(t, test.current), values(t, test.defaults)
+
+			result := playbook.MergeValues(base, current, defaults)
+
+			assert.Equal(t, values(t, test.expected), result.Values)
+			assert.ElementsMatch(t, test.added, result.Added)
+			assert.ElementsMatch(t, test.updated, result.Updated)
+			assert.ElementsMatch(t, test.removed, result.Removed)
+
+			var conflicts []string
+			for _, c := range result.Conflicts {
+				conflicts = append(conflicts, c.Path)
+			}
+			assert.ElementsMatch(t, test.conflicts, conflicts)
+
+			// the given values are not modified
+			assert.Equal(t, values(t, test.current), current)
+			assert.Equal(t, values(t, test.defaults), defaults)
+		})
+	}
+}
+
+func TestMergeValuesConflict(t *testing.T) {
+	result := playbook.MergeValues(values(t, `{"version":"1.0"}`), values(t, `{"version":"1.1"}`), values(t, `{"version":"2.0"}`))
+
+	assert.Equal(t, []playbook.Conflict{{Path: "version", Base: "1.0", Current: "1.1", Default: "2.0"}}, result.Conflicts)
+}
+
+// memoryInventories is an InventoryRepository keeping the inventories in memory
+type memoryInventories struct {
+	playbook.InventoryRepository
+	inventories map[string]playbook.Inventory
+}
+
+func (r *memoryInventories) Get(namespace string) (playbook.Inventory, error) {
+	inv, ok := r.inventories[namespace]
+	if !ok {
+		return playbook.Inventory{}, playbook.NewErrorInventoryNotFound(namespace)
+	}
+
+	return inv, nil
+}
+
+func (r *memoryInventories) Update(namespace string, inv playbook.Inventory) error {
+	r.inventories[namespace] = inv
+	return nil
+}
+
+func TestUpgradeOk(t *testing.T) {
+	def, _ := playbooks.GetDefault()
+
+	base := playbook.CopyValues(def.Values)
+	base["microservices"] = base["microservices"].([]interface{})[:1]
+
+	current := playbook.CopyValues(base)
+	current["microservices"].([]interface{})[0].(map[string]interface{})["version"] = "1.2.0"
+
+	repo := &memoryInventories{inventories: map[string]playbook.Inventory{
+		"test": {Namespace: "test", Values: current, Base: base},
+	}}
+
+	report, err := playbook.NewInventoryService(repo, playbooks).Upgrade("test")
+	assert.Nil(t, err)
+	assert.True(t, report.Changed())
+	assert.Equal(t, []string{"microservices[api-algo]"}, report.Added)
+	assert.Empty(t, report.Conflicts)
+
+	inv := repo.inventories["test"]
+	assert.Equal(t, def.Values, inv.Base)
+
+	microservices := inv.Values["microservices"].([]interface{})
+	assert.Len(t, microservices, 2)
+	assert.Equal(t, "1.2.0", microservices[0].(map[string]interface{})["version"])
+
+	// a second upgrade does not change anything
+	report, err = playbook.NewInventoryService(repo, playbooks).Upgrade("test")
+	assert.Nil(t, err)
+	assert.False(t, report.Changed())
+}
+
+func TestUpgradeNotFound(t *testing.T) {
+	repo := &memoryInventories{inventories: map[string]playbook.Inventory{}}
+
+	_, err := playbook.NewInventoryService(repo, playbook.NewPlaybookService(mock.NewPlaybookRepository())).Upgrade("test")
+	assert.Error(t, err)
+}
@@ -1,6 +1,9 @@
 package playbook
 
-import "text/template"
+import (
+	"text/template"
+	"time"
+)
 
 // ConfigTemplate represents a set of kubernetes configuration template.
 // Usually, Template is expected to be golang template of yaml.
@@ -9,16 +12,58 @@ type ConfigTemplate struct {
 	Template *template.Template
 }
 
+// Version identifies the playbook used to generate configs.
+// Source is the playbook directory or git repository. When the playbook is read from git, Ref is the
+// followed git reference, Commit the commit in use and UpdatedAt the last time the repository was fetched.
+type Version struct {
+	Source    string     `json:"source"`
+	Ref       string     `json:"ref,omitempty"`
+	Commit    string     `json:"commit,omitempty"`
+	UpdatedAt *time.Time `json:"updatedAt,omitempty"`
+}
+
+// String returns the ref and the short commit of a git playbook, or the source of a playbook directory
+func (v Version) String() string {
+	if v.Commit == "" {
+		return v.Source
+	}
+
+	commit := v.Commit
+	if len(commit) > 12 {
+		commit = commit[:12]
+	}
+
+	return v.Ref + "@" + commit
+}
+
+// Status is the load status of a playbook.
+// Loaded is true if a valid version of the playbook is in use, loaded at LoadedAt.
+// Error is the reason why the last load failed, at FailedAt. The previous valid version, if any, is then still in use.
+type Status struct {
+	Version  Version    `json:"version"`
+	Loaded   bool       `json:"loaded"`
+	LoadedAt *time.Time `json:"loadedAt,omitempty"`
+	Error    string     `json:"error,omitempty"`
+	FailedAt *time.Time `json:"failedAt,omitempty"`
+}
+
 // PlaybookService represents the way playbook are managed
 type PlaybookService interface {
 	GetDefault() (Inventory, error)
 	GetTemplate() ([]ConfigTemplate, error)
+	Version() (Version, error)
+	Status() (Status, error)
+	At(ref string) (PlaybookService, error)
 }
 
-// PlaybookRepository is an actual implementation of playbook management
+// PlaybookRepository is an actual implementation of playbook management.
+// At returns the playbook at the given git reference, or the playbook itself if ref is empty.
 type PlaybookRepository interface {
 	GetDefault() (Inventory, error)
 	GetTemplate() ([]ConfigTemplate, error)
+	Version() (Version, error)
+	Status() (Status, error)
+	At(ref string) (PlaybookRepository, error)
 }
 
 type playbookService struct {
@@ -41,3 +86,27 @@ func (ps *playbookService) GetTemplate() ([]ConfigTemplate, error) {
 func (ps *playbookService) GetDefault() (Inventory, error) {
 	return ps.playbooks.GetDefault()
 }
+
+// Version returns the version of the playbook in use
+func (ps *playbookService) Version() (Version, error) {
+	return ps.playbooks.Version()
+}
+
+// Status returns the load status of the playbook in use
+func (ps *playbookService) Status() (Status, error) {
+	return ps.playbooks.Status()
+}
+
+// At returns the playbook at the given git reference, or the playbook in use if ref is empty
+func (ps *playbookService) At(ref string) (PlaybookService, error) {
+	if ref == "" {
+		return ps, nil
+	}
+
+	playbooks, err := ps.playbooks.At(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewPlaybookService(playbooks), nil
+}
@@ -0,0 +1,217 @@
+package resource
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// AnnotationChecks declares smoke checks on a Service, an Ingress or an HTTPRoute.
+// Its value is a json array of checks, ex: [{"path": "/health", "status": 200}]
+const AnnotationChecks = "blackbeard.io/checks"
+
+// Probes used to check a service
+const (
+	ProbeHTTP = "http"
+	ProbeTCP  = "tcp"
+)
+
+// SmokeCheck is an http request declared on an exposed service.
+// Path is requested on the service url and Status is the expected response status, 200 by default.
+type SmokeCheck struct {
+	Path   string `json:"path"`
+	Status int    `json:"status,omitempty"`
+}
+
+// CheckResult is the result of a probe.
+// Expected is the expected http status. When it is 0, any response which is not a server error passes.
+type CheckResult struct {
+	Service   string `json:"service"`
+	Source    string `json:"source"`
+	Probe     string `json:"probe"`
+	Target    string `json:"target"`
+	Expected  int    `json:"expected,omitempty"`
+	Status    int    `json:"status,omitempty"`
+	Passed    bool   `json:"passed"`
+	LatencyMs int64  `json:"latencyMs"`
+	Error     string `json:"error,omitempty"`
+}
+
+// CheckReport is the result of the checks of a namespace. It passes if all checks passed.
+type CheckReport struct {
+	Namespace string        `json:"namespace"`
+	Passed    bool          `json:"passed"`
+	Results   []CheckResult `json:"results"`
+}
+
+// CheckService checks that the services exposed by a namespace are reachable
+type CheckService interface {
+	Check(ctx context.Context, namespace string) (*CheckReport, error)
+}
+
+type checkService struct {
+	services ServiceService
+	client   *http.Client
+}
+
+// NewCheckService returns a CheckService using client for http probes.
+// The timeout of the client is also used for tcp probes. A client with a 5s timeout is used if client is nil.
+func NewCheckService(services ServiceService, client *http.Client) CheckService {
+	if client == nil {
+		client = &http.Client{Timeout: 5 * time.Second}
+	}
+
+	return &checkService{
+		services: services,
+		client:   client,
+	}
+}
+
+// Check probes every exposed service of a namespace.
+// Ingresses and HTTPRoutes are requested using http, NodePort and LoadBalancer services are dialed using tcp.
+// Smoke checks declared on a service are run in addition to its default probe.
+func (cs *checkService) Check(ctx context.Context, namespace string) (*CheckReport, error) {
+	services, err := cs.services.ListExposed(namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	probes := probesOf(services)
+	results := make([]CheckResult, len(probes))
+
+	var wg sync.WaitGroup
+
+	for i := range probes {
+		wg.Add(1)
+
+		go func(index int) {
+			defer wg.Done()
+			results[index] = cs.probe(ctx, probes[index])
+		}(i)
+	}
+
+	wg.Wait()
+
+	report := &CheckReport{Namespace: namespace, Passed: true, Results: results}
+	for _, r := range results {
+		if !r.Passed {
+			report.Passed = false
+		}
+	}
+
+	return report, nil
+}
+
+// probesOf returns the probes to run for the given services, without duplicates.
+// Results are returned with an empty Passed field, filled by probe.
+func probesOf(services []Service) []CheckResult {
+	var probes []CheckResult
+	seen := make(map[string]bool)
+
+	add := func(p CheckResult) {
+		key := p.Probe + " " + p.Target + " " + strconv.Itoa(p.Expected)
+		if seen[key] {
+			return
+		}
+		seen[key] = true
+		probes = append(probes, p)
+	}
+
+	for _, svc := range services {
+		addr, _, _ := strings.Cut(svc.Addr, ",")
+
+		var exposed int32
+		if len(svc.Ports) > 0 {
+			exposed = svc.Ports[0].ExposedPort
+		}
+
+		// the default probe dials each port of node port and load balancer services, and requests the other urls
+		if svc.Source == SourceNodePort || svc.Source == SourceLoadBalancer {
+			for _, port := range svc.Ports {
+				p := CheckResult{Service: svc.Name, Source: svc.Source, Probe: ProbeTCP}
+				if addr != "" {
+					p.Target = net.JoinHostPort(addr, strconv.Itoa(int(port.ExposedPort)))
+				}
+
+				add(p)
+			}
+		} else {
+			add(CheckResult{Service: svc.Name, Source: svc.Source, Probe: ProbeHTTP, Target: svc.URL})
+		}
+
+		for _, c := range svc.Checks {
+			status := c.Status
+			if status == 0 {
+				status = http.StatusOK
+			}
+
+			add(CheckResult{
+				Service:  svc.Name,
+				Source:   svc.Source,
+				Probe:    ProbeHTTP,
+				Target:   ServiceURL(svc.TLS, addr, exposed, c.Path),
+				Expected: status,
+			})
+		}
+	}
+
+	return probes
+}
+
+// probe runs a probe and returns its result
+func (cs *checkService) probe(ctx context.Context, p CheckResult) CheckResult {
+	if p.Target == "" {
+		p.Error = "the service has no address"
+		return p
+	}
+
+	start := time.Now()
+
+	if p.Probe == ProbeTCP {
+		dialer := net.Dialer{Timeout: cs.client.Timeout}
+
+		conn, err := dialer.DialContext(ctx, "tcp", p.Target)
+		p.LatencyMs = time.Since(start).Milliseconds()
+		if err != nil {
+			p.Error = err.Error()
+			return p
+		}
+
+		conn.Close()
+		p.Passed = true
+
+		return p
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.Target, nil)
+	if err != nil {
+		p.Error = err.Error()
+		return p
+	}
+
+	resp, err := cs.client.Do(req)
+	p.LatencyMs = time.Since(start).Milliseconds()
+	if err != nil {
+		p.Error = err.Error()
+		return p
+	}
+	resp.Body.Close()
+
+	p.Status = resp.StatusCode
+
+	switch {
+	case p.Expected != 0 && p.Status != p.Expected:
+		p.Error = fmt.Sprintf("unexpected status %d, expected %d", p.Status, p.Expected)
+	case p.Expected == 0 && p.Status >= http.StatusInternalServerError:
+		p.Error = fmt.Sprintf("server error %d", p.Status)
+	default:
+		p.Passed = true
+	}
+
+	return p
+}
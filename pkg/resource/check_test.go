@@ -0,0 +1,99 @@
+package resource_test
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Meetic/blackbeard/pkg/resource"
+)
+
+// exposedServices is a ServiceRepository returning fixed services
+type exposedServices struct {
+	external, ingress []resource.Service
+}
+
+func (s exposedServices) ListExternal(string) ([]resource.Service, error) { return s.external, nil }
+func (s exposedServices) ListIngress(string) ([]resource.Service, error)  { return s.ingress, nil }
+func (s exposedServices) ListRoutes(string) ([]resource.Service, error)   { return nil, nil }
+
+func TestCheck(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/health":
+			w.WriteHeader(http.StatusOK)
+		case "/broken":
+			w.WriteHeader(http.StatusBadGateway)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	u, _ := url.Parse(srv.URL)
+	host, p, _ := net.SplitHostPort(u.Host)
+	port, _ := strconv.Atoi(p)
+
+	// a closed port, used as an unreachable node port
+	l, _ := net.Listen("tcp", "127.0.0.1:0")
+	closed := l.Addr().(*net.TCPAddr).Port
+	l.Close()
+
+	l, _ = net.Listen("tcp", "127.0.0.1:0")
+	closedMetrics := l.Addr().(*net.TCPAddr).Port
+	l.Close()
+
+	checks := resource.NewCheckService(resource.NewServiceService(exposedServices{
+		external: []resource.Service{
+			{
+				Name: "api", Addr: host, Source: resource.SourceNodePort,
+				Ports: []resource.Port{{Port: 80, ExposedPort: int32(port)}, {Port: 9090, ExposedPort: int32(closedMetrics)}},
+			},
+			{Name: "db", Addr: host, Source: resource.SourceNodePort, Ports: []resource.Port{{Port: 3306, ExposedPort: int32(closed)}}},
+			{Name: "pending", Source: resource.SourceLoadBalancer, Ports: []resource.Port{{Port: 80, ExposedPort: 80}}},
+		},
+		ingress: []resource.Service{
+			{Name: "front", Addr: u.Host, URL: srv.URL + "/", Source: resource.SourceIngress, Ports: []resource.Port{{Port: 80, ExposedPort: 80}}},
+			{
+				Name: "back", Addr: u.Host, URL: srv.URL + "/back", Source: resource.SourceIngress, Ports: []resource.Port{{Port: 80, ExposedPort: 80}},
+				Checks: []resource.SmokeCheck{{Path: "/health"}, {Path: "/broken", Status: 502}, {Path: "/missing"}},
+			},
+		},
+	}), nil)
+
+	report, err := checks.Check(context.Background(), "test")
+	assert.Nil(t, err)
+	assert.Equal(t, "test", report.Namespace)
+	assert.False(t, report.Passed)
+
+	results := make(map[string]resource.CheckResult)
+	for _, r := range report.Results {
+		results[r.Service+" "+r.Target] = r
+	}
+
+	assert.Len(t, results, 9)
+	assert.True(t, results["api "+u.Host].Passed)
+	assert.Equal(t, resource.ProbeTCP, results["api "+u.Host].Probe)
+	// each port of a node port service is probed
+	assert.Equal(t, resource.ProbeTCP, results["api "+net.JoinHostPort(host, strconv.Itoa(closedMetrics))].Probe)
+	assert.False(t, results["api "+net.JoinHostPort(host, strconv.Itoa(closedMetrics))].Passed)
+	assert.False(t, results["db "+net.JoinHostPort(host, strconv.Itoa(closed))].Passed)
+	assert.Equal(t, "the service has no address", results["pending "].Error)
+
+	// any response which is not a server error passes the default probe
+	assert.True(t, results["front "+srv.URL+"/"].Passed)
+	assert.Equal(t, http.StatusNotFound, results["front "+srv.URL+"/"].Status)
+
+	// smoke checks do not replace the default probe
+	assert.True(t, results["back "+srv.URL+"/back"].Passed)
+	assert.True(t, results["back "+srv.URL+"/health"].Passed)
+	assert.True(t, results["back "+srv.URL+"/broken"].Passed)
+	assert.False(t, results["back "+srv.URL+"/missing"].Passed)
+	assert.Equal(t, "unexpected status 404, expected 200", results["back "+srv.URL+"/missing"].Error)
+}
@@ -2,9 +2,12 @@ package resource
 
 type Deployments []Deployment
 
+// Deployment is a kubernetes deployment. Images are the images of its containers, indexed by container name.
 type Deployment struct {
-	Name   string
-	Status DeploymentStatus
+	Name        string
+	Status      DeploymentStatus
+	Annotations map[string]string
+	Images      map[string]string
 }
 
 type DeploymentStatus string
@@ -16,4 +19,5 @@ const (
 
 type DeploymentRepository interface {
 	List(namespace string) (Deployments, error)
+	Restart(namespace, name string) error
 }
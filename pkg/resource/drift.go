@@ -0,0 +1,83 @@
+package resource
+
+import "time"
+
+// FieldDrift is a field of an object whose live value differs from the rendered configs.
+// Actual is nil when the field is missing from the live object.
+type FieldDrift struct {
+	Path     string      `json:"path"`
+	Expected interface{} `json:"expected"`
+	Actual   interface{} `json:"actual"`
+}
+
+// ObjectDrift describes how a live object differs from the rendered configs.
+// Missing is true if the object does not exist in the namespace.
+type ObjectDrift struct {
+	Kind    string       `json:"kind"`
+	Name    string       `json:"name"`
+	Missing bool         `json:"missing,omitempty"`
+	Fields  []FieldDrift `json:"fields,omitempty"`
+}
+
+// Drifted returns true if the object is missing or if some of its fields differ from the rendered configs
+func (o ObjectDrift) Drifted() bool {
+	return o.Missing || len(o.Fields) > 0
+}
+
+// DriftReport is the result of the comparison of the live objects of a namespace with its rendered configs.
+// Checked is the number of objects compared and Objects only contains the drifted ones.
+type DriftReport struct {
+	Namespace string        `json:"namespace"`
+	Drifted   bool          `json:"drifted"`
+	Checked   int           `json:"checked"`
+	Objects   []ObjectDrift `json:"objects"`
+	CheckedAt time.Time     `json:"checkedAt"`
+}
+
+// DriftService detects the changes made to the objects of a namespace since its configs were applied
+type DriftService interface {
+	Drift(namespace string, manifests []string) (*DriftReport, error)
+}
+
+// DriftRepository compares the objects described by yaml manifests with the live objects of a namespace.
+// Only the fields set in the manifests are compared, so the fields defaulted by kubernetes are not seen as drifts.
+// Compare returns a drift for every object of the manifests.
+type DriftRepository interface {
+	Compare(namespace string, manifests []string) ([]ObjectDrift, error)
+}
+
+type driftService struct {
+	drifts DriftRepository
+}
+
+// NewDriftService returns a new DriftService
+func NewDriftService(drifts DriftRepository) DriftService {
+	return &driftService{
+		drifts: drifts,
+	}
+}
+
+// Drift compares the live objects of a namespace with the given rendered configs
+func (s *driftService) Drift(namespace string, manifests []string) (*DriftReport, error) {
+	objects, err := s.drifts.Compare(namespace, manifests)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &DriftReport{
+		Namespace: namespace,
+		Checked:   len(objects),
+		Objects:   make([]ObjectDrift, 0),
+		CheckedAt: time.Now().UTC(),
+	}
+
+	for _, o := range objects {
+		if o.Drifted() {
+			report.Objects = append(report.Objects, o)
+		}
+	}
+
+	report.Drifted = len(report.Objects) > 0
+
+	return report, nil
+}
@@ -0,0 +1,192 @@
+package resource_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+
+	"github.com/Meetic/blackbeard/pkg/errors"
+	"github.com/Meetic/blackbeard/pkg/kubernetes"
+	"github.com/Meetic/blackbeard/pkg/resource"
+)
+
+const driftManifests = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: api
+  namespace: test
+spec:
+  replicas: 1
+  template:
+    spec:
+      containers:
+        - name: api
+          image: api:v1
+          resources:
+            limits:
+              cpu: "0.5"
+            requests: {}
+          env:
+            - name: MODE
+              value: feature
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: api
+spec:
+  ports:
+    - port: 80
+      targetPort: 8080
+      protocol: TCP
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+data:
+  cpu: "0.5"
+`
+
+func TestDrift(t *testing.T) {
+	dynamic := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme())
+
+	live := []*unstructured.Unstructured{
+		{Object: map[string]interface{}{
+			"apiVersion": "apps/v1",
+			"kind":       "Deployment",
+			"metadata":   map[string]interface{}{"name": "api", "namespace": "test", "generation": int64(4)},
+			"spec": map[string]interface{}{
+				"replicas": int64(3),
+				"template": map[string]interface{}{
+					"spec": map[string]interface{}{
+						"containers": []interface{}{map[string]interface{}{
+							"name":                   "api",
+							"image":                  "api:v1",
+							"imagePullPolicy":        "IfNotPresent",
+							"resources":              map[string]interface{}{"limits": map[string]interface{}{"cpu": "500m"}},
+							"terminationMessagePath": "/dev/termination-log",
+						}},
+					},
+				},
+			},
+			"status": map[string]interface{}{"replicas": int64(3)},
+		}},
+		{Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "Service",
+			"metadata":   map[string]interface{}{"name": "api", "namespace": "test"},
+			"spec": map[string]interface{}{
+				"clusterIP": "10.0.0.12",
+				"ports": []interface{}{map[string]interface{}{
+					"port":       int64(80),
+					"targetPort": int64(8080),
+					"protocol":   "TCP",
+				}},
+			},
+		}},
+	}
+
+	resources := map[string]schema.GroupVersionResource{
+		"Deployment": {Group: "apps", Version: "v1", Resource: "deployments"},
+		"Service":    {Version: "v1", Resource: "services"},
+	}
+	for _, obj := range live {
+		assert.Nil(t, dynamic.Tracker().Create(resources[obj.GetKind()], obj, "test"))
+	}
+
+	drifts := resource.NewDriftService(kubernetes.NewDriftRepository(dynamic))
+
+	report, err := drifts.Drift("test", []string{driftManifests})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "test", report.Namespace)
+	assert.True(t, report.Drifted)
+	assert.Equal(t, 3, report.Checked)
+	assert.Equal(t, []resource.ObjectDrift{
+		{
+			Kind: "Deployment",
+			Name: "api",
+			Fields: []resource.FieldDrift{
+				{Path: "spec.replicas", Expected: int64(1), Actual: int64(3)},
+				{Path: "spec.template.spec.containers[0].env", Expected: []interface{}{
+					map[string]interface{}{"name": "MODE", "value": "feature"},
+				}},
+			},
+		},
+		{Kind: "ConfigMap", Name: "settings", Missing: true},
+	}, report.Objects)
+
+	assert.Nil(t, dynamic.Tracker().Create(
+		schema.GroupVersionResource{Version: "v1", Resource: "configmaps"},
+		&unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata":   map[string]interface{}{"name": "settings", "namespace": "test"},
+			"data":       map[string]interface{}{"cpu": "0.5"},
+		}},
+		"test",
+	))
+
+	report, err = drifts.Drift("test", []string{driftManifests})
+	assert.Nil(t, err)
+	assert.Len(t, report.Objects, 1)
+	assert.Equal(t, "Deployment", report.Objects[0].Kind)
+
+	report, err = drifts.Drift("test", []string{strings.Replace(driftManifests, `cpu: "0.5"`, `cpu: "1"`, 1)})
+	assert.Nil(t, err)
+	assert.Contains(t, report.Objects[0].Fields, resource.FieldDrift{
+		Path:     "spec.template.spec.containers[0].resources.limits.cpu",
+		Expected: "1",
+		Actual:   "500m",
+	})
+
+	_, err = drifts.Drift("test", []string{"kind: [Deployment"})
+	assert.True(t, errors.Is(err, errors.Invalid))
+}
+
+const secretManifest = `apiVersion: v1
+kind: Secret
+metadata:
+  name: credentials
+data:
+  user: YWRtaW4=
+stringData:
+  password: s3cr3t
+`
+
+func TestDriftSecretStringData(t *testing.T) {
+	dynamic := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme())
+
+	assert.Nil(t, dynamic.Tracker().Create(
+		schema.GroupVersionResource{Version: "v1", Resource: "secrets"},
+		&unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "Secret",
+			"metadata":   map[string]interface{}{"name": "credentials", "namespace": "test"},
+			"type":       "Opaque",
+			"data":       map[string]interface{}{"user": "YWRtaW4=", "password": "czNjcjN0"},
+		}},
+		"test",
+	))
+
+	drifts := resource.NewDriftService(kubernetes.NewDriftRepository(dynamic))
+
+	report, err := drifts.Drift("test", []string{secretManifest})
+	assert.Nil(t, err)
+	assert.False(t, report.Drifted)
+	assert.Equal(t, 1, report.Checked)
+
+	report, err = drifts.Drift("test", []string{strings.Replace(secretManifest, "s3cr3t", "changed", 1)})
+	assert.Nil(t, err)
+	assert.Equal(t, []resource.ObjectDrift{{
+		Kind:   "Secret",
+		Name:   "credentials",
+		Fields: []resource.FieldDrift{{Path: "data.password", Expected: "Y2hhbmdlZA==", Actual: "czNjcjN0"}},
+	}}, report.Objects)
+}
@@ -0,0 +1,104 @@
+package resource
+
+import (
+	"context"
+	"io"
+
+	"github.com/Meetic/blackbeard/pkg/errors"
+)
+
+// TerminalSize is the size of the terminal used by a command run with a TTY
+type TerminalSize struct {
+	Width  uint16
+	Height uint16
+}
+
+// ExecOptions describes a command to run in a container.
+// Target is a pod given as pod/NAME, or a workload given as deployment/NAME, statefulset/NAME or job/NAME.
+// When Container is empty, the first container of the pod is used.
+// Stdin is optional. Sizes sent to the Resize channel are applied to the TTY.
+type ExecOptions struct {
+	Target    string
+	Container string
+	Command   []string
+	TTY       bool
+	Stdin     io.Reader
+	Stdout    io.Writer
+	Stderr    io.Writer
+	Resize    <-chan TerminalSize
+}
+
+// ExecService runs commands inside the containers of a namespace
+type ExecService interface {
+	Exec(ctx context.Context, namespace string, opts ExecOptions) error
+}
+
+// ExecRepository defines the way commands are actually run
+type ExecRepository interface {
+	// ResolvePod returns a running and ready pod of the target
+	ResolvePod(namespace, target string) (string, error)
+	// Exec runs a command in a pod. A command exiting with a non zero code returns an error built using NewExitError.
+	Exec(ctx context.Context, namespace, pod string, opts ExecOptions) error
+}
+
+type execService struct {
+	execs      ExecRepository
+	namespaces NamespaceRepository
+}
+
+// NewExecService returns a new ExecService
+func NewExecService(execs ExecRepository, namespaces NamespaceRepository) ExecService {
+	return &execService{
+		execs:      execs,
+		namespaces: namespaces,
+	}
+}
+
+// Exec runs a command in a pod of the target.
+// Commands may only be run in namespaces managed by blackbeard.
+func (s *execService) Exec(ctx context.Context, namespace string, opts ExecOptions) error {
+	if len(opts.Command) == 0 {
+		return errors.New(errors.Invalid, "a command is required")
+	}
+
+	if opts.Target == "" {
+		return errors.New(errors.Invalid, "a pod or a workload is required")
+	}
+
+	ns, err := s.namespaces.Get(namespace)
+	if err != nil {
+		return err
+	}
+
+	if ns.Labels[LabelManager] != ManagerBlackbeard {
+		return errors.WithDetail(
+			errors.New(errors.Forbidden, "namespace %s is not managed by blackbeard, commands can not be run in it", namespace),
+			"namespace", namespace,
+		)
+	}
+
+	pod, err := s.execs.ResolvePod(namespace, opts.Target)
+	if err != nil {
+		return err
+	}
+
+	return s.execs.Exec(ctx, namespace, pod, opts)
+}
+
+// NewExitError returns the error of a command exiting with a non zero code
+func NewExitError(code int) error {
+	return errors.WithDetail(errors.New(errors.Upstream, "command terminated with exit code %d", code), "exitCode", code)
+}
+
+// ExitCode returns the exit code of a command if err has been built using NewExitError
+func ExitCode(err error) (int, bool) {
+	switch code := errors.DetailsOf(err)["exitCode"].(type) {
+	case int:
+		return code, true
+	case float64:
+		// decoded from a json problem
+		return int(code), true
+	}
+
+	return 0, false
+}
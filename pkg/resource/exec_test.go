@@ -0,0 +1,63 @@
+package resource_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/Meetic/blackbeard/pkg/errors"
+	"github.com/Meetic/blackbeard/pkg/kubernetes"
+	"github.com/Meetic/blackbeard/pkg/mock"
+	"github.com/Meetic/blackbeard/pkg/resource"
+)
+
+func newExecService() resource.ExecService {
+	kube := fake.NewSimpleClientset(
+		&v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "managed", Labels: map[string]string{"manager": "blackbeard"}}},
+		&v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "kube-system"}},
+	)
+
+	return resource.NewExecService(mock.NewExecRepository(), kubernetes.NewNamespaceRepository(kube))
+}
+
+func TestExec(t *testing.T) {
+	execs := newExecService()
+
+	var out bytes.Buffer
+	err := execs.Exec(context.Background(), "managed", resource.ExecOptions{Target: "deploy/api", Command: []string{"ls", "-l"}, Stdout: &out})
+	assert.Nil(t, err)
+	assert.Equal(t, "api: ls -l\n", out.String())
+
+	err = execs.Exec(context.Background(), "managed", resource.ExecOptions{Target: "deploy/api", Command: []string{"false"}, Stdout: &out})
+	code, ok := resource.ExitCode(err)
+	assert.True(t, ok)
+	assert.Equal(t, 1, code)
+}
+
+func TestExecErrors(t *testing.T) {
+	execs := newExecService()
+
+	tests := []struct {
+		namespace string
+		opts      resource.ExecOptions
+		kind      errors.Kind
+	}{
+		{"managed", resource.ExecOptions{Target: "deploy/api"}, errors.Invalid},
+		{"managed", resource.ExecOptions{Command: []string{"ls"}}, errors.Invalid},
+		{"kube-system", resource.ExecOptions{Target: "deploy/api", Command: []string{"ls"}}, errors.Forbidden},
+		{"unknown", resource.ExecOptions{Target: "deploy/api", Command: []string{"ls"}}, errors.NotFound},
+	}
+
+	for _, test := range tests {
+		err := execs.Exec(context.Background(), test.namespace, test.opts)
+		assert.True(t, errors.Is(err, test.kind), "%s %v: %v", test.namespace, test.opts, err)
+	}
+
+	_, ok := resource.ExitCode(errors.New(errors.Upstream, "unavailable"))
+	assert.False(t, ok)
+}
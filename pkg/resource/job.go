@@ -16,8 +16,9 @@ type jobService struct {
 type Jobs []Job
 
 type Job struct {
-	Name   string
-	Status JobStatus
+	Name        string
+	Status      JobStatus
+	Annotations map[string]string
 }
 
 type JobStatus string
@@ -25,6 +26,7 @@ type JobStatus string
 const (
 	JobReady    JobStatus = "Ready"
 	JobNotReady JobStatus = "NotReady"
+	JobFailed   JobStatus = "Failed"
 )
 
 func NewJobService(job JobRepository) JobService {
@@ -8,44 +8,115 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
+// Annotations set on namespaces to describe them.
+const (
+	AnnotationOwner       = "blackbeard.io/owner"
+	AnnotationTTL         = "blackbeard.io/ttl"
+	AnnotationPlaybook    = "blackbeard.io/playbook"
+	AnnotationLastApplied = "blackbeard.io/last-applied"
+)
+
+// NamespaceMetadata are the labels and annotations set on a namespace when it is created,
+// used for instance for cost attribution or by a service mesh to inject its sidecars.
+type NamespaceMetadata struct {
+	Labels      map[string]string `json:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
 type Namespace struct {
-	Name   string
-	Phase  string
-	Status int
+	Name        string
+	Phase       string
+	Status      int
+	Labels      map[string]string
+	Annotations map[string]string
 }
 
 // NamespaceService defined the way namespace are managed.
 type NamespaceService interface {
-	Create(namespace string) error
+	Create(namespace string, metadata NamespaceMetadata, profile *NamespaceProfile) error
+	ApplyProfile(namespace string, profile *NamespaceProfile) error
 	ApplyConfig(namespace string, configPath string) error
 	Delete(namespace string) error
 	GetStatus(namespace string) (*NamespaceStatus, error)
-	List() ([]Namespace, error)
+	List(selector string) ([]Namespace, error)
 	Watch(events chan NamespaceEvent)
+	Annotate(namespace string, annotations map[string]string) error
+	GetQuota(namespace string) ([]QuotaUsage, error)
+	Adopt(namespace string) error
+	Detach(namespace string) error
+	Get(namespace string) (*Namespace, error)
 }
 
 // NamespaceRepository defined the way namespace area actually managed.
+// Create sets the given metadata on the namespace, and attaches the quota, limit range and network policies
+// of the given profile to it, if any.
+// ApplyProfile creates or updates them, and removes the ones which are no longer part of the profile.
+// List only returns the namespaces matching the given label selector, if it is not empty.
+// Adopt labels an existing namespace as managed by blackbeard, and Detach removes this label.
 type NamespaceRepository interface {
-	Create(namespace string) error
+	Create(namespace string, metadata NamespaceMetadata, profile *NamespaceProfile) error
+	ApplyProfile(namespace string, profile *NamespaceProfile) error
 	Get(namespace string) (*Namespace, error)
 	ApplyConfig(namespace string, configPath string) error
 	Delete(namespace string) error
-	List() ([]Namespace, error)
+	List(selector string) ([]Namespace, error)
 	Watch(events chan<- NamespaceEvent) error
+	Annotate(namespace string, annotations map[string]string) error
+	GetQuota(namespace string) ([]QuotaUsage, error)
+	Adopt(namespace string) error
+	Detach(namespace string) error
 }
 
+// namespaceService evaluates the required rules, which fail the namespace status if they can not be evaluated,
+// then the additional rules, which are left out of the status if they can not be evaluated.
 type namespaceService struct {
-	namespaces   NamespaceRepository
-	pods         PodRepository
-	deployments  DeploymentRepository
-	statefulsets StatefulsetRepository
-	jobs         JobRepository
+	namespaces NamespaceRepository
+	pods       PodRepository
+	required   []ReadinessRule
+	rules      []ReadinessRule
 }
 
 // NamespaceStatus represent namespace with percentage of pods running and status phase (Active or Terminating)
+// Workloads are the workloads taken into account in the status, with their readiness.
+// Problems are the terminal conditions detected on the pods of the namespace.
 type NamespaceStatus struct {
-	Status int    `json:"status"`
-	Phase  string `json:"phase"`
+	Status    int                 `json:"status"`
+	Phase     string              `json:"phase"`
+	Workloads []WorkloadReadiness `json:"workloads,omitempty"`
+	Problems  []PodProblem        `json:"problems,omitempty"`
+}
+
+// Failed returns true if the namespace can not become ready without a change :
+// a workload failed or a pod has a terminal problem.
+func (s *NamespaceStatus) Failed() bool {
+	if len(s.Problems) > 0 {
+		return true
+	}
+
+	for _, w := range s.Workloads {
+		if w.Status == WorkloadFailed {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Blocking describes the workloads which are not ready and the problems of the pods of the namespace
+func (s *NamespaceStatus) Blocking() []string {
+	var blocking []string
+
+	for _, w := range s.Workloads {
+		if w.Status != WorkloadReady {
+			blocking = append(blocking, fmt.Sprintf("%s/%s: %s", w.Kind, w.Name, w.Status))
+		}
+	}
+
+	for _, p := range s.Problems {
+		blocking = append(blocking, p.String())
+	}
+
+	return blocking
 }
 
 type NamespaceEvent struct {
@@ -53,37 +124,77 @@ type NamespaceEvent struct {
 	Type      string
 }
 
+// NamespaceSynced is the type of the event published, without namespace, once the namespaces have been listed
+// to start watching them, when the watcher starts or when its resource version expired. The namespaces deleted
+// before were not notified, so consumers compare their state with the existing namespaces.
+const NamespaceSynced = "SYNCED"
+
 // NewNamespaceService creates a new NamespaceService
+// The namespace status is computed from the readiness of deployments, statefulsets and jobs,
+// and from the readiness of the workloads evaluated by the given additional rules.
 func NewNamespaceService(
 	namespaces NamespaceRepository,
 	pods PodRepository,
 	deployments DeploymentRepository,
 	statefulsets StatefulsetRepository,
 	jobs JobRepository,
+	rules ...ReadinessRule,
 ) NamespaceService {
 
 	ns := &namespaceService{
-		namespaces:   namespaces,
-		pods:         pods,
-		deployments:  deployments,
-		statefulsets: statefulsets,
-		jobs:         jobs,
+		namespaces: namespaces,
+		pods:       pods,
+		required: []ReadinessRule{
+			NewDeploymentRule(deployments),
+			NewStatefulsetRule(statefulsets),
+			NewJobRule(jobs),
+		},
+		rules: rules,
 	}
 
 	return ns
 }
 
-// Create creates a kubernetes namespace
-func (ns *namespaceService) Create(n string) error {
-	err := ns.namespaces.Create(n)
-
-	if err != nil {
-		return ErrorCreateNamespace{err.Error()}
+// Create creates a kubernetes namespace with the given labels and annotations,
+// limited by the given profile if it is not nil
+func (ns *namespaceService) Create(n string, metadata NamespaceMetadata, profile *NamespaceProfile) error {
+	if err := ns.namespaces.Create(n, metadata, profile); err != nil {
+		return fmt.Errorf("create namespace %s: %w", n, err)
 	}
 
 	return nil
 }
 
+// ApplyProfile updates the quota, limit range and network policies of a namespace to match the given profile.
+// Nothing is done if profile is nil.
+func (ns *namespaceService) ApplyProfile(namespace string, profile *NamespaceProfile) error {
+	if profile == nil {
+		return nil
+	}
+
+	return ns.namespaces.ApplyProfile(namespace, profile)
+}
+
+// GetQuota returns the usage of the resources limited by the quotas of a namespace
+func (ns *namespaceService) GetQuota(namespace string) ([]QuotaUsage, error) {
+	return ns.namespaces.GetQuota(namespace)
+}
+
+// Get returns a namespace, whether it is managed by blackbeard or not
+func (ns *namespaceService) Get(namespace string) (*Namespace, error) {
+	return ns.namespaces.Get(namespace)
+}
+
+// Adopt marks an existing namespace as managed by blackbeard
+func (ns *namespaceService) Adopt(namespace string) error {
+	return ns.namespaces.Adopt(namespace)
+}
+
+// Detach stops managing a namespace without deleting it nor its workloads
+func (ns *namespaceService) Detach(namespace string) error {
+	return ns.namespaces.Detach(namespace)
+}
+
 // ApplyConfig apply kubernetes configurations to the given namespace.
 // Warning : For now, this method takes a configPath as parameter. This parameter is the directory containing configs in a playbook
 // This may change since the NamespaceService should not be aware that configs are stored in files.
@@ -96,10 +207,16 @@ func (ns *namespaceService) Delete(namespace string) error {
 	return ns.namespaces.Delete(namespace)
 }
 
+// Annotate adds the given annotations to a namespace. Existing annotations are kept.
+func (ns *namespaceService) Annotate(namespace string, annotations map[string]string) error {
+	return ns.namespaces.Annotate(namespace, annotations)
+}
+
 // List returns a slice of namespace from the kubernetes package and enrich each of the
 // returned namespace with their status.
-func (ns *namespaceService) List() ([]Namespace, error) {
-	namespaces, err := ns.namespaces.List()
+// Only the namespaces matching the given label selector are returned, if it is not empty.
+func (ns *namespaceService) List(selector string) ([]Namespace, error) {
+	namespaces, err := ns.namespaces.List(selector)
 	if err != nil {
 		return nil, err
 	}
@@ -110,14 +227,16 @@ func (ns *namespaceService) List() ([]Namespace, error) {
 		wg.Add(1)
 
 		go func(index int) {
+			defer wg.Done()
+
 			status, err := ns.GetStatus(namespaces[index].Name)
 
 			if err != nil {
 				namespaces[index].Status = 0
+				return
 			}
 
 			namespaces[index].Status = status.Status
-			wg.Done()
 		}(i)
 	}
 
@@ -127,84 +246,118 @@ func (ns *namespaceService) List() ([]Namespace, error) {
 }
 
 // GetStatus returns the status of an inventory
-// The status is an int that represents the percentage of pods in a "running" state inside the given namespace
+// The status is an int that represents the weighted percentage of ready workloads inside the given namespace.
+// Workloads annotated with blackbeard.io/readiness: ignore are not taken into account.
+// The problems of the pods of the namespace are also returned, except for pods annotated to be ignored
+// and pods owned by an ignored workload.
+// Deployments, statefulsets and jobs must be listed. The other kinds and the pods are logged and left out of the
+// status when they can not be listed, for instance because of restricted permissions.
 func (ns *namespaceService) GetStatus(namespace string) (*NamespaceStatus, error) {
 
 	// get namespace state
 	n, err := ns.namespaces.Get(namespace)
 	if err != nil {
-		return nil, fmt.Errorf("namespace get status: %v", err)
+		return nil, fmt.Errorf("namespace get status: %w", err)
 	}
 
 	if n.Phase == "Terminating" {
-		return &NamespaceStatus{0, n.Phase}, nil
+		return &NamespaceStatus{Status: 0, Phase: n.Phase}, nil
 	}
 
-	dps, errDps := ns.deployments.List(namespace)
-	sfs, errSfs := ns.statefulsets.List(namespace)
-	jbs, errJbs := ns.jobs.List(namespace)
+	var (
+		workloads     []WorkloadReadiness
+		total, weight int
+		errs          []error
+	)
+
+	logger := logrus.WithFields(logrus.Fields{"component": "status", "namespace": namespace})
+	ignored := make(map[string]bool)
+
+	for i, rule := range append(append([]ReadinessRule{}, ns.required...), ns.rules...) {
+		ws, err := rule.Evaluate(namespace)
+		if err != nil && i < len(ns.required) {
+			errs = append(errs, err)
+			continue
+		}
+
+		if err != nil {
+			logger.Warnf("%s are left out of the namespace status : %v", rule.Kind(), err)
+			continue
+		}
+
+		for _, w := range ws {
+			wgt, ok := weightOf(w)
+			if !ok {
+				ignored[w.Kind+"/"+w.Name] = true
+				continue
+			}
+
+			w.Weight = wgt
+			total += wgt
+			if w.Status == WorkloadReady {
+				weight += wgt
+			}
 
-	if errDps != nil || errSfs != nil || errJbs != nil {
-		return &NamespaceStatus{0, ""}, fmt.Errorf("namespace get status: list deployments, statefulsets or jobs: %v", err)
+			workloads = append(workloads, w)
+		}
 	}
 
-	totalApps := len(dps) + len(sfs) + len(jbs)
+	pods, err := ns.pods.List(namespace)
+	if err != nil {
+		logger.Warnf("pod problems are left out of the namespace status : %v", err)
+	}
 
-	if totalApps == 0 {
-		return &NamespaceStatus{0, n.Phase}, nil
+	if len(errs) > 0 {
+		return &NamespaceStatus{Status: 0, Phase: ""}, fmt.Errorf("namespace get status: %w", errs[0])
 	}
 
-	var i int
+	status := &NamespaceStatus{Status: 0, Phase: n.Phase, Workloads: workloads}
 
-	for _, dp := range dps {
-		if dp.Status == DeploymentReady {
-			i++
+	for _, pod := range pods {
+		if pod.Annotations[AnnotationReadiness] == ReadinessIgnore || ownedByAny(pod, ignored) {
+			continue
 		}
+
+		status.Problems = append(status.Problems, pod.Problems...)
 	}
 
-	for _, sf := range sfs {
-		if sf.Status == StatefulsetReady {
-			i++
-		}
+	if total > 0 {
+		status.Status = weight * 100 / total
 	}
 
-	for _, job := range jbs {
-		if job.Status == JobReady {
-			i++
+	return status, nil
+}
+
+// ownedByAny returns true if one of the owners of a pod is in workloads
+func ownedByAny(pod Pod, workloads map[string]bool) bool {
+	for _, owner := range pod.Owners {
+		if workloads[owner] {
+			return true
 		}
 	}
 
-	status := i * 100 / totalApps
-
-	return &NamespaceStatus{status, n.Phase}, nil
+	return false
 }
 
+// Watch publishes the namespace events until the watch fails, then closes events.
+// The watch is restarted every 5 seconds once the connection is closed by the api server.
 func (ns *namespaceService) Watch(events chan NamespaceEvent) {
 	ticker := time.NewTicker(5 * time.Second)
+	defer ticker.Stop()
 	defer close(events)
 
-	for range ticker.C {
-		err := ns.namespaces.Watch(events)
-		if err != nil {
-			ticker.Stop()
+	for {
+		if err := ns.namespaces.Watch(events); err != nil {
+			logrus.
+				WithFields(logrus.Fields{"component": "watcher"}).
+				Errorf("watch namespace stopped due to error : %v", err)
+			return
 		}
 
+		<-ticker.C
+
 		logrus.
 			WithFields(logrus.Fields{"component": "watcher"}).
 			Debug("watch namespace restarted")
 	}
-
-	logrus.
-		WithFields(logrus.Fields{"component": "watcher"}).
-		Error("watch namespace stopped due to error")
-}
-
-// ErrorCreateNamespace represents an error due to a namespace creation failure on kubernetes cluster
-type ErrorCreateNamespace struct {
-	Msg string
-}
-
-// Error returns the error message
-func (err ErrorCreateNamespace) Error() string {
-	return err.Msg
 }
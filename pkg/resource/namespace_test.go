@@ -1,12 +1,20 @@
 package resource_test
 
 import (
-	"errors"
+	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
 
+	"github.com/Meetic/blackbeard/pkg/errors"
+	"github.com/Meetic/blackbeard/pkg/kubernetes"
 	"github.com/Meetic/blackbeard/pkg/mock"
 	"github.com/Meetic/blackbeard/pkg/resource"
 )
@@ -42,6 +50,10 @@ func TestGetStatusOk(t *testing.T) {
 		On("List", "test").
 		Return(resource.Jobs{{Name: "app", Status: resource.JobReady}}, nil)
 
+	podRepository.
+		On("List", "test").
+		Return(resource.Pods{{Name: "app-1", Status: "Running"}}, nil)
+
 	status, err := namespaces.GetStatus("test")
 
 	deploymentRepository.AssertExpectations(t)
@@ -59,12 +71,16 @@ func TestGetStatusIncomplete(t *testing.T) {
 
 	statefulsetRepository.
 		On("List", "testko").
-		Return(resource.Statefulsets{}, errors.New("some error"))
+		Return(resource.Statefulsets{}, fmt.Errorf("some error"))
 
 	jobRepository.
 		On("List", "testko").
 		Return(resource.Jobs{{Name: "app", Status: resource.JobReady}}, nil)
 
+	podRepository.
+		On("List", "testko").
+		Return(resource.Pods{}, nil)
+
 	status, err := namespaces.GetStatus("testko")
 
 	deploymentRepository.AssertExpectations(t)
@@ -76,7 +92,7 @@ func TestGetStatusIncomplete(t *testing.T) {
 }
 
 func TestNamespaceCreate(t *testing.T) {
-	err := namespaces.Create("mynamespace")
+	err := namespaces.Create("mynamespace", resource.NamespaceMetadata{}, nil)
 
 	assert.Nil(t, err)
 }
@@ -90,9 +106,10 @@ func TestNamespaceCreateError(t *testing.T) {
 		jobRepository,
 	)
 
-	err := namespaces.Create("foobar")
+	err := namespaces.Create("foobar", resource.NamespaceMetadata{}, nil)
 
-	assert.Equal(t, resource.ErrorCreateNamespace{Msg: "namespace foobar already exist"}, err)
+	assert.True(t, errors.Is(err, errors.AlreadyExists))
+	assert.EqualError(t, err, "create namespace foobar: namespace foobar already exist")
 }
 
 func TestDelete(t *testing.T) {
@@ -108,7 +125,7 @@ func TestApplyConfig(t *testing.T) {
 }
 
 func TestList(t *testing.T) {
-	namespaces, err := namespaces.List()
+	namespaces, err := namespaces.List("")
 
 	expectedNamespaces := []resource.Namespace{
 		{
@@ -121,3 +138,125 @@ func TestList(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, expectedNamespaces, namespaces)
 }
+
+func TestNamespaceCreateWithMetadata(t *testing.T) {
+	kube := fake.NewSimpleClientset()
+	namespaces := kubernetes.NewNamespaceRepository(kube)
+
+	err := namespaces.Create("dating", resource.NamespaceMetadata{
+		Labels:      map[string]string{"team": "dating", resource.LabelManager: "someone"},
+		Annotations: map[string]string{resource.AnnotationOwner: "john"},
+	}, nil)
+	assert.Nil(t, err)
+	assert.Nil(t, namespaces.Create("payment", resource.NamespaceMetadata{Labels: map[string]string{"team": "payment"}}, nil))
+
+	ns, err := namespaces.Get("dating")
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"team": "dating", resource.LabelManager: resource.ManagerBlackbeard}, ns.Labels)
+	assert.Equal(t, map[string]string{resource.AnnotationOwner: "john"}, ns.Annotations)
+
+	list, err := namespaces.List("team=dating")
+	assert.Nil(t, err)
+	assert.Len(t, list, 1)
+	assert.Equal(t, "dating", list[0].Name)
+
+	list, err = namespaces.List("")
+	assert.Nil(t, err)
+	assert.Len(t, list, 2)
+
+	_, err = namespaces.List("team in (dating")
+	assert.True(t, errors.Is(err, errors.Invalid))
+}
+
+func TestNamespaceCreateInvalidMetadata(t *testing.T) {
+	namespaces := kubernetes.NewNamespaceRepository(fake.NewSimpleClientset())
+
+	err := namespaces.Create("dating", resource.NamespaceMetadata{
+		Labels:      map[string]string{"branch": "feature/login"},
+		Annotations: map[string]string{"not valid": "value"},
+	}, nil)
+
+	assert.True(t, errors.Is(err, errors.Invalid))
+	assert.Len(t, errors.DetailsOf(err)["invalid"], 2)
+}
+
+func TestNamespaceAdoptAndDetach(t *testing.T) {
+	kube := fake.NewSimpleClientset(&v1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: "legacy", Labels: map[string]string{"team": "dating"}},
+	})
+	namespaces := kubernetes.NewNamespaceRepository(kube)
+
+	list, err := namespaces.List("")
+	assert.Nil(t, err)
+	assert.Empty(t, list)
+
+	assert.Nil(t, namespaces.Adopt("legacy"))
+
+	list, err = namespaces.List("team=dating")
+	assert.Nil(t, err)
+	assert.Len(t, list, 1)
+	assert.Equal(t, map[string]string{"team": "dating", resource.LabelManager: resource.ManagerBlackbeard}, list[0].Labels)
+
+	assert.Nil(t, namespaces.Detach("legacy"))
+
+	ns, err := namespaces.Get("legacy")
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"team": "dating"}, ns.Labels)
+
+	assert.True(t, errors.Is(namespaces.Adopt("unknown"), errors.NotFound))
+}
+
+func TestNamespaceWatchResumes(t *testing.T) {
+	kube := fake.NewSimpleClientset()
+	namespaces := kubernetes.NewNamespaceRepository(kube)
+
+	var lists int
+	kube.PrependReactor("list", "namespaces", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		lists++
+		return true, &v1.NamespaceList{ListMeta: metav1.ListMeta{ResourceVersion: "10"}}, nil
+	})
+
+	var versions []string
+	watchers := make(chan *watch.FakeWatcher)
+	kube.PrependWatchReactor("namespaces", func(action k8stesting.Action) (bool, watch.Interface, error) {
+		versions = append(versions, action.(k8stesting.WatchActionImpl).WatchRestrictions.ResourceVersion)
+		w := watch.NewFakeWithChanSize(1, false)
+		watchers <- w
+		return true, w, nil
+	})
+
+	events := make(chan resource.NamespaceEvent, 10)
+
+	// run watches namespaces until the events sent by send are consumed
+	run := func(send func(w *watch.FakeWatcher)) error {
+		done := make(chan error)
+		go func() { done <- namespaces.Watch(events) }()
+
+		w := <-watchers
+		send(w)
+		w.Stop()
+
+		return <-done
+	}
+
+	err := run(func(w *watch.FakeWatcher) {
+		w.Delete(&v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "test", ResourceVersion: "12"}})
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, resource.NamespaceEvent{Type: resource.NamespaceSynced}, <-events)
+	assert.Equal(t, resource.NamespaceEvent{Namespace: "test", Type: "DELETED"}, <-events)
+
+	err = run(func(w *watch.FakeWatcher) {
+		w.Error(&kerrors.NewResourceExpired("too old resource version").ErrStatus)
+	})
+	assert.Nil(t, err)
+
+	assert.Nil(t, run(func(w *watch.FakeWatcher) {}))
+
+	// deletions may have been missed once the resource version expired
+	assert.Equal(t, resource.NamespaceEvent{Type: resource.NamespaceSynced}, <-events)
+	assert.Empty(t, events)
+
+	assert.Equal(t, []string{"10", "12", "10"}, versions)
+	assert.Equal(t, 2, lists)
+}
@@ -0,0 +1,137 @@
+package resource
+
+import (
+	"strings"
+	"time"
+
+	"github.com/Meetic/blackbeard/pkg/errors"
+)
+
+// Label set on the namespaces created by blackbeard
+const (
+	LabelManager      = "manager"
+	ManagerBlackbeard = "blackbeard"
+)
+
+// Kinds of the objects managed by the ObjectService
+const (
+	KindPods         = "pods"
+	KindDeployments  = "deployments"
+	KindStatefulsets = "statefulsets"
+	KindJobs         = "jobs"
+	KindCronjobs     = "cronjobs"
+	KindConfigmaps   = "configmaps"
+	KindSecrets      = "secrets"
+	KindPvcs         = "pvcs"
+)
+
+// kindAliases maps the accepted names of each kind to the kind
+var kindAliases = map[string]string{
+	"pod": KindPods, "pods": KindPods, "po": KindPods,
+	"deployment": KindDeployments, "deployments": KindDeployments, "deploy": KindDeployments,
+	"statefulset": KindStatefulsets, "statefulsets": KindStatefulsets, "sts": KindStatefulsets,
+	"job": KindJobs, "jobs": KindJobs,
+	"cronjob": KindCronjobs, "cronjobs": KindCronjobs, "cj": KindCronjobs,
+	"configmap": KindConfigmaps, "configmaps": KindConfigmaps, "cm": KindConfigmaps,
+	"secret": KindSecrets, "secrets": KindSecrets,
+	"pvc": KindPvcs, "pvcs": KindPvcs, "persistentvolumeclaim": KindPvcs, "persistentvolumeclaims": KindPvcs,
+}
+
+// Kinds returns the kinds of objects managed by the ObjectService
+func Kinds() []string {
+	return []string{KindPods, KindDeployments, KindStatefulsets, KindJobs, KindCronjobs, KindConfigmaps, KindSecrets, KindPvcs}
+}
+
+// NormalizeKind returns the kind matching a singular, plural or short kind name.
+func NormalizeKind(kind string) (string, error) {
+	k, ok := kindAliases[strings.ToLower(kind)]
+	if !ok {
+		return "", errors.WithDetail(
+			errors.New(errors.Invalid, "unsupported kind %s, use one of %s", kind, strings.Join(Kinds(), ", ")),
+			"kind", kind,
+		)
+	}
+
+	return k, nil
+}
+
+// Object is a kubernetes object of a namespace.
+// Status is a short description of the object state, depending on its kind. Secret values are never exposed.
+type Object struct {
+	Kind      string            `json:"kind"`
+	Name      string            `json:"name"`
+	Namespace string            `json:"namespace"`
+	Status    string            `json:"status"`
+	CreatedAt time.Time         `json:"createdAt"`
+	Labels    map[string]string `json:"labels,omitempty"`
+}
+
+// ObjectService lists, reads and deletes the objects of a namespace by kind
+type ObjectService interface {
+	List(namespace, kind string) ([]Object, error)
+	Get(namespace, kind, name string) (*Object, error)
+	Delete(namespace, kind, name string) error
+}
+
+// ObjectRepository defines the way objects are actually managed. Kinds are already normalized.
+type ObjectRepository interface {
+	List(namespace, kind string) ([]Object, error)
+	Get(namespace, kind, name string) (*Object, error)
+	Delete(namespace, kind, name string) error
+}
+
+type objectService struct {
+	objects    ObjectRepository
+	namespaces NamespaceRepository
+}
+
+// NewObjectService returns a new ObjectService
+func NewObjectService(objects ObjectRepository, namespaces NamespaceRepository) ObjectService {
+	return &objectService{
+		objects:    objects,
+		namespaces: namespaces,
+	}
+}
+
+// List returns the objects of the given kind in a namespace
+func (s *objectService) List(namespace, kind string) ([]Object, error) {
+	k, err := NormalizeKind(kind)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.objects.List(namespace, k)
+}
+
+// Get returns an object of a namespace
+func (s *objectService) Get(namespace, kind, name string) (*Object, error) {
+	k, err := NormalizeKind(kind)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.objects.Get(namespace, k, name)
+}
+
+// Delete deletes an object of a namespace.
+// Only objects of namespaces managed by blackbeard may be deleted.
+func (s *objectService) Delete(namespace, kind, name string) error {
+	k, err := NormalizeKind(kind)
+	if err != nil {
+		return err
+	}
+
+	ns, err := s.namespaces.Get(namespace)
+	if err != nil {
+		return err
+	}
+
+	if ns.Labels[LabelManager] != ManagerBlackbeard {
+		return errors.WithDetail(
+			errors.New(errors.Forbidden, "namespace %s is not managed by blackbeard, its objects can not be deleted", namespace),
+			"namespace", namespace,
+		)
+	}
+
+	return s.objects.Delete(namespace, k, name)
+}
@@ -0,0 +1,63 @@
+package resource_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/Meetic/blackbeard/pkg/errors"
+	"github.com/Meetic/blackbeard/pkg/kubernetes"
+	"github.com/Meetic/blackbeard/pkg/resource"
+)
+
+func newObjectService() resource.ObjectService {
+	kube := fake.NewSimpleClientset(
+		&v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "managed", Labels: map[string]string{"manager": "blackbeard"}}},
+		&v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "kube-system"}},
+		&batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: "migration", Namespace: "managed"}, Status: batchv1.JobStatus{Succeeded: 1}},
+		&batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: "backup", Namespace: "kube-system"}},
+		&v1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "managed"}, Type: v1.SecretTypeOpaque, Data: map[string][]byte{"password": []byte("secret")}},
+	)
+
+	return resource.NewObjectService(kubernetes.NewObjectRepository(kube), kubernetes.NewNamespaceRepository(kube))
+}
+
+func TestObjectList(t *testing.T) {
+	objects := newObjectService()
+
+	jobs, err := objects.List("managed", "job")
+	assert.Nil(t, err)
+	assert.Len(t, jobs, 1)
+	assert.Equal(t, "jobs", jobs[0].Kind)
+	assert.Equal(t, "migration", jobs[0].Name)
+	assert.Equal(t, "1 succeeded, 0 failed", jobs[0].Status)
+
+	secret, err := objects.Get("managed", "secret", "db")
+	assert.Nil(t, err)
+	assert.Equal(t, "Opaque, 1 keys", secret.Status)
+	assert.NotContains(t, secret.Status, "secret")
+
+	_, err = objects.List("managed", "ingresses")
+	assert.True(t, errors.Is(err, errors.Invalid))
+
+	_, err = objects.Get("managed", "jobs", "unknown")
+	assert.True(t, errors.Is(err, errors.NotFound))
+}
+
+func TestObjectDelete(t *testing.T) {
+	objects := newObjectService()
+
+	assert.Nil(t, objects.Delete("managed", "jobs", "migration"))
+	_, err := objects.Get("managed", "jobs", "migration")
+	assert.True(t, errors.Is(err, errors.NotFound))
+
+	err = objects.Delete("kube-system", "jobs", "backup")
+	assert.True(t, errors.Is(err, errors.Forbidden))
+
+	err = objects.Delete("unknown", "jobs", "backup")
+	assert.True(t, errors.Is(err, errors.NotFound))
+}
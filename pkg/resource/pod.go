@@ -1,6 +1,9 @@
 package resource
 
 import (
+	"context"
+	"time"
+
 	"k8s.io/api/core/v1"
 )
 
@@ -12,9 +15,56 @@ type Pods []Pod
 // * running
 // * pending
 // etc...
+// Annotations are the pod annotations and Problems the terminal conditions detected on the pod.
+// Owners are the workloads managing the pod as kind/name, using the kinds of the readiness rules
+// (ex: deployments/api, or jobs/backup-28 then cronjobs/backup for the pod of a cronjob run).
 type Pod struct {
-	Name   string
-	Status v1.PodPhase
+	Name        string
+	Status      v1.PodPhase
+	Annotations map[string]string
+	Owners      []string
+	Problems    []PodProblem
+}
+
+// PodProblem is a condition preventing a pod from becoming ready until its configuration changes,
+// such as an image which can not be pulled, a container crashing in loop or a pod which can not be scheduled.
+// Reason is the kubernetes reason of the condition (ex: ImagePullBackOff).
+type PodProblem struct {
+	Pod       string `json:"pod"`
+	Container string `json:"container,omitempty"`
+	Reason    string `json:"reason"`
+	Message   string `json:"message,omitempty"`
+}
+
+func (p PodProblem) String() string {
+	s := "pod " + p.Pod
+	if p.Container != "" {
+		s += " (" + p.Container + ")"
+	}
+
+	s += ": " + p.Reason
+	if p.Message != "" {
+		s += ": " + p.Message
+	}
+
+	return s
+}
+
+// LogOptions selects the logs to read from a namespace.
+// Workload is the name of a deployment, statefulset or job, optionally prefixed by its kind (ex: "job/migration").
+// When empty, logs of every pod of the namespace are read.
+type LogOptions struct {
+	Workload  string
+	Container string
+	Follow    bool
+	Since     time.Duration
+}
+
+// LogLine is a line of log written by a pod container.
+type LogLine struct {
+	Pod       string `json:"pod"`
+	Container string `json:"container"`
+	Message   string `json:"message"`
 }
 
 type podService struct {
@@ -24,10 +74,12 @@ type podService struct {
 // PodRepository represents the way Pods are managed
 type PodRepository interface {
 	List(string) (Pods, error)
+	Logs(ctx context.Context, namespace string, opts LogOptions, lines chan<- LogLine) error
 }
 
 type PodService interface {
 	List(string) (Pods, error)
+	Logs(ctx context.Context, namespace string, opts LogOptions, lines chan<- LogLine) error
 }
 
 // NewPodService returns a new PodService
@@ -41,3 +93,10 @@ func NewPodService(pods PodRepository) PodService {
 func (ps *podService) List(namespace string) (Pods, error) {
 	return ps.pods.List(namespace)
 }
+
+// Logs sends the log lines of the pods selected by opts to the lines channel.
+// It returns once all logs are read, or when ctx is done if logs are followed.
+// The lines channel is not closed.
+func (ps *podService) Logs(ctx context.Context, namespace string, opts LogOptions, lines chan<- LogLine) error {
+	return ps.pods.Logs(ctx, namespace, opts, lines)
+}
@@ -0,0 +1,104 @@
+package resource
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	reconnectMinWait = time.Second
+	reconnectMaxWait = 30 * time.Second
+)
+
+// PortForward forwards a local port to a port of a service.
+// A LocalPort of 0 lets the system choose a free port.
+type PortForward struct {
+	Namespace  string
+	Service    string
+	LocalPort  int
+	RemotePort int
+}
+
+// PortForwardService forwards local ports to the pods backing a service
+type PortForwardService interface {
+	Forward(ctx context.Context, pf PortForward, ready func(localPort int)) error
+}
+
+// PortForwardRepository defines the way ports are actually forwarded
+type PortForwardRepository interface {
+	// ResolvePod returns a ready pod backing the service and declaring the pod port targeted by the service port
+	ResolvePod(namespace, service string, port int) (pod string, podPort int, err error)
+	// Forward forwards the local port to the pod port until the connection is lost or ctx is done.
+	// ready is called with the actual local port once the forwarding is established, before Forward returns.
+	Forward(ctx context.Context, namespace, pod string, localPort, podPort int, ready func(localPort int)) error
+}
+
+type portForwardService struct {
+	forwards PortForwardRepository
+}
+
+// NewPortForwardService returns a new PortForwardService
+func NewPortForwardService(forwards PortForwardRepository) PortForwardService {
+	return &portForwardService{
+		forwards: forwards,
+	}
+}
+
+// Forward forwards a local port to a service until ctx is done.
+// When the connection to the pod is lost, for instance because the pod restarted, another ready pod is resolved
+// and the same local port is forwarded again. ready is only called for the first connection.
+// An error is returned if no ready pod can be found for the first connection.
+func (s *portForwardService) Forward(ctx context.Context, pf PortForward, ready func(localPort int)) error {
+	wait := reconnectMinWait
+	connected := false
+	var first sync.Once
+
+	for {
+		pod, podPort, err := s.forwards.ResolvePod(pf.Namespace, pf.Service, pf.RemotePort)
+		if err != nil && !connected {
+			return err
+		}
+
+		if err == nil {
+			// the repository calls back from the forwarding goroutine, so the local port is sent back over a channel
+			established := make(chan int, 1)
+
+			err = s.forwards.Forward(ctx, pf.Namespace, pod, pf.LocalPort, podPort, func(localPort int) {
+				established <- localPort
+				first.Do(func() { ready(localPort) })
+			})
+
+			select {
+			case localPort := <-established:
+				wait, connected, pf.LocalPort = reconnectMinWait, true, localPort
+			default:
+			}
+
+			if err != nil && !connected {
+				return err
+			}
+		}
+
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		logrus.WithFields(logrus.Fields{
+			"namespace": pf.Namespace,
+			"service":   pf.Service,
+		}).Warnf("port forwarding interrupted, reconnecting in %s : %v", wait, err)
+
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return nil
+		}
+
+		if wait *= 2; wait > reconnectMaxWait {
+			wait = reconnectMaxWait
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package resource_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	testifymock "github.com/stretchr/testify/mock"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/Meetic/blackbeard/pkg/errors"
+	"github.com/Meetic/blackbeard/pkg/kubernetes"
+	"github.com/Meetic/blackbeard/pkg/mock"
+	"github.com/Meetic/blackbeard/pkg/resource"
+)
+
+func TestForwardReconnects(t *testing.T) {
+	forwards := new(mock.PortForwardRepository)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	forwards.On("ResolvePod", "test", "api", 80).Return("api-1", 8080, nil).Once()
+	forwards.On("Forward", "test", "api-1", 0, 8080).Return(4242, fmt.Errorf("lost connection to pod")).Once()
+	forwards.On("ResolvePod", "test", "api", 80).Return("api-2", 8080, nil).Once()
+	forwards.On("Forward", "test", "api-2", 4242, 8080).Return(4242, nil).Run(func(testifymock.Arguments) { cancel() }).Once()
+
+	var readyPorts []int
+	err := resource.NewPortForwardService(forwards).Forward(
+		ctx,
+		resource.PortForward{Namespace: "test", Service: "api", RemotePort: 80},
+		func(localPort int) { readyPorts = append(readyPorts, localPort) },
+	)
+
+	assert.Nil(t, err)
+	assert.Equal(t, []int{4242}, readyPorts)
+	forwards.AssertExpectations(t)
+}
+
+func TestForwardWithoutReadyPod(t *testing.T) {
+	forwards := new(mock.PortForwardRepository)
+	forwards.On("ResolvePod", "test", "api", 80).Return("", 0, errors.New(errors.NotFound, "no ready pod"))
+
+	err := resource.NewPortForwardService(forwards).Forward(
+		context.Background(),
+		resource.PortForward{Namespace: "test", Service: "api", RemotePort: 80},
+		func(int) {},
+	)
+
+	assert.True(t, errors.Is(err, errors.NotFound))
+}
+
+func TestResolvePod(t *testing.T) {
+	selector := map[string]string{"app": "api"}
+	ready := v1.PodStatus{Phase: v1.PodRunning, Conditions: []v1.PodCondition{{Type: v1.PodReady, Status: v1.ConditionTrue}}}
+
+	kube := fake.NewSimpleClientset(
+		&v1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "test"},
+			Spec: v1.ServiceSpec{
+				ClusterIP: v1.ClusterIPNone,
+				Selector:  selector,
+				Ports: []v1.ServicePort{
+					{Port: 50051, TargetPort: intstr.FromString("grpc")},
+					{Port: 80, TargetPort: intstr.FromString("http")},
+				},
+			},
+		},
+		&v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "api-starting", Namespace: "test", Labels: selector},
+			Status:     v1.PodStatus{Phase: v1.PodPending},
+		},
+		&v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "api-old", Namespace: "test", Labels: selector},
+			Spec:       v1.PodSpec{Containers: []v1.Container{{Name: "api"}}},
+			Status:     ready,
+		},
+		&v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "api-ready", Namespace: "test", Labels: selector},
+			Spec:       v1.PodSpec{Containers: []v1.Container{{Name: "api", Ports: []v1.ContainerPort{{Name: "grpc", ContainerPort: 9090}}}}},
+			Status:     ready,
+		},
+	)
+
+	forwards := kubernetes.NewPortForwardRepository(kube, nil)
+
+	pod, port, err := forwards.ResolvePod("test", "api", 50051)
+	assert.Nil(t, err)
+	assert.Equal(t, "api-ready", pod)
+	assert.Equal(t, 9090, port)
+
+	// no ready pod declares the http port
+	_, _, err = forwards.ResolvePod("test", "api", 80)
+	assert.True(t, errors.Is(err, errors.Invalid))
+
+	_, _, err = forwards.ResolvePod("test", "api", 81)
+	assert.True(t, errors.Is(err, errors.Invalid))
+
+	_, _, err = forwards.ResolvePod("test", "unknown", 80)
+	assert.True(t, errors.Is(err, errors.NotFound))
+}
@@ -0,0 +1,48 @@
+package resource
+
+// LabelProfile is set on the objects created from a namespace profile
+const LabelProfile = "blackbeard.io/profile"
+
+// NamespaceProfile is a size profile of a namespace, declared in the namespace.yaml file of a playbook.
+// Quota is the hard limits of the namespace resource quota, ex: {"requests.cpu": "2", "pods": "20"}.
+// Limits are the default and allowed resources of the containers of the namespace.
+// NetworkPolicies are created in the namespace along with the quota.
+type NamespaceProfile struct {
+	Name            string            `json:"name"`
+	Quota           map[string]string `json:"quota,omitempty"`
+	Limits          *ContainerLimits  `json:"limits,omitempty"`
+	NetworkPolicies []NetworkPolicy   `json:"networkPolicies,omitempty"`
+}
+
+// ContainerLimits are the resources of a limit range applied to containers.
+// Each field maps a resource name to a quantity, ex: {"cpu": "500m", "memory": "512Mi"}.
+type ContainerLimits struct {
+	Default        map[string]string `json:"default,omitempty"`
+	DefaultRequest map[string]string `json:"defaultRequest,omitempty"`
+	Max            map[string]string `json:"max,omitempty"`
+	Min            map[string]string `json:"min,omitempty"`
+}
+
+// NetworkPolicy is a network policy of a profile. Spec is a kubernetes NetworkPolicySpec.
+type NetworkPolicy struct {
+	Name string                 `json:"name"`
+	Spec map[string]interface{} `json:"spec"`
+}
+
+// ProfileRepository reads the namespace profiles of a playbook
+type ProfileRepository interface {
+	// Get returns the profile with the given name, or the default profile if name is empty.
+	// It returns nil if name is empty and the playbook has no default profile.
+	Get(name string) (*NamespaceProfile, error)
+	// Metadata returns the labels and annotations set on every namespace created using the playbook
+	Metadata() (NamespaceMetadata, error)
+}
+
+// QuotaUsage is the usage of a resource limited by a resource quota of a namespace
+type QuotaUsage struct {
+	Quota    string `json:"quota"`
+	Resource string `json:"resource"`
+	Hard     string `json:"hard"`
+	Used     string `json:"used"`
+	Percent  int    `json:"percent"`
+}
@@ -0,0 +1,124 @@
+package resource_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	kresource "k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/Meetic/blackbeard/pkg/errors"
+	"github.com/Meetic/blackbeard/pkg/kubernetes"
+	"github.com/Meetic/blackbeard/pkg/resource"
+)
+
+func TestCreateWithProfile(t *testing.T) {
+	kube := fake.NewSimpleClientset()
+	namespaces := resource.NewNamespaceService(
+		kubernetes.NewNamespaceRepository(kube),
+		kubernetes.NewPodRepository(kube),
+		kubernetes.NewDeploymentRepository(kube),
+		kubernetes.NewStatefulsetRepository(kube),
+		kubernetes.NewJobRepository(kube),
+	)
+
+	err := namespaces.Create("test", resource.NamespaceMetadata{}, &resource.NamespaceProfile{
+		Name:   "small",
+		Quota:  map[string]string{"pods": "20", "requests.memory": "4Gi"},
+		Limits: &resource.ContainerLimits{Default: map[string]string{"cpu": "500m"}},
+		NetworkPolicies: []resource.NetworkPolicy{
+			{Name: "same-namespace", Spec: map[string]interface{}{"podSelector": map[string]interface{}{}}},
+		},
+	})
+	assert.Nil(t, err)
+
+	quota, err := kube.CoreV1().ResourceQuotas("test").Get(context.Background(), "blackbeard", metav1.GetOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, "small", quota.Labels[resource.LabelProfile])
+	assert.Equal(t, kresource.MustParse("20"), quota.Spec.Hard[v1.ResourcePods])
+
+	limits, err := kube.CoreV1().LimitRanges("test").Get(context.Background(), "blackbeard", metav1.GetOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, kresource.MustParse("500m"), limits.Spec.Limits[0].Default[v1.ResourceCPU])
+
+	_, err = kube.NetworkingV1().NetworkPolicies("test").Get(context.Background(), "same-namespace", metav1.GetOptions{})
+	assert.Nil(t, err)
+
+	usages, err := namespaces.GetQuota("test")
+	assert.Nil(t, err)
+	assert.Equal(t, []resource.QuotaUsage{
+		{Quota: "blackbeard", Resource: "pods", Hard: "20", Used: "0"},
+		{Quota: "blackbeard", Resource: "requests.memory", Hard: "4Gi", Used: "0"},
+	}, usages)
+
+	// a profile without limits nor policies removes them
+	assert.Nil(t, namespaces.ApplyProfile("test", &resource.NamespaceProfile{Name: "large", Quota: map[string]string{"pods": "100"}}))
+
+	quota, err = kube.CoreV1().ResourceQuotas("test").Get(context.Background(), "blackbeard", metav1.GetOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, "large", quota.Labels[resource.LabelProfile])
+
+	_, err = kube.CoreV1().LimitRanges("test").Get(context.Background(), "blackbeard", metav1.GetOptions{})
+	assert.NotNil(t, err)
+
+	policies, err := kube.NetworkingV1().NetworkPolicies("test").List(context.Background(), metav1.ListOptions{})
+	assert.Nil(t, err)
+	assert.Empty(t, policies.Items)
+}
+
+func TestApplyProfileStalePolicyDeleted(t *testing.T) {
+	kube := fake.NewSimpleClientset()
+	namespaces := kubernetes.NewNamespaceRepository(kube)
+
+	assert.Nil(t, namespaces.Create("test", resource.NamespaceMetadata{}, &resource.NamespaceProfile{
+		Name: "small",
+		NetworkPolicies: []resource.NetworkPolicy{
+			{Name: "a-deleted-meanwhile", Spec: map[string]interface{}{"podSelector": map[string]interface{}{}}},
+			{Name: "b-stale", Spec: map[string]interface{}{"podSelector": map[string]interface{}{}}},
+		},
+	}))
+
+	// the first stale policy is deleted by someone else between the list and the delete
+	kube.PrependReactor("delete", "networkpolicies", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if name := action.(k8stesting.DeleteAction).GetName(); name == "a-deleted-meanwhile" {
+			return true, nil, apierrors.NewNotFound(schema.GroupResource{Resource: "networkpolicies"}, name)
+		}
+		return false, nil, nil
+	})
+
+	assert.Nil(t, namespaces.ApplyProfile("test", &resource.NamespaceProfile{Name: "large"}))
+
+	_, err := kube.NetworkingV1().NetworkPolicies("test").Get(context.Background(), "b-stale", metav1.GetOptions{})
+	assert.True(t, apierrors.IsNotFound(err))
+}
+
+func TestGetQuotaUsage(t *testing.T) {
+	kube := fake.NewSimpleClientset(&v1.ResourceQuota{
+		ObjectMeta: metav1.ObjectMeta{Name: "compute", Namespace: "test"},
+		Status: v1.ResourceQuotaStatus{
+			Hard: v1.ResourceList{v1.ResourceRequestsCPU: kresource.MustParse("2"), v1.ResourcePods: kresource.MustParse("20")},
+			Used: v1.ResourceList{v1.ResourceRequestsCPU: kresource.MustParse("500m"), v1.ResourcePods: kresource.MustParse("15")},
+		},
+	})
+
+	usages, err := kubernetes.NewNamespaceRepository(kube).GetQuota("test")
+	assert.Nil(t, err)
+	assert.Equal(t, []resource.QuotaUsage{
+		{Quota: "compute", Resource: "pods", Hard: "20", Used: "15", Percent: 75},
+		{Quota: "compute", Resource: "requests.cpu", Hard: "2", Used: "500m", Percent: 25},
+	}, usages)
+}
+
+func TestApplyProfileInvalidQuantity(t *testing.T) {
+	namespaces := kubernetes.NewNamespaceRepository(fake.NewSimpleClientset())
+
+	err := namespaces.ApplyProfile("test", &resource.NamespaceProfile{Name: "small", Quota: map[string]string{"pods": "many"}})
+	assert.True(t, errors.Is(err, errors.Invalid))
+}
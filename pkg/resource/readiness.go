@@ -0,0 +1,153 @@
+package resource
+
+import (
+	"strconv"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Annotations changing how a workload is taken into account in the namespace status.
+// A workload annotated with blackbeard.io/readiness: ignore is excluded from the status,
+// which is useful for workloads allowed to fail.
+// blackbeard.io/weight is an integer of at least 1 giving more importance to critical workloads. The default weight is 1.
+const (
+	AnnotationReadiness = "blackbeard.io/readiness"
+	AnnotationWeight    = "blackbeard.io/weight"
+	ReadinessIgnore     = "ignore"
+)
+
+// WorkloadStatus is the readiness of a workload
+type WorkloadStatus string
+
+const (
+	WorkloadReady    WorkloadStatus = "Ready"
+	WorkloadNotReady WorkloadStatus = "NotReady"
+	WorkloadFailed   WorkloadStatus = "Failed"
+)
+
+// WorkloadReadiness is the readiness of a workload taken into account in the namespace status.
+// Annotations are the workload annotations, used to read its weight or to ignore it.
+type WorkloadReadiness struct {
+	Kind        string            `json:"kind"`
+	Name        string            `json:"name"`
+	Status      WorkloadStatus    `json:"status"`
+	Weight      int               `json:"weight"`
+	Annotations map[string]string `json:"-"`
+}
+
+// ReadinessRule evaluates the readiness of the workloads of a kind in a namespace.
+// Rules are given to the NamespaceService to compute the namespace status.
+type ReadinessRule interface {
+	Kind() string
+	Evaluate(namespace string) ([]WorkloadReadiness, error)
+}
+
+// weightOf returns the weight of a workload, or false if the workload must be ignored
+func weightOf(w WorkloadReadiness) (int, bool) {
+	if w.Annotations[AnnotationReadiness] == ReadinessIgnore {
+		return 0, false
+	}
+
+	value, ok := w.Annotations[AnnotationWeight]
+	if !ok {
+		return 1, true
+	}
+
+	weight, err := strconv.Atoi(value)
+	if err != nil || weight < 1 {
+		logrus.Warnf("invalid %s annotation %q on %s %s, using 1", AnnotationWeight, value, w.Kind, w.Name)
+		return 1, true
+	}
+
+	return weight, true
+}
+
+type deploymentRule struct {
+	deployments DeploymentRepository
+}
+
+// NewDeploymentRule returns a ReadinessRule evaluating deployments
+func NewDeploymentRule(deployments DeploymentRepository) ReadinessRule {
+	return &deploymentRule{deployments: deployments}
+}
+
+func (r *deploymentRule) Kind() string { return KindDeployments }
+
+func (r *deploymentRule) Evaluate(namespace string) ([]WorkloadReadiness, error) {
+	dps, err := r.deployments.List(namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	var workloads []WorkloadReadiness
+	for _, dp := range dps {
+		workloads = append(workloads, WorkloadReadiness{
+			Kind:        KindDeployments,
+			Name:        dp.Name,
+			Status:      WorkloadStatus(dp.Status),
+			Annotations: dp.Annotations,
+		})
+	}
+
+	return workloads, nil
+}
+
+type statefulsetRule struct {
+	statefulsets StatefulsetRepository
+}
+
+// NewStatefulsetRule returns a ReadinessRule evaluating statefulsets
+func NewStatefulsetRule(statefulsets StatefulsetRepository) ReadinessRule {
+	return &statefulsetRule{statefulsets: statefulsets}
+}
+
+func (r *statefulsetRule) Kind() string { return KindStatefulsets }
+
+func (r *statefulsetRule) Evaluate(namespace string) ([]WorkloadReadiness, error) {
+	sfs, err := r.statefulsets.List(namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	var workloads []WorkloadReadiness
+	for _, sf := range sfs {
+		workloads = append(workloads, WorkloadReadiness{
+			Kind:        KindStatefulsets,
+			Name:        sf.Name,
+			Status:      WorkloadStatus(sf.Status),
+			Annotations: sf.Annotations,
+		})
+	}
+
+	return workloads, nil
+}
+
+type jobRule struct {
+	jobs JobRepository
+}
+
+// NewJobRule returns a ReadinessRule evaluating jobs. A completed job is ready.
+func NewJobRule(jobs JobRepository) ReadinessRule {
+	return &jobRule{jobs: jobs}
+}
+
+func (r *jobRule) Kind() string { return KindJobs }
+
+func (r *jobRule) Evaluate(namespace string) ([]WorkloadReadiness, error) {
+	jbs, err := r.jobs.List(namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	var workloads []WorkloadReadiness
+	for _, job := range jbs {
+		workloads = append(workloads, WorkloadReadiness{
+			Kind:        KindJobs,
+			Name:        job.Name,
+			Status:      WorkloadStatus(job.Status),
+			Annotations: job.Annotations,
+		})
+	}
+
+	return workloads, nil
+}
@@ -0,0 +1,283 @@
+package resource_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/Meetic/blackbeard/pkg/kubernetes"
+	"github.com/Meetic/blackbeard/pkg/resource"
+)
+
+func newReadinessNamespaceService(objects ...runtime.Object) resource.NamespaceService {
+	return newReadinessNamespaceServiceFor(newReadinessClientset(objects...))
+}
+
+func newReadinessClientset(objects ...runtime.Object) *fake.Clientset {
+	objects = append(objects, &v1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: "test"},
+		Status:     v1.NamespaceStatus{Phase: v1.NamespaceActive},
+	})
+
+	return fake.NewSimpleClientset(objects...)
+}
+
+func newReadinessNamespaceServiceFor(kube *fake.Clientset) resource.NamespaceService {
+	return resource.NewNamespaceService(
+		kubernetes.NewNamespaceRepository(kube),
+		kubernetes.NewPodRepository(kube),
+		kubernetes.NewDeploymentRepository(kube),
+		kubernetes.NewStatefulsetRepository(kube),
+		kubernetes.NewJobRepository(kube),
+		kubernetes.NewReadinessRules(kube)...,
+	)
+}
+
+func meta(name string, annotations map[string]string) metav1.ObjectMeta {
+	return metav1.ObjectMeta{Name: name, Namespace: "test", Annotations: annotations}
+}
+
+func condition(t batchv1.JobConditionType) batchv1.JobStatus {
+	return batchv1.JobStatus{Conditions: []batchv1.JobCondition{{Type: t, Status: v1.ConditionTrue}}}
+}
+
+func TestGetStatusReadinessRules(t *testing.T) {
+	now := time.Now()
+
+	namespaces := newReadinessNamespaceService(
+		&appsv1.Deployment{
+			ObjectMeta: meta("api", map[string]string{resource.AnnotationWeight: "3"}),
+			Status:     appsv1.DeploymentStatus{Replicas: 2, UpdatedReplicas: 2, ReadyReplicas: 2},
+		},
+		&appsv1.Deployment{
+			ObjectMeta: meta("worker", nil),
+			Status:     appsv1.DeploymentStatus{Replicas: 1, ReadyReplicas: 0},
+		},
+		&batchv1.Job{ObjectMeta: meta("migration", nil), Status: condition(batchv1.JobFailed)},
+		&batchv1.Job{ObjectMeta: meta("fixtures", nil), Status: batchv1.JobStatus{Active: 1}},
+		&batchv1.Job{ObjectMeta: meta("flaky", map[string]string{resource.AnnotationReadiness: resource.ReadinessIgnore}), Status: condition(batchv1.JobFailed)},
+		&batchv1.Job{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            "backup-1",
+				Namespace:       "test",
+				OwnerReferences: []metav1.OwnerReference{{Kind: "CronJob", Name: "backup"}},
+			},
+			Status: condition(batchv1.JobFailed),
+		},
+		&batchv1.CronJob{
+			ObjectMeta: meta("backup", nil),
+			Status: batchv1.CronJobStatus{
+				LastScheduleTime:   &metav1.Time{Time: now},
+				LastSuccessfulTime: &metav1.Time{Time: now.Add(-time.Hour)},
+			},
+		},
+		&batchv1.CronJob{ObjectMeta: meta("report", nil)},
+		&appsv1.DaemonSet{
+			ObjectMeta: meta("agent", nil),
+			Status:     appsv1.DaemonSetStatus{DesiredNumberScheduled: 3, NumberReady: 3},
+		},
+		&v1.PersistentVolumeClaim{ObjectMeta: meta("data", nil), Status: v1.PersistentVolumeClaimStatus{Phase: v1.ClaimBound}},
+		&v1.PersistentVolumeClaim{ObjectMeta: meta("cache", nil), Status: v1.PersistentVolumeClaimStatus{Phase: v1.ClaimPending}},
+	)
+
+	status, err := namespaces.GetStatus("test")
+	assert.Nil(t, err)
+
+	workloads := make(map[string]resource.WorkloadReadiness)
+	for _, w := range status.Workloads {
+		workloads[w.Kind+"/"+w.Name] = w
+	}
+
+	assert.Len(t, workloads, 9)
+	assert.Equal(t, resource.WorkloadReady, workloads["deployments/api"].Status)
+	assert.Equal(t, 3, workloads["deployments/api"].Weight)
+	assert.Equal(t, resource.WorkloadNotReady, workloads["deployments/worker"].Status)
+	assert.Equal(t, resource.WorkloadFailed, workloads["jobs/migration"].Status)
+	assert.Equal(t, resource.WorkloadNotReady, workloads["jobs/fixtures"].Status)
+	assert.NotContains(t, workloads, "jobs/flaky")
+	assert.NotContains(t, workloads, "jobs/backup-1")
+	assert.Equal(t, resource.WorkloadFailed, workloads["cronjobs/backup"].Status)
+	assert.Equal(t, resource.WorkloadReady, workloads["cronjobs/report"].Status)
+	assert.Equal(t, resource.WorkloadReady, workloads["daemonsets/agent"].Status)
+	assert.Equal(t, resource.WorkloadReady, workloads["pvcs/data"].Status)
+	assert.Equal(t, resource.WorkloadNotReady, workloads["pvcs/cache"].Status)
+
+	// ready weight : api 3, report 1, agent 1, data 1 out of a total weight of 11
+	assert.Equal(t, 54, status.Status)
+}
+
+func TestGetStatusInvalidWeight(t *testing.T) {
+	for _, weight := range []string{"high", "0", "-2"} {
+		namespaces := newReadinessNamespaceService(
+			&appsv1.Deployment{
+				ObjectMeta: meta("api", map[string]string{resource.AnnotationWeight: weight}),
+				Status:     appsv1.DeploymentStatus{Replicas: 1, UpdatedReplicas: 1, ReadyReplicas: 1},
+			},
+			&appsv1.Deployment{
+				ObjectMeta: meta("worker", map[string]string{resource.AnnotationWeight: weight}),
+				Status:     appsv1.DeploymentStatus{Replicas: 1, ReadyReplicas: 0},
+			},
+		)
+
+		status, err := namespaces.GetStatus("test")
+		assert.Nil(t, err, weight)
+		assert.Equal(t, 50, status.Status, weight)
+	}
+}
+
+func TestGetStatusRestrictedPermissions(t *testing.T) {
+	kube := newReadinessClientset(
+		&appsv1.Deployment{
+			ObjectMeta: meta("api", nil),
+			Status:     appsv1.DeploymentStatus{Replicas: 1, UpdatedReplicas: 1, ReadyReplicas: 1},
+		},
+	)
+
+	forbidden := func(resource string) {
+		kube.PrependReactor("list", resource, func(k8stesting.Action) (bool, runtime.Object, error) {
+			return true, nil, apierrors.NewForbidden(schema.GroupResource{Resource: resource}, "", nil)
+		})
+	}
+
+	forbidden("cronjobs")
+	forbidden("daemonsets")
+	forbidden("persistentvolumeclaims")
+	forbidden("pods")
+
+	status, err := newReadinessNamespaceServiceFor(kube).GetStatus("test")
+	assert.Nil(t, err)
+	assert.Equal(t, 100, status.Status)
+	assert.Len(t, status.Workloads, 1)
+	assert.Empty(t, status.Problems)
+
+	// deployments, statefulsets and jobs are required
+	forbidden("jobs")
+
+	_, err = newReadinessNamespaceServiceFor(kube).GetStatus("test")
+	assert.NotNil(t, err)
+}
+
+func TestGetStatusRollout(t *testing.T) {
+	namespaces := newReadinessNamespaceService(
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "test", Generation: 2},
+			Status:     appsv1.DeploymentStatus{ObservedGeneration: 1, Replicas: 1, UpdatedReplicas: 1, ReadyReplicas: 1},
+		},
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "front", Namespace: "test", Generation: 2},
+			Status:     appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 2, UpdatedReplicas: 1, ReadyReplicas: 2},
+		},
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "worker", Namespace: "test", Generation: 2},
+			Status:     appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 1, UpdatedReplicas: 1, ReadyReplicas: 1},
+		},
+		&appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "test", Generation: 3},
+			Status:     appsv1.StatefulSetStatus{ObservedGeneration: 3, Replicas: 2, UpdatedReplicas: 1, ReadyReplicas: 2},
+		},
+	)
+
+	status, err := namespaces.GetStatus("test")
+	assert.Nil(t, err)
+
+	workloads := make(map[string]resource.WorkloadReadiness)
+	for _, w := range status.Workloads {
+		workloads[w.Kind+"/"+w.Name] = w
+	}
+
+	// the restart of api is not observed yet and the old pods of front and db are still running
+	assert.Equal(t, resource.WorkloadNotReady, workloads["deployments/api"].Status)
+	assert.Equal(t, resource.WorkloadNotReady, workloads["deployments/front"].Status)
+	assert.Equal(t, resource.WorkloadReady, workloads["deployments/worker"].Status)
+	assert.Equal(t, resource.WorkloadNotReady, workloads["statefulsets/db"].Status)
+}
+
+func waiting(name, reason string, restarts int32) v1.ContainerStatus {
+	return v1.ContainerStatus{
+		Name:         name,
+		RestartCount: restarts,
+		State:        v1.ContainerState{Waiting: &v1.ContainerStateWaiting{Reason: reason}},
+	}
+}
+
+func TestGetStatusPodProblems(t *testing.T) {
+	unschedulable := func(since time.Duration) v1.PodStatus {
+		return v1.PodStatus{
+			Phase: v1.PodPending,
+			Conditions: []v1.PodCondition{{
+				Type:               v1.PodScheduled,
+				Status:             v1.ConditionFalse,
+				Reason:             v1.PodReasonUnschedulable,
+				Message:            "0/3 nodes are available",
+				LastTransitionTime: metav1.Time{Time: time.Now().Add(-since)},
+			}},
+		}
+	}
+
+	namespaces := newReadinessNamespaceService(
+		&v1.Pod{ObjectMeta: meta("api-1", nil), Status: v1.PodStatus{ContainerStatuses: []v1.ContainerStatus{waiting("php", "CrashLoopBackOff", 5)}}},
+		&v1.Pod{ObjectMeta: meta("api-2", nil), Status: v1.PodStatus{ContainerStatuses: []v1.ContainerStatus{waiting("php", "CrashLoopBackOff", 1)}}},
+		&v1.Pod{ObjectMeta: meta("front-1", nil), Status: v1.PodStatus{InitContainerStatuses: []v1.ContainerStatus{waiting("assets", "ImagePullBackOff", 0)}}},
+		&v1.Pod{ObjectMeta: meta("front-2", nil), Status: v1.PodStatus{ContainerStatuses: []v1.ContainerStatus{waiting("nginx", "ContainerCreating", 0)}}},
+		&v1.Pod{ObjectMeta: meta("db-1", nil), Status: unschedulable(5 * time.Minute)},
+		&v1.Pod{ObjectMeta: meta("db-2", nil), Status: unschedulable(time.Second)},
+		&v1.Pod{
+			ObjectMeta: meta("flaky-1", map[string]string{resource.AnnotationReadiness: resource.ReadinessIgnore}),
+			Status:     v1.PodStatus{ContainerStatuses: []v1.ContainerStatus{waiting("flaky", "ImagePullBackOff", 0)}},
+		},
+	)
+
+	status, err := namespaces.GetStatus("test")
+	assert.Nil(t, err)
+	assert.True(t, status.Failed())
+	assert.ElementsMatch(t, []resource.PodProblem{
+		{Pod: "api-1", Container: "php", Reason: "CrashLoopBackOff"},
+		{Pod: "front-1", Container: "assets", Reason: "ImagePullBackOff"},
+		{Pod: "db-1", Reason: "Unschedulable", Message: "0/3 nodes are available"},
+	}, status.Problems)
+}
+
+func TestGetStatusIgnoredWorkloadProblems(t *testing.T) {
+	controller := func(kind, name string) []metav1.OwnerReference {
+		isController := true
+		return []metav1.OwnerReference{{Kind: kind, Name: name, Controller: &isController}}
+	}
+
+	ignore := map[string]string{resource.AnnotationReadiness: resource.ReadinessIgnore}
+	crashing := v1.PodStatus{ContainerStatuses: []v1.ContainerStatus{waiting("php", "CrashLoopBackOff", 5)}}
+
+	namespaces := newReadinessNamespaceService(
+		&appsv1.Deployment{ObjectMeta: meta("flaky", ignore)},
+		&appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{Name: "flaky-7d9f", Namespace: "test", OwnerReferences: controller("Deployment", "flaky")}},
+		&v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "flaky-7d9f-x2x", Namespace: "test", OwnerReferences: controller("ReplicaSet", "flaky-7d9f")},
+			Status:     crashing,
+		},
+		&batchv1.CronJob{ObjectMeta: meta("report", ignore)},
+		&batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: "report-28", Namespace: "test", OwnerReferences: controller("CronJob", "report")}},
+		&v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "report-28-x8z", Namespace: "test", OwnerReferences: controller("Job", "report-28")},
+			Status:     crashing,
+		},
+		&appsv1.Deployment{ObjectMeta: meta("api", nil)},
+		&appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{Name: "api-5c4b", Namespace: "test", OwnerReferences: controller("Deployment", "api")}},
+		&v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "api-5c4b-p9q", Namespace: "test", OwnerReferences: controller("ReplicaSet", "api-5c4b")},
+			Status:     crashing,
+		},
+	)
+
+	status, err := namespaces.GetStatus("test")
+	assert.Nil(t, err)
+	assert.Equal(t, []resource.PodProblem{{Pod: "api-5c4b-p9q", Container: "php", Reason: "CrashLoopBackOff"}}, status.Problems)
+}
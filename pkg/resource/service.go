@@ -1,23 +1,73 @@
 package resource
 
-import "fmt"
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+)
+
+// Sources of exposed services
+const (
+	SourceNodePort     = "NodePort"
+	SourceLoadBalancer = "LoadBalancer"
+	SourceIngress      = "Ingress"
+	SourceHTTPRoute    = "HTTPRoute"
+)
 
 // Service represent a kubernetes service
 // Name is the service name
 // Addr is the domain name where the service can be reach from outside the kubernetes cluster.
 // For ingress exposed services it is the domain name declared in the ingress configuration
 // for node port exposed services it is the ip / domain name of the cluster.
+// URL is the full url of the service, including the scheme, the port when it is not the default one and the path.
+// For NodePort and LoadBalancer services, URL and TLS are the ones of the first port, each port having its own URL.
+// Source is the kind of object exposing the service : NodePort, LoadBalancer, Ingress or HTTPRoute.
+// TLS is true when the service is reached using https. TLSSecret is the secret holding the certificate, if known.
+// Checks are the smoke checks declared using the blackbeard.io/checks annotation.
 type Service struct {
-	Name  string `json:"name"`
-	Ports []Port `json:"ports"`
-	Addr  string `json:"addr"`
+	Name      string       `json:"name"`
+	Ports     []Port       `json:"ports"`
+	Addr      string       `json:"addr"`
+	URL       string       `json:"url"`
+	Path      string       `json:"path,omitempty"`
+	Source    string       `json:"source"`
+	TLS       bool         `json:"tls"`
+	TLSSecret string       `json:"tlsSecret,omitempty"`
+	Checks    []SmokeCheck `json:"checks,omitempty"`
+}
+
+// ServiceURL returns the url of a service reached at the given address, port and path.
+// The port is omitted when it is the default port of the scheme.
+func ServiceURL(tls bool, addr string, port int32, path string) string {
+	if addr == "" {
+		return ""
+	}
+
+	u := url.URL{Scheme: "http", Host: addr, Path: path}
+	if tls {
+		u.Scheme = "https"
+	}
+
+	if port != 0 && !(tls && port == 443) && !(!tls && port == 80) {
+		u.Host = net.JoinHostPort(addr, strconv.Itoa(int(port)))
+	}
+
+	if u.Path == "" {
+		u.Path = "/"
+	}
+
+	return u.String()
 }
 
 // Port represent a kubernetes service port.
-// This mean an internal port and a exposed port
+// This mean an internal port and a exposed port.
+// URL and TLS are only set for the ports of NodePort and LoadBalancer services, which may expose several ports.
 type Port struct {
-	Port        int32 `json:"port"`
-	ExposedPort int32 `json:"exposedPort"`
+	Port        int32  `json:"port"`
+	ExposedPort int32  `json:"exposedPort"`
+	URL         string `json:"url,omitempty"`
+	TLS         bool   `json:"tls,omitempty"`
 }
 
 // ServiceService defines the way kubernetes services are managed
@@ -29,6 +79,7 @@ type ServiceService interface {
 type ServiceRepository interface {
 	ListExternal(n string) ([]Service, error)
 	ListIngress(n string) ([]Service, error)
+	ListRoutes(n string) ([]Service, error)
 }
 
 type serviceService struct {
@@ -42,8 +93,8 @@ func NewServiceService(services ServiceRepository) ServiceService {
 	}
 }
 
-// ListExposed find services exposed as NodePort and ingress configuration and return
-// an array of services containing an URL, the exposed port and the service name.
+// ListExposed find services exposed as NodePort or LoadBalancer, ingress configurations and Gateway API HTTPRoutes
+// and return an array of services containing an URL, the exposed port and the service name.
 func (ss *serviceService) ListExposed(namespace string) ([]Service, error) {
 
 	var (
@@ -53,15 +104,21 @@ func (ss *serviceService) ListExposed(namespace string) ([]Service, error) {
 
 	services, err = ss.services.ListExternal(namespace)
 	if err != nil {
-		return nil, fmt.Errorf("unable to list external services %s", err.Error())
+		return nil, fmt.Errorf("unable to list external services: %w", err)
 	}
 
 	ingress, err := ss.services.ListIngress(namespace)
 	if err != nil {
-		return nil, fmt.Errorf("unable to list ingress entries %s", err.Error())
+		return nil, fmt.Errorf("unable to list ingress entries: %w", err)
+	}
+
+	routes, err := ss.services.ListRoutes(namespace)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list http routes: %w", err)
 	}
 
 	services = append(services, ingress...)
+	services = append(services, routes...)
 
 	return services, nil
 }
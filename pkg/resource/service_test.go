@@ -0,0 +1,272 @@
+package resource_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/Meetic/blackbeard/pkg/kubernetes"
+	"github.com/Meetic/blackbeard/pkg/resource"
+)
+
+var (
+	httpRoutes = schema.GroupVersionResource{Group: "gateway.networking.k8s.io", Version: "v1", Resource: "httproutes"}
+	gateways   = schema.GroupVersionResource{Group: "gateway.networking.k8s.io", Version: "v1", Resource: "gateways"}
+)
+
+func newServiceService(t *testing.T, objects []runtime.Object, gatewayObjects ...*unstructured.Unstructured) resource.ServiceService {
+	kube := fake.NewSimpleClientset(objects...)
+
+	dynamic := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(
+		runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{httpRoutes: "HTTPRouteList", gateways: "GatewayList"},
+	)
+
+	// objects are added using their resource since the fake client can not guess the plural of gateway
+	for _, obj := range gatewayObjects {
+		gvr := httpRoutes
+		if obj.GetKind() == "Gateway" {
+			gvr = gateways
+		}
+		assert.Nil(t, dynamic.Tracker().Create(gvr, obj, obj.GetNamespace()))
+	}
+
+	return resource.NewServiceService(kubernetes.NewServiceRepository(kube, dynamic, "kube.test"))
+}
+
+func gatewayObject(kind string, namespace, name string, spec map[string]interface{}, status map[string]interface{}) *unstructured.Unstructured {
+	obj := map[string]interface{}{
+		"apiVersion": "gateway.networking.k8s.io/v1",
+		"kind":       kind,
+		"metadata":   map[string]interface{}{"name": name, "namespace": namespace},
+		"spec":       spec,
+	}
+
+	if status != nil {
+		obj["status"] = status
+	}
+
+	return &unstructured.Unstructured{Object: obj}
+}
+
+func TestListExposedExternal(t *testing.T) {
+	services := newServiceService(t, []runtime.Object{
+		&v1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "test"},
+			Spec: v1.ServiceSpec{
+				Type: v1.ServiceTypeNodePort,
+				Ports: []v1.ServicePort{
+					{Name: "http", Port: 8080, NodePort: 30080},
+					{Name: "https", Port: 8443, NodePort: 30443},
+				},
+			},
+		},
+		&v1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "front", Namespace: "test"},
+			Spec: v1.ServiceSpec{
+				Type:  v1.ServiceTypeLoadBalancer,
+				Ports: []v1.ServicePort{{Name: "https", Port: 443, NodePort: 30443}},
+			},
+			Status: v1.ServiceStatus{LoadBalancer: v1.LoadBalancerStatus{Ingress: []v1.LoadBalancerIngress{{Hostname: "lb.example.com"}}}},
+		},
+		&v1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "test"},
+			Spec:       v1.ServiceSpec{Type: v1.ServiceTypeClusterIP, Ports: []v1.ServicePort{{Port: 5432}}},
+		},
+	})
+
+	exposed, err := services.ListExposed("test")
+	assert.Nil(t, err)
+	assert.Equal(t, []resource.Service{
+		{
+			Name: "api",
+			Ports: []resource.Port{
+				{Port: 8080, ExposedPort: 30080, URL: "http://kube.test:30080/"},
+				{Port: 8443, ExposedPort: 30443, URL: "https://kube.test:30443/", TLS: true},
+			},
+			Addr:   "kube.test",
+			URL:    "http://kube.test:30080/",
+			Source: resource.SourceNodePort,
+		},
+		{
+			Name:   "front",
+			Ports:  []resource.Port{{Port: 443, ExposedPort: 443, URL: "https://lb.example.com/", TLS: true}},
+			Addr:   "lb.example.com",
+			URL:    "https://lb.example.com/",
+			Source: resource.SourceLoadBalancer,
+			TLS:    true,
+		},
+	}, exposed)
+}
+
+func TestListExposedIngress(t *testing.T) {
+	prefix := networkingv1.PathTypePrefix
+	apiGroup := "storage.k8s.io"
+
+	services := newServiceService(t, []runtime.Object{
+		&networkingv1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "test"},
+			Spec: networkingv1.IngressSpec{
+				TLS: []networkingv1.IngressTLS{{Hosts: []string{"*.example.com"}, SecretName: "wildcard"}},
+				Rules: []networkingv1.IngressRule{
+					{
+						Host: "api.example.com",
+						IngressRuleValue: networkingv1.IngressRuleValue{HTTP: &networkingv1.HTTPIngressRuleValue{
+							Paths: []networkingv1.HTTPIngressPath{
+								{
+									Path:     "/v1",
+									PathType: &prefix,
+									Backend: networkingv1.IngressBackend{Service: &networkingv1.IngressServiceBackend{
+										Name: "api", Port: networkingv1.ServiceBackendPort{Number: 8080},
+									}},
+								},
+								{
+									Path:     "/static",
+									PathType: &prefix,
+									Backend: networkingv1.IngressBackend{Resource: &v1.TypedLocalObjectReference{
+										APIGroup: &apiGroup, Kind: "Bucket", Name: "assets",
+									}},
+								},
+							},
+						}},
+					},
+					// host only rule, routed to the default backend
+					{Host: "front.test"},
+				},
+				DefaultBackend: &networkingv1.IngressBackend{Service: &networkingv1.IngressServiceBackend{
+					Name: "front", Port: networkingv1.ServiceBackendPort{Number: 80},
+				}},
+			},
+		},
+	})
+
+	exposed, err := services.ListExposed("test")
+	assert.Nil(t, err)
+	assert.Equal(t, []resource.Service{
+		{
+			Name:      "api",
+			Ports:     []resource.Port{{Port: 8080, ExposedPort: 443}},
+			Addr:      "api.example.com",
+			URL:       "https://api.example.com/v1",
+			Path:      "/v1",
+			Source:    resource.SourceIngress,
+			TLS:       true,
+			TLSSecret: "wildcard",
+		},
+		{
+			Name:      "bucket/assets",
+			Ports:     []resource.Port{{Port: 0, ExposedPort: 443}},
+			Addr:      "api.example.com",
+			URL:       "https://api.example.com/static",
+			Path:      "/static",
+			Source:    resource.SourceIngress,
+			TLS:       true,
+			TLSSecret: "wildcard",
+		},
+		{
+			Name:   "front",
+			Ports:  []resource.Port{{Port: 80, ExposedPort: 80}},
+			Addr:   "front.test",
+			URL:    "http://front.test/",
+			Source: resource.SourceIngress,
+		},
+	}, exposed)
+}
+
+func TestListExposedHTTPRoutes(t *testing.T) {
+	services := newServiceService(t, nil,
+		gatewayObject("Gateway", "infra", "public", map[string]interface{}{
+			"listeners": []interface{}{
+				map[string]interface{}{"name": "http", "protocol": "HTTP", "port": int64(80)},
+				map[string]interface{}{
+					"name": "https", "protocol": "HTTPS", "port": int64(8443),
+					"tls": map[string]interface{}{"certificateRefs": []interface{}{map[string]interface{}{"name": "public-cert"}}},
+				},
+			},
+		}, map[string]interface{}{
+			"addresses": []interface{}{map[string]interface{}{"type": "IPAddress", "value": "10.0.0.1"}},
+		}),
+		gatewayObject("HTTPRoute", "test", "api", map[string]interface{}{
+			"hostnames": []interface{}{"api.example.com"},
+			"parentRefs": []interface{}{
+				map[string]interface{}{"name": "public", "namespace": "infra", "sectionName": "https"},
+			},
+			"rules": []interface{}{
+				map[string]interface{}{
+					"matches":     []interface{}{map[string]interface{}{"path": map[string]interface{}{"type": "PathPrefix", "value": "/api"}}},
+					"backendRefs": []interface{}{map[string]interface{}{"name": "api", "port": int64(8080)}},
+				},
+			},
+		}, nil),
+		gatewayObject("HTTPRoute", "test", "front", map[string]interface{}{
+			"parentRefs": []interface{}{map[string]interface{}{"name": "public", "namespace": "infra", "sectionName": "http"}},
+			"rules": []interface{}{
+				map[string]interface{}{"backendRefs": []interface{}{map[string]interface{}{"name": "front", "port": int64(80)}}},
+			},
+		}, nil),
+	)
+
+	exposed, err := services.ListExposed("test")
+	assert.Nil(t, err)
+	assert.ElementsMatch(t, []resource.Service{
+		{
+			Name:      "api",
+			Ports:     []resource.Port{{Port: 8080, ExposedPort: 8443}},
+			Addr:      "api.example.com",
+			URL:       "https://api.example.com:8443/api",
+			Path:      "/api",
+			Source:    resource.SourceHTTPRoute,
+			TLS:       true,
+			TLSSecret: "public-cert",
+		},
+		{
+			Name:   "front",
+			Ports:  []resource.Port{{Port: 80, ExposedPort: 80}},
+			Addr:   "10.0.0.1",
+			URL:    "http://10.0.0.1/",
+			Source: resource.SourceHTTPRoute,
+		},
+	}, exposed)
+}
+
+func TestServiceURL(t *testing.T) {
+	assert.Equal(t, "http://kube.test:30080/", resource.ServiceURL(false, "kube.test", 30080, ""))
+	assert.Equal(t, "https://example.com/api", resource.ServiceURL(true, "example.com", 443, "/api"))
+	assert.Equal(t, "http://[::1]:8080/", resource.ServiceURL(false, "::1", 8080, ""))
+	assert.Equal(t, "", resource.ServiceURL(false, "", 80, ""))
+}
+
+func TestListExposedSmokeChecks(t *testing.T) {
+	services := newServiceService(t, []runtime.Object{
+		&v1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "api",
+				Namespace:   "test",
+				Annotations: map[string]string{resource.AnnotationChecks: `[{"path": "/health", "status": 204}]`},
+			},
+			Spec: v1.ServiceSpec{Type: v1.ServiceTypeNodePort, Ports: []v1.ServicePort{{Port: 8080, NodePort: 30080}}},
+		},
+		&v1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "front",
+				Namespace:   "test",
+				Annotations: map[string]string{resource.AnnotationChecks: `/health`},
+			},
+			Spec: v1.ServiceSpec{Type: v1.ServiceTypeNodePort, Ports: []v1.ServicePort{{Port: 80, NodePort: 30081}}},
+		},
+	})
+
+	exposed, err := services.ListExposed("test")
+	assert.Nil(t, err)
+	assert.Len(t, exposed, 2)
+	assert.Equal(t, []resource.SmokeCheck{{Path: "/health", Status: 204}}, exposed[0].Checks)
+	assert.Nil(t, exposed[1].Checks)
+}
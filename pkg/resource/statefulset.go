@@ -3,8 +3,9 @@ package resource
 type Statefulsets []Statefulset
 
 type Statefulset struct {
-	Name   string
-	Status StatefulsetStatus
+	Name        string
+	Status      StatefulsetStatus
+	Annotations map[string]string
 }
 
 type StatefulsetStatus string
@@ -16,4 +17,5 @@ const (
 
 type StatefulsetRepository interface {
 	List(namespace string) (Statefulsets, error)
+	Restart(namespace, name string) error
 }
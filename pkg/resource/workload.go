@@ -0,0 +1,143 @@
+package resource
+
+import (
+	"strings"
+
+	"github.com/Meetic/blackbeard/pkg/errors"
+)
+
+// Workload kinds which can be restarted
+const (
+	KindDeployment  = "deployment"
+	KindStatefulset = "statefulset"
+)
+
+// WorkloadService restarts the workloads of a namespace
+type WorkloadService interface {
+	Restart(namespace, kind, name string) error
+	RestartAll(namespace string) ([]string, error)
+	ImageTags(namespace string) (map[string]string, error)
+}
+
+type workloadService struct {
+	deployments  DeploymentRepository
+	statefulsets StatefulsetRepository
+}
+
+// NewWorkloadService returns a new WorkloadService
+func NewWorkloadService(deployments DeploymentRepository, statefulsets StatefulsetRepository) WorkloadService {
+	return &workloadService{
+		deployments:  deployments,
+		statefulsets: statefulsets,
+	}
+}
+
+// NormalizeWorkloadKind returns the canonical name of a workload kind.
+// Plural names and short names are accepted : deployments, deploy, statefulsets, sts.
+func NormalizeWorkloadKind(kind string) (string, error) {
+	switch strings.ToLower(kind) {
+	case "deployment", "deployments", "deploy":
+		return KindDeployment, nil
+	case "statefulset", "statefulsets", "sts":
+		return KindStatefulset, nil
+	}
+
+	return "", errors.New(errors.Invalid, "workload kind %s can not be restarted, use deployment or statefulset", kind)
+}
+
+// Restart recreates the pods of a deployment or a statefulset using a rolling update
+func (ws *workloadService) Restart(namespace, kind, name string) error {
+	k, err := NormalizeWorkloadKind(kind)
+	if err != nil {
+		return err
+	}
+
+	if k == KindStatefulset {
+		return ws.statefulsets.Restart(namespace, name)
+	}
+
+	return ws.deployments.Restart(namespace, name)
+}
+
+// RestartAll restarts every deployment and statefulset of a namespace.
+// It returns the restarted workloads as kind/name.
+func (ws *workloadService) RestartAll(namespace string) ([]string, error) {
+	var restarted []string
+
+	deployments, err := ws.deployments.List(namespace)
+	if err != nil {
+		return restarted, err
+	}
+
+	for _, d := range deployments {
+		if err := ws.deployments.Restart(namespace, d.Name); err != nil {
+			return restarted, err
+		}
+		restarted = append(restarted, KindDeployment+"/"+d.Name)
+	}
+
+	statefulsets, err := ws.statefulsets.List(namespace)
+	if err != nil {
+		return restarted, err
+	}
+
+	for _, s := range statefulsets {
+		if err := ws.statefulsets.Restart(namespace, s.Name); err != nil {
+			return restarted, err
+		}
+		restarted = append(restarted, KindStatefulset+"/"+s.Name)
+	}
+
+	return restarted, nil
+}
+
+// ImageTags returns the tags of the images run by the deployments of a namespace, indexed by deployment name
+// and by container name. Images without tag or pinned by digest are ignored.
+// The tag of a deployment is the one of its container having the same name, or of its only container.
+// A deployment name takes precedence over a container name of another deployment.
+func (ws *workloadService) ImageTags(namespace string) (map[string]string, error) {
+	dps, err := ws.deployments.List(namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	tags := make(map[string]string)
+	containers := make(map[string]string)
+
+	for _, dp := range dps {
+		for container, image := range dp.Images {
+			tag := imageTag(image)
+			if tag == "" {
+				continue
+			}
+
+			if container == dp.Name || len(dp.Images) == 1 {
+				tags[dp.Name] = tag
+			}
+			containers[container] = tag
+		}
+	}
+
+	for container, tag := range containers {
+		if _, ok := tags[container]; !ok {
+			tags[container] = tag
+		}
+	}
+
+	return tags, nil
+}
+
+// imageTag returns the tag of an image, or an empty string if the image has no tag or is pinned by digest
+func imageTag(image string) string {
+	if strings.Contains(image, "@") {
+		return ""
+	}
+
+	slash := strings.LastIndex(image, "/")
+	colon := strings.LastIndex(image, ":")
+	if colon <= slash {
+		return ""
+	}
+
+	return image[colon+1:]
+}
@@ -0,0 +1,61 @@
+package resource_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Meetic/blackbeard/pkg/errors"
+	"github.com/Meetic/blackbeard/pkg/mock"
+	"github.com/Meetic/blackbeard/pkg/resource"
+)
+
+func TestRestart(t *testing.T) {
+	deployments := new(mock.DeploymentRepository)
+	statefulsets := new(mock.StatefulsetRepository)
+	workloads := resource.NewWorkloadService(deployments, statefulsets)
+
+	deployments.On("Restart", "test", "api").Return(nil)
+	statefulsets.On("Restart", "test", "mysql").Return(nil)
+
+	assert.Nil(t, workloads.Restart("test", "deployments", "api"))
+	assert.Nil(t, workloads.Restart("test", "sts", "mysql"))
+	assert.True(t, errors.Is(workloads.Restart("test", "job", "migration"), errors.Invalid))
+
+	deployments.AssertExpectations(t)
+	statefulsets.AssertExpectations(t)
+}
+
+func TestRestartAll(t *testing.T) {
+	deployments := new(mock.DeploymentRepository)
+	statefulsets := new(mock.StatefulsetRepository)
+	workloads := resource.NewWorkloadService(deployments, statefulsets)
+
+	deployments.On("List", "test").Return(resource.Deployments{{Name: "api"}, {Name: "front"}}, nil)
+	deployments.On("Restart", "test", "api").Return(nil)
+	deployments.On("Restart", "test", "front").Return(nil)
+	statefulsets.On("List", "test").Return(resource.Statefulsets{{Name: "mysql"}}, nil)
+	statefulsets.On("Restart", "test", "mysql").Return(nil)
+
+	restarted, err := workloads.RestartAll("test")
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"deployment/api", "deployment/front", "statefulset/mysql"}, restarted)
+}
+
+func TestImageTags(t *testing.T) {
+	deployments := new(mock.DeploymentRepository)
+	workloads := resource.NewWorkloadService(deployments, new(mock.StatefulsetRepository))
+
+	deployments.On("List", "test").Return(resource.Deployments{
+		{Name: "api", Images: map[string]string{"api": "registry.local:5000/api:v2", "nginx": "nginx:1.25"}},
+		{Name: "front", Images: map[string]string{"front": "front@sha256:4f53"}},
+		{Name: "nginx", Images: map[string]string{"nginx": "nginx"}},
+		{Name: "worker", Images: map[string]string{"api": "api:v1"}},
+	}, nil)
+
+	tags, err := workloads.ImageTags("test")
+
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"api": "v2", "worker": "v1", "nginx": "1.25"}, tags)
+}